@@ -0,0 +1,381 @@
+package catalog
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/geocode"
+	"github.com/thunderjr/sptrans-mcp/internal/journey"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Catalog holds the stops, lines and corridors known to the server and the
+// line-to-stop relations between them. It is fed by every stop the SPTrans
+// API returns and filled by the crawler.
+type Catalog struct {
+	mu            sync.RWMutex
+	stops         map[int]types.Stop
+	lines         map[int]types.Line
+	lineStops     map[int][]int     // Ordered stop codes of each line
+	lineFetched   map[int]time.Time // When the stops of each line were fetched
+	corridors     map[int]types.Corridor
+	corridorStops map[int][]int // Stop codes of each corridor
+	version       int           // Incremented on every completed crawl
+	updatedAt     time.Time     // When the last crawl completed
+
+	// Derived structures, dropped only when a stop, line or relation they
+	// depend on is added or changes, and rebuilt lazily
+	index     *geo.Grid[types.Stop] // Rebuilt after stops change
+	servedBy  map[int][]int         // Line codes serving each stop, rebuilt after line stops change
+	gazetteer *geocode.Gazetteer    // Rebuilt after stops change
+	network   *journey.Network      // Rebuilt after stops, line stops or their lines change
+}
+
+// New creates an empty catalog
+func New() *Catalog {
+	return &Catalog{
+		stops:         make(map[int]types.Stop),
+		lines:         make(map[int]types.Line),
+		lineStops:     make(map[int][]int),
+		lineFetched:   make(map[int]time.Time),
+		corridors:     make(map[int]types.Corridor),
+		corridorStops: make(map[int][]int),
+	}
+}
+
+// AddStops adds or updates stops in the catalog
+func (c *Catalog) AddStops(stops []types.Stop) {
+	if len(stops) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addStops(stops)
+}
+
+// addStops adds stops with usable coordinates, dropping the structures
+// derived from the stops if any is new or changed; the caller holds the lock
+func (c *Catalog) addStops(stops []types.Stop) {
+	changed := false
+	for _, stop := range stops {
+		if stop.Code == 0 || (stop.Latitude == 0 && stop.Longitude == 0) ||
+			!geo.ValidCoordinate(stop.Latitude, stop.Longitude) {
+			continue
+		}
+		if known, ok := c.stops[stop.Code]; ok && known == stop {
+			continue
+		}
+		c.stops[stop.Code] = stop
+		changed = true
+	}
+	if changed {
+		c.index = nil
+		c.gazetteer = nil
+		c.network = nil
+	}
+}
+
+// AddLines adds or updates lines in the catalog
+func (c *Catalog) AddLines(lines []types.Line) {
+	if len(lines) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, line := range lines {
+		if line.Code == 0 {
+			continue
+		}
+		if known, ok := c.lines[line.Code]; ok && known == line {
+			continue
+		}
+		c.lines[line.Code] = line
+		// The network only holds the lines whose stops are known
+		if _, ok := c.lineStops[line.Code]; ok {
+			c.network = nil
+		}
+	}
+}
+
+// SetLineStops records the ordered stops served by a line
+func (c *Catalog) SetLineStops(lineCode int, stops []types.Stop) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addStops(stops)
+
+	codes := make([]int, 0, len(stops))
+	for _, stop := range stops {
+		codes = append(codes, stop.Code)
+	}
+	if known, ok := c.lineStops[lineCode]; !ok || !slices.Equal(known, codes) {
+		c.lineStops[lineCode] = codes
+		c.servedBy = nil
+		c.network = nil
+	}
+	c.lineFetched[lineCode] = time.Now()
+}
+
+// SetCorridorStops records a corridor and its stops
+func (c *Catalog) SetCorridorStops(corridor types.Corridor, stops []types.Stop) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addStops(stops)
+
+	codes := make([]int, 0, len(stops))
+	for _, stop := range stops {
+		codes = append(codes, stop.Code)
+	}
+	c.corridors[corridor.Code] = corridor
+	c.corridorStops[corridor.Code] = codes
+}
+
+// RetainLines drops the lines, and their stop relations, that are not in keep
+func (c *Catalog) RetainLines(keep map[int]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for code := range c.lines {
+		if keep[code] {
+			continue
+		}
+		if _, ok := c.lineStops[code]; ok {
+			c.servedBy = nil
+			c.network = nil
+		}
+		delete(c.lines, code)
+		delete(c.lineStops, code)
+		delete(c.lineFetched, code)
+	}
+}
+
+// MarkUpdated stamps the catalog with a new version after a completed crawl
+func (c *Catalog) MarkUpdated() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.updatedAt = time.Now()
+}
+
+// Version returns the catalog version and when it was stamped
+func (c *Catalog) Version() (int, time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version, c.updatedAt
+}
+
+// Stop returns the stop with the given code
+func (c *Catalog) Stop(code int) (types.Stop, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	stop, ok := c.stops[code]
+	return stop, ok
+}
+
+// StopCount returns the number of stops in the catalog
+func (c *Catalog) StopCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.stops)
+}
+
+// Line returns the line with the given code
+func (c *Catalog) Line(code int) (types.Line, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	line, ok := c.lines[code]
+	return line, ok
+}
+
+// Lines returns every line in the catalog, sorted by code
+func (c *Catalog) Lines() []types.Line {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	lines := make([]types.Line, 0, len(c.lines))
+	for _, line := range c.lines {
+		lines = append(lines, line)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Code < lines[j].Code
+	})
+	return lines
+}
+
+// LineCount returns the number of lines in the catalog
+func (c *Catalog) LineCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.lines)
+}
+
+// LineStops returns the ordered stops of a line, if they are known
+func (c *Catalog) LineStops(lineCode int) ([]types.Stop, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	codes, ok := c.lineStops[lineCode]
+	if !ok {
+		return nil, false
+	}
+	stops := make([]types.Stop, 0, len(codes))
+	for _, code := range codes {
+		if stop, ok := c.stops[code]; ok {
+			stops = append(stops, stop)
+		}
+	}
+	return stops, true
+}
+
+// LineFetchedAt returns when the stops of a line were last fetched
+func (c *Catalog) LineFetchedAt(lineCode int) (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	fetched, ok := c.lineFetched[lineCode]
+	return fetched, ok
+}
+
+// LinesServing returns the lines known to serve a stop, sorted by code
+func (c *Catalog) LinesServing(stopCode int) []types.Line {
+	servedBy := c.servedByIndex()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var lines []types.Line
+	for _, code := range servedBy[stopCode] {
+		if line, ok := c.lines[code]; ok {
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, types.Line{Code: code})
+		}
+	}
+	return lines
+}
+
+// CorridorCount returns the number of corridors in the catalog
+func (c *Catalog) CorridorCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.corridors)
+}
+
+// StopsNear returns the stops within radius meters of the point, nearest first
+func (c *Catalog) StopsNear(lat, lon, radius float64, limit int) []geo.Neighbor[types.Stop] {
+	return c.stopIndex().Within(lat, lon, radius, limit)
+}
+
+// StopsInBox returns the stops inside the rectangle
+func (c *Catalog) StopsInBox(box types.BoundingBox) []types.Stop {
+	found := c.stopIndex().InBox(box.South, box.West, box.North, box.East)
+	stops := make([]types.Stop, len(found))
+	for i, n := range found {
+		stops[i] = n.Value
+	}
+	return stops
+}
+
+// Gazetteer returns the geocoder over the stop addresses and names,
+// rebuilding it if stale
+func (c *Catalog) Gazetteer() *geocode.Gazetteer {
+	c.mu.RLock()
+	gazetteer := c.gazetteer
+	c.mu.RUnlock()
+	if gazetteer != nil {
+		return gazetteer
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gazetteer == nil {
+		stops := make([]types.Stop, 0, len(c.stops))
+		for _, stop := range c.stops {
+			stops = append(stops, stop)
+		}
+		c.gazetteer = geocode.New(stops)
+	}
+	return c.gazetteer
+}
+
+// Network returns the graph of the lines whose stops are known, for
+// planning journeys, rebuilding it if stale
+func (c *Catalog) Network() *journey.Network {
+	c.mu.RLock()
+	network := c.network
+	c.mu.RUnlock()
+	if network != nil {
+		return network
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.network == nil {
+		stops := make([]types.Stop, 0, len(c.stops))
+		for _, stop := range c.stops {
+			stops = append(stops, stop)
+		}
+		patterns := make([]journey.Pattern, 0, len(c.lineStops))
+		for _, code := range sortedCodes(c.lineStops) {
+			line, ok := c.lines[code]
+			if !ok {
+				line = types.Line{Code: code}
+			}
+			patterns = append(patterns, journey.Pattern{Line: line, Stops: c.lineStops[code]})
+		}
+		c.network = journey.NewNetwork(stops, patterns)
+	}
+	return c.network
+}
+
+// stopIndex returns the spatial index of the stops, rebuilding it if stale
+func (c *Catalog) stopIndex() *geo.Grid[types.Stop] {
+	c.mu.RLock()
+	index := c.index
+	c.mu.RUnlock()
+	if index != nil {
+		return index
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.index == nil {
+		c.index = geo.NewGrid[types.Stop](geo.DefaultCellSize)
+		for _, stop := range c.stops {
+			c.index.Insert(stop.Latitude, stop.Longitude, stop)
+		}
+	}
+	return c.index
+}
+
+// servedByIndex returns the lines serving each stop, rebuilding it if stale
+func (c *Catalog) servedByIndex() map[int][]int {
+	c.mu.RLock()
+	servedBy := c.servedBy
+	c.mu.RUnlock()
+	if servedBy != nil {
+		return servedBy
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.servedBy == nil {
+		c.servedBy = make(map[int][]int)
+		for lineCode, stopCodes := range c.lineStops {
+			for _, stopCode := range stopCodes {
+				c.servedBy[stopCode] = append(c.servedBy[stopCode], lineCode)
+			}
+		}
+		for _, lineCodes := range c.servedBy {
+			sort.Ints(lineCodes)
+		}
+	}
+	return c.servedBy
+}
+
+// sortedCodes returns the codes keyed in relations in increasing order
+func sortedCodes(relations map[int][]int) []int {
+	codes := make([]int, 0, len(relations))
+	for code := range relations {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	return codes
+}
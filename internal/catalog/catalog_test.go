@@ -0,0 +1,92 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+var (
+	stopA = types.Stop{Code: 1, Name: "A", Address: "AV PAULISTA, 1000", Latitude: -23.5700, Longitude: -46.6500}
+	stopB = types.Stop{Code: 2, Name: "B", Address: "AV PAULISTA, 2000", Latitude: -23.5650, Longitude: -46.6550}
+	line  = types.Line{Code: 10, Number: "8000", Type: 10, Direction: 1}
+)
+
+func TestDerivedKeptWithoutChanges(t *testing.T) {
+	c := New()
+	c.AddLines([]types.Line{line})
+	c.SetLineStops(line.Code, []types.Stop{stopA, stopB})
+	gazetteer, network := c.Gazetteer(), c.Network()
+	c.StopsNear(stopA.Latitude, stopA.Longitude, 100, 1)
+	index := c.index
+
+	c.AddStops([]types.Stop{stopA, stopB})
+	c.AddLines([]types.Line{line})
+	c.SetLineStops(line.Code, []types.Stop{stopA, stopB})
+	c.RetainLines(map[int]bool{line.Code: true})
+	if c.Gazetteer() != gazetteer || c.Network() != network || c.index != index {
+		t.Error("unchanged stops and lines rebuilt the derived structures")
+	}
+}
+
+func TestDerivedRebuiltOnChanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    func(c *Catalog)
+		stops     bool // Whether the stop index and gazetteer are rebuilt
+		network   bool
+		servedBy  bool
+		lineCount int
+	}{
+		{"new stop", func(c *Catalog) {
+			c.AddStops([]types.Stop{{Code: 3, Name: "C", Latitude: -23.56, Longitude: -46.66}})
+		}, true, true, false, 1},
+		{"moved stop", func(c *Catalog) {
+			moved := stopA
+			moved.Latitude += 0.001
+			c.AddStops([]types.Stop{moved})
+		}, true, true, false, 1},
+		{"renamed line", func(c *Catalog) {
+			renamed := line
+			renamed.Destination = "Lapa"
+			c.AddLines([]types.Line{renamed})
+		}, false, true, false, 1},
+		{"line without stops", func(c *Catalog) {
+			c.AddLines([]types.Line{{Code: 20, Number: "875A"}})
+		}, false, false, false, 2},
+		{"reordered line stops", func(c *Catalog) {
+			c.SetLineStops(line.Code, []types.Stop{stopB, stopA})
+		}, false, true, true, 1},
+		{"dropped line", func(c *Catalog) {
+			c.RetainLines(map[int]bool{})
+		}, false, true, true, 0},
+	}
+	for _, tt := range tests {
+		c := New()
+		c.AddLines([]types.Line{line})
+		c.SetLineStops(line.Code, []types.Stop{stopA, stopB})
+		gazetteer, network := c.Gazetteer(), c.Network()
+		c.LinesServing(stopA.Code)
+		c.StopsNear(stopA.Latitude, stopA.Longitude, 100, 1)
+
+		tt.change(c)
+		if (c.index == nil) != tt.stops || (c.gazetteer == nil) != tt.stops {
+			t.Errorf("%s: stop structures dropped = %v, want %v", tt.name, c.index == nil, tt.stops)
+		}
+		if (c.network == nil) != tt.network {
+			t.Errorf("%s: network dropped = %v, want %v", tt.name, c.network == nil, tt.network)
+		}
+		if (c.servedBy == nil) != tt.servedBy {
+			t.Errorf("%s: served-by index dropped = %v, want %v", tt.name, c.servedBy == nil, tt.servedBy)
+		}
+		if c.LineCount() != tt.lineCount {
+			t.Errorf("%s: %d lines, want %d", tt.name, c.LineCount(), tt.lineCount)
+		}
+		if tt.stops && c.Gazetteer() == gazetteer {
+			t.Errorf("%s: gazetteer not rebuilt", tt.name)
+		}
+		if tt.network && c.Network() == network {
+			t.Errorf("%s: network not rebuilt", tt.name)
+		}
+	}
+}
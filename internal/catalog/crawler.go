@@ -0,0 +1,312 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/client"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// searchPrefixes are the search terms used to enumerate every line. Olho Vivo
+// matches terms anywhere in the line number or terminal names, so single
+// digits and letters cover the whole network.
+const searchPrefixes = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// checkpointEvery is the number of fetched lines between catalog saves, so an
+// interrupted crawl resumes where it stopped
+const checkpointEvery = 100
+
+// retryDelay is how long the crawler waits after a failed crawl
+const retryDelay = 5 * time.Minute
+
+// Source is the part of the SPTrans API the crawler reads the network from
+type Source interface {
+	SearchLines(ctx context.Context, searchTerm string) ([]types.Line, error)
+	GetStopsByLine(ctx context.Context, lineCode int) ([]types.Stop, error)
+	GetCorridors(ctx context.Context) ([]types.Corridor, error)
+	GetStopsByCorridor(ctx context.Context, corridorCode int) ([]types.Stop, error)
+}
+
+// CrawlerOptions configures a Crawler
+type CrawlerOptions struct {
+	Path     string        // File the catalog is persisted to, empty to keep it in memory
+	Interval time.Duration // Time between refreshes
+	MaxAge   time.Duration // Age after which the stops of a line are fetched again
+	Rate     float64       // Maximum requests per second, zero for no limit
+}
+
+// CrawlStatus describes the progress of the crawler
+type CrawlStatus struct {
+	Running      bool      // A crawl is in progress
+	StartedAt    time.Time // When the current or last crawl started
+	FinishedAt   time.Time // When the last crawl finished
+	LinesPending int       // Lines whose stops remain to be fetched in the current crawl
+	LinesFetched int       // Lines whose stops were fetched in the current or last crawl
+	Failures     int       // Requests that failed in the current or last crawl
+	LastError    string    // Last request failure
+	NextCrawl    time.Time // When the next crawl is due
+}
+
+// Crawler builds the catalog from the SPTrans API and keeps it fresh: every
+// interval it enumerates the lines, fetches the stops of new or outdated
+// lines and the corridor stops, and persists the result.
+type Crawler struct {
+	catalog *Catalog
+	source  Source
+	opts    CrawlerOptions
+	limiter *client.RateLimiter
+
+	mu     sync.Mutex
+	status CrawlStatus
+}
+
+// NewCrawler creates a crawler filling catalog from source
+func NewCrawler(catalog *Catalog, source Source, opts CrawlerOptions) *Crawler {
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = opts.Interval
+	}
+	return &Crawler{
+		catalog: catalog,
+		source:  source,
+		opts:    opts,
+		limiter: client.NewRateLimiter(opts.Rate),
+	}
+}
+
+// Status returns the progress of the crawler
+func (cr *Crawler) Status() CrawlStatus {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return cr.status
+}
+
+// Run crawls whenever the catalog is older than the interval, until ctx is done
+func (cr *Crawler) Run(ctx context.Context) {
+	for {
+		_, updatedAt := cr.catalog.Version()
+		next := updatedAt.Add(cr.opts.Interval)
+		cr.update(func(s *CrawlStatus) { s.NextCrawl = next })
+
+		if wait := time.Until(next); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+
+		if err := cr.Crawl(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Catalog crawl failed: %v", err)
+			retry := time.Now().Add(retryDelay)
+			cr.update(func(s *CrawlStatus) { s.NextCrawl = retry })
+			select {
+			case <-time.After(retryDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Crawl refreshes the catalog once and persists it
+func (cr *Crawler) Crawl(ctx context.Context) error {
+	cr.update(func(s *CrawlStatus) {
+		*s = CrawlStatus{Running: true, StartedAt: time.Now(), NextCrawl: s.NextCrawl}
+	})
+	defer cr.update(func(s *CrawlStatus) {
+		s.Running = false
+		s.FinishedAt = time.Now()
+	})
+
+	lines, complete, err := cr.enumerateLines(ctx)
+	if err != nil {
+		return err
+	}
+	cr.catalog.AddLines(lines)
+	if complete {
+		// Only drop vanished lines when every search succeeded
+		keep := make(map[int]bool, len(lines))
+		for _, line := range lines {
+			keep[line.Code] = true
+		}
+		cr.catalog.RetainLines(keep)
+	}
+
+	if err := cr.fetchLineStops(ctx, cr.outdatedLines(lines)); err != nil {
+		return err
+	}
+	if err := cr.fetchCorridorStops(ctx); err != nil {
+		return err
+	}
+
+	cr.catalog.MarkUpdated()
+	if err := cr.save(); err != nil {
+		return err
+	}
+
+	version, _ := cr.catalog.Version()
+	status := cr.Status()
+	log.Printf("Catalog version %d: %d lines, %d stops, %d corridors (%d lines fetched, %d failures)",
+		version, cr.catalog.LineCount(), cr.catalog.StopCount(), cr.catalog.CorridorCount(),
+		status.LinesFetched, status.Failures)
+	return nil
+}
+
+// enumerateLines searches every prefix and returns the distinct lines found,
+// reporting whether every search succeeded
+func (cr *Crawler) enumerateLines(ctx context.Context) ([]types.Line, bool, error) {
+	seen := make(map[int]types.Line)
+	complete := true
+	for _, prefix := range searchPrefixes {
+		if err := cr.limiter.Wait(ctx); err != nil {
+			return nil, false, err
+		}
+		found, err := cr.source.SearchLines(ctx, string(prefix))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, false, ctx.Err()
+			}
+			cr.fail(err)
+			complete = false
+			continue
+		}
+		for _, line := range found {
+			if line.Code != 0 {
+				seen[line.Code] = line
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return nil, false, errors.New("no lines found")
+	}
+
+	lines := make([]types.Line, 0, len(seen))
+	for _, line := range seen {
+		lines = append(lines, line)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Code < lines[j].Code
+	})
+	return lines, complete, nil
+}
+
+// outdatedLines returns the line codes whose stops were never fetched or are
+// older than the maximum age, never-fetched and oldest first
+func (cr *Crawler) outdatedLines(lines []types.Line) []int {
+	type pending struct {
+		code    int
+		fetched time.Time
+	}
+	var outdated []pending
+	cutoff := time.Now().Add(-cr.opts.MaxAge)
+	for _, line := range lines {
+		fetched, ok := cr.catalog.LineFetchedAt(line.Code)
+		if !ok || fetched.Before(cutoff) {
+			outdated = append(outdated, pending{line.Code, fetched})
+		}
+	}
+	sort.SliceStable(outdated, func(i, j int) bool {
+		return outdated[i].fetched.Before(outdated[j].fetched)
+	})
+
+	codes := make([]int, len(outdated))
+	for i, p := range outdated {
+		codes[i] = p.code
+	}
+	return codes
+}
+
+// fetchLineStops fetches the stops of each line, saving a checkpoint periodically
+func (cr *Crawler) fetchLineStops(ctx context.Context, codes []int) error {
+	cr.update(func(s *CrawlStatus) { s.LinesPending = len(codes) })
+	for i, code := range codes {
+		if err := cr.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		stops, err := cr.source.GetStopsByLine(ctx, code)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			cr.fail(err)
+		} else {
+			cr.catalog.SetLineStops(code, stops)
+			cr.update(func(s *CrawlStatus) { s.LinesFetched++ })
+		}
+		cr.update(func(s *CrawlStatus) { s.LinesPending = len(codes) - i - 1 })
+
+		if (i+1)%checkpointEvery == 0 {
+			if err := cr.save(); err != nil {
+				log.Printf("Catalog checkpoint failed: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// fetchCorridorStops fetches every corridor and its stops
+func (cr *Crawler) fetchCorridorStops(ctx context.Context) error {
+	if err := cr.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	corridors, err := cr.source.GetCorridors(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		cr.fail(err)
+		return nil
+	}
+	for _, corridor := range corridors {
+		if err := cr.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		stops, err := cr.source.GetStopsByCorridor(ctx, corridor.Code)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			cr.fail(err)
+			continue
+		}
+		cr.catalog.SetCorridorStops(corridor, stops)
+	}
+	return nil
+}
+
+// save persists the catalog when a path is configured
+func (cr *Crawler) save() error {
+	if cr.opts.Path == "" {
+		return nil
+	}
+	if err := cr.catalog.Save(cr.opts.Path); err != nil {
+		return fmt.Errorf("failed to save catalog: %w", err)
+	}
+	return nil
+}
+
+// fail records a failed request
+func (cr *Crawler) fail(err error) {
+	cr.update(func(s *CrawlStatus) {
+		s.Failures++
+		s.LastError = err.Error()
+	})
+}
+
+// update changes the status under the lock
+func (cr *Crawler) update(change func(s *CrawlStatus)) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	change(&cr.status)
+}
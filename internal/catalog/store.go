@@ -0,0 +1,128 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// fileFormat is the layout version of the catalog file; files written with
+// another layout are ignored and rebuilt by the crawler
+const fileFormat = 1
+
+// snapshot is the on-disk representation of a catalog
+type snapshot struct {
+	Format        int               `json:"format"`
+	Version       int               `json:"version"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	Stops         []types.Stop      `json:"stops"`
+	Lines         []types.Line      `json:"lines"`
+	LineStops     map[int][]int     `json:"line_stops"`
+	LineFetched   map[int]time.Time `json:"line_fetched"`
+	Corridors     []types.Corridor  `json:"corridors"`
+	CorridorStops map[int][]int     `json:"corridor_stops"`
+}
+
+// Save writes the catalog to path, replacing the file atomically
+func (c *Catalog) Save(path string) error {
+	c.mu.RLock()
+	snap := snapshot{
+		Format:        fileFormat,
+		Version:       c.version,
+		UpdatedAt:     c.updatedAt,
+		Stops:         make([]types.Stop, 0, len(c.stops)),
+		Lines:         make([]types.Line, 0, len(c.lines)),
+		LineStops:     c.lineStops,
+		LineFetched:   c.lineFetched,
+		Corridors:     make([]types.Corridor, 0, len(c.corridors)),
+		CorridorStops: c.corridorStops,
+	}
+	for _, stop := range c.stops {
+		snap.Stops = append(snap.Stops, stop)
+	}
+	for _, line := range c.lines {
+		snap.Lines = append(snap.Lines, line)
+	}
+	for _, corridor := range c.corridors {
+		snap.Corridors = append(snap.Corridors, corridor)
+	}
+	data, err := json.Marshal(snap)
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode catalog: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create catalog directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create catalog file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write catalog file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write catalog file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace catalog file: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the catalog contents with the file at path. A missing file
+// is reported with an error wrapping os.ErrNotExist.
+func (c *Catalog) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read catalog file: %w", err)
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode catalog file: %w", err)
+	}
+	if snap.Format != fileFormat {
+		return fmt.Errorf("catalog file has format %d, expected %d", snap.Format, fileFormat)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stops = make(map[int]types.Stop, len(snap.Stops))
+	c.addStops(snap.Stops)
+	c.lines = make(map[int]types.Line, len(snap.Lines))
+	for _, line := range snap.Lines {
+		c.lines[line.Code] = line
+	}
+	c.corridors = make(map[int]types.Corridor, len(snap.Corridors))
+	for _, corridor := range snap.Corridors {
+		c.corridors[corridor.Code] = corridor
+	}
+	c.lineStops = orEmpty(snap.LineStops)
+	c.corridorStops = orEmpty(snap.CorridorStops)
+	c.lineFetched = snap.LineFetched
+	if c.lineFetched == nil {
+		c.lineFetched = make(map[int]time.Time)
+	}
+	c.version = snap.Version
+	c.updatedAt = snap.UpdatedAt
+	c.index = nil
+	c.servedBy = nil
+	c.gazetteer = nil
+	c.network = nil
+	return nil
+}
+
+// orEmpty returns relations, or an empty map when it is nil
+func orEmpty(relations map[int][]int) map[int][]int {
+	if relations == nil {
+		return make(map[int][]int)
+	}
+	return relations
+}
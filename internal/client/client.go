@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -17,6 +18,7 @@ const BaseURL = "https://api.olhovivo.sptrans.com.br/v2.1"
 type Client struct {
 	authManager *auth.Manager
 	httpClient  *http.Client
+	limiter     *RateLimiter
 }
 
 // NewClient creates a new SPTrans API client
@@ -27,9 +29,34 @@ func NewClient(authManager *auth.Manager) *Client {
 	}
 }
 
+// SetRateLimit limits the client to perSecond requests per second; zero removes the limit
+func (c *Client) SetRateLimit(perSecond float64) {
+	c.limiter = NewRateLimiter(perSecond)
+}
+
 // makeRequest performs an authenticated HTTP request to the SPTrans API
 func (c *Client) makeRequest(ctx context.Context, endpoint string, result interface{}) error {
-	
+	return c.makeDecodingRequest(ctx, endpoint, func(dec *json.Decoder) error {
+		return dec.Decode(result)
+	})
+}
+
+// makeDecodingRequest performs an authenticated HTTP request and hands the response decoder to decode
+func (c *Client) makeDecodingRequest(ctx context.Context, endpoint string, decode func(dec *json.Decoder) error) error {
+	return c.doRequest(ctx, endpoint, func(body io.Reader) error {
+		if err := decode(json.NewDecoder(body)); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	})
+}
+
+// doRequest performs an authenticated HTTP request and hands the response body to read
+func (c *Client) doRequest(ctx context.Context, endpoint string, read func(body io.Reader) error) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait cancelled: %w", err)
+	}
+
 	if err := c.authManager.EnsureAuthenticated(ctx); err != nil {
 		return fmt.Errorf("authentication failed: %w", err)
 	}
@@ -56,11 +83,7 @@ func (c *Client) makeRequest(ctx context.Context, endpoint string, result interf
 		}
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return nil
+	return read(resp.Body)
 }
 
 // SearchLines searches for bus lines by name or number
@@ -144,6 +167,66 @@ func (c *Client) GetVehiclePositions(ctx context.Context) (*types.VehiclePositio
 	return &positions, nil
 }
 
+// StreamVehiclePositions gets real-time positions of all vehicles, decoding the
+// snapshot one line at a time and handing each line to visit so the full
+// response is never held in memory. It returns the snapshot timestamp.
+func (c *Client) StreamVehiclePositions(ctx context.Context, visit func(line types.VehicleLine)) (string, error) {
+	var hour string
+	err := c.makeDecodingRequest(ctx, "/Posicao", func(dec *json.Decoder) error {
+		if err := expectDelim(dec, '{'); err != nil {
+			return err
+		}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "hr":
+				if err := dec.Decode(&hour); err != nil {
+					return err
+				}
+			case "l":
+				if err := expectDelim(dec, '['); err != nil {
+					return err
+				}
+				for dec.More() {
+					var line types.VehicleLine
+					if err := dec.Decode(&line); err != nil {
+						return err
+					}
+					visit(line)
+				}
+				if err := expectDelim(dec, ']'); err != nil {
+					return err
+				}
+			default:
+				var skipped json.RawMessage
+				if err := dec.Decode(&skipped); err != nil {
+					return err
+				}
+			}
+		}
+		return expectDelim(dec, '}')
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get vehicle positions: %w", err)
+	}
+	return hour, nil
+}
+
+// expectDelim reads the next token and checks that it is the given delimiter
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %q, got %v", delim, tok)
+	}
+	return nil
+}
+
 // GetVehiclePositionsByLine gets real-time positions of vehicles on a specific line
 func (c *Client) GetVehiclePositionsByLine(ctx context.Context, lineCode int) (*types.VehiclePositions, error) {
 	endpoint := fmt.Sprintf("/Posicao/Linha?codigoLinha=%d", lineCode)
@@ -192,4 +275,22 @@ func (c *Client) GetArrivalPredictionsByStop(ctx context.Context, stopCode int)
 		return nil, fmt.Errorf("failed to get arrival predictions by stop: %w", err)
 	}
 	return &predictions, nil
-}
\ No newline at end of file
+}
+
+// maxKMZSize bounds the size of a downloaded KMZ file
+const maxKMZSize = 64 << 20
+
+// GetRoutesKMZ downloads the KMZ file with the shapes of every route in a direction
+func (c *Client) GetRoutesKMZ(ctx context.Context, direction int) ([]byte, error) {
+	endpoint := fmt.Sprintf("/KMZ?sentido=%d", direction)
+	var data []byte
+	err := c.doRequest(ctx, endpoint, func(body io.Reader) error {
+		var err error
+		data, err = io.ReadAll(io.LimitReader(body, maxKMZSize))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get routes KMZ: %w", err)
+	}
+	return data, nil
+}
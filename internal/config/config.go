@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the server configuration, read from flags and environment variables
+type Config struct {
+	Token  string // SPTrans API token
+	Format string // Default rendering format of tool results
+	Locale string // Default locale of descriptions, messages and rendered text
+
+	ToolGroups         string // Comma-separated tool groups to enable, empty for all
+	DisabledToolGroups string // Comma-separated tool groups to disable
+
+	ToolTimeout  time.Duration            // Default timeout of a tool call
+	ToolTimeouts map[string]time.Duration // Per-tool timeouts overriding the default
+
+	RateLimit float64 // Maximum SPTrans API requests per second, zero for no limit
+
+	CatalogPath    string        // File the network catalog is persisted to, empty to keep it in memory
+	CatalogRefresh time.Duration // Interval between catalog crawls, zero disables the crawler
+	CatalogMaxAge  time.Duration // Age after which the stops of a line are crawled again
+	CrawlRate      float64       // Maximum requests per second made by the crawler
+
+	ShapeRefresh time.Duration // Age after which the route shapes are downloaded again, zero disables them
+
+	GeofenceInterval time.Duration // Interval between snapshots evaluated for geofences and line speeds, zero disables both
+
+	WalkDetour float64 // Ratio of street to straight-line distance used for walking estimates
+	WalkSpeed  float64 // Walking speed in km/h used for walking estimates
+
+	FaresPath string // JSON file overriding the default fare table, empty for the defaults
+}
+
+// Load reads the configuration from command-line flags, falling back to environment variables
+func Load() (*Config, error) {
+	cfg := &Config{
+		Token: os.Getenv("SPTRANS_PAT"),
+	}
+
+	flag.StringVar(&cfg.Format, "format", envOr("SPTRANS_FORMAT", "json"), "Default output format of tool results: json, markdown, compact or geojson")
+	flag.StringVar(&cfg.Locale, "locale", envOr("SPTRANS_LOCALE", "en"), "Default locale of tool descriptions, messages and rendered text: en or pt-BR")
+	flag.StringVar(&cfg.ToolGroups, "tools", os.Getenv("SPTRANS_TOOL_GROUPS"), "Comma-separated tool groups to enable (lines, stops, positions, predictions, catalog, analytics, session, admin); empty enables all")
+	flag.StringVar(&cfg.DisabledToolGroups, "disable-tools", os.Getenv("SPTRANS_DISABLED_TOOL_GROUPS"), "Comma-separated tool groups to disable")
+	flag.DurationVar(&cfg.ToolTimeout, "tool-timeout", envDuration("SPTRANS_TOOL_TIMEOUT", 30*time.Second), "Default timeout of a tool call")
+	timeouts := flag.String("tool-timeouts", os.Getenv("SPTRANS_TOOL_TIMEOUTS"), "Comma-separated per-tool timeouts, e.g. get_vehicle_positions=60s")
+	flag.Float64Var(&cfg.RateLimit, "rate-limit", envFloat("SPTRANS_RATE_LIMIT", 10), "Maximum SPTrans API requests per second, 0 for no limit")
+	flag.StringVar(&cfg.CatalogPath, "catalog", envOr("SPTRANS_CATALOG_PATH", defaultCatalogPath()), "File the network catalog is persisted to, empty to keep it in memory")
+	flag.DurationVar(&cfg.CatalogRefresh, "catalog-refresh", envDuration("SPTRANS_CATALOG_REFRESH", 6*time.Hour), "Interval between network catalog crawls, 0 disables the crawler")
+	flag.DurationVar(&cfg.CatalogMaxAge, "catalog-max-age", envDuration("SPTRANS_CATALOG_MAX_AGE", 7*24*time.Hour), "Age after which the stops of a line are crawled again")
+	flag.Float64Var(&cfg.CrawlRate, "crawl-rate", envFloat("SPTRANS_CRAWL_RATE", 2), "Maximum requests per second made by the catalog crawler")
+	flag.DurationVar(&cfg.ShapeRefresh, "shape-refresh", envDuration("SPTRANS_SHAPE_REFRESH", 24*time.Hour), "Age after which the KMZ route shapes are downloaded again, 0 disables them")
+	flag.DurationVar(&cfg.GeofenceInterval, "geofence-interval", envDuration("SPTRANS_GEOFENCE_INTERVAL", 30*time.Second), "Interval between vehicle position snapshots evaluated for geofences and line speeds, 0 disables both")
+	flag.Float64Var(&cfg.WalkDetour, "walk-detour", envFloat("SPTRANS_WALK_DETOUR", 1.3), "Ratio of street to straight-line distance used for walking estimates")
+	flag.Float64Var(&cfg.WalkSpeed, "walk-speed", envFloat("SPTRANS_WALK_SPEED", 4.5), "Walking speed in km/h used for walking estimates")
+	flag.StringVar(&cfg.FaresPath, "fares", os.Getenv("SPTRANS_FARES"), "JSON file overriding the default fare table and integration rules")
+	flag.Parse()
+
+	var err error
+	if cfg.ToolTimeouts, err = parseTimeouts(*timeouts); err != nil {
+		return nil, err
+	}
+
+	if cfg.WalkDetour < 1 || cfg.WalkSpeed <= 0 {
+		return nil, errors.New("walk-detour must be at least 1 and walk-speed positive")
+	}
+
+	if cfg.Token == "" {
+		return nil, errors.New("SPTRANS_PAT environment variable is required")
+	}
+	return cfg, nil
+}
+
+// envDuration returns the duration in the environment variable, or fallback when unset or invalid
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return d
+	}
+	return fallback
+}
+
+// envFloat returns the number in the environment variable, or fallback when unset or invalid
+func envFloat(key string, fallback float64) float64 {
+	if f, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return f
+	}
+	return fallback
+}
+
+// defaultCatalogPath returns the catalog file in the user cache directory,
+// or an empty path when there is none
+func defaultCatalogPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sptrans-mcp", "catalog.json")
+}
+
+// parseTimeouts parses a comma-separated list of tool=duration pairs
+func parseTimeouts(list string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, pair := range strings.Split(list, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tool timeout %q, expected tool=duration", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tool timeout %q: %w", pair, err)
+		}
+		timeouts[strings.TrimSpace(name)] = d
+	}
+	return timeouts, nil
+}
+
+// envOr returns the value of the environment variable, or fallback when unset
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
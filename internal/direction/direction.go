@@ -0,0 +1,197 @@
+package direction
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geocode"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Threshold is the lowest score a direction needs to match a text
+const Threshold = 0.6
+
+// margin is the lead the best direction needs over a different direction of
+// the same line to be chosen
+const margin = 0.1
+
+// stopWeight discounts matches against the stops of an itinerary, so that a
+// terminal name wins over a stop of the same name
+const stopWeight = 0.9
+
+// Ways a direction can match a text
+const (
+	ByTerminal = "terminal"
+	ByStop     = "stop"
+)
+
+var (
+	// ErrNoMatch is returned when no direction matches the text
+	ErrNoMatch = errors.New("no direction matches")
+	// ErrAmbiguous is returned when different directions match the text equally well
+	ErrAmbiguous = errors.New("several directions match")
+)
+
+// stopWords are connecting words ignored when matching names
+var stopWords = map[string]bool{
+	"de": true, "da": true, "do": true, "das": true, "dos": true, "e": true,
+	"a": true, "o": true, "para": true, "sentido": true, "ate": true,
+}
+
+// Candidate is a direction of a line that may be taken, with the stops of its
+// itinerary when known
+type Candidate struct {
+	Line  types.Line
+	Stops []types.Stop // Ordered stops of the itinerary, nil when unknown
+	From  int          // Index in Stops of the stop the rider boards at, or -1 when not known
+}
+
+// Match is how well a direction matches a text
+type Match struct {
+	Line  types.Line
+	Score float64 // From 0 to 1
+	By    string  // ByTerminal or ByStop, empty when nothing matched
+	Name  string  // Terminal or stop name matched
+}
+
+// Terminals returns the terminals a direction heads to: its destination, or
+// both ends of the loop for a circular line, which runs a single direction
+// out from the origin and back
+func Terminals(line types.Line) []string {
+	if line.IsCircular {
+		return []string{line.Destination, line.Origin}
+	}
+	return []string{line.Headsign()}
+}
+
+// Score matches a text against the terminals a direction heads to and
+// against the stops still ahead on its itinerary. Without a boarding stop,
+// the stops of a non-circular line count more the further along they are.
+func Score(towards string, c Candidate) Match {
+	query := Words(towards)
+	best := Match{Line: c.Line}
+	for _, terminal := range Terminals(c.Line) {
+		if s := similarity(query, Words(terminal)); s > best.Score {
+			best = Match{Line: c.Line, Score: s, By: ByTerminal, Name: terminal}
+		}
+	}
+	for i, stop := range c.Stops {
+		if c.From >= 0 && i <= c.From {
+			continue
+		}
+		s := similarity(query, Words(stop.Name)) * stopWeight
+		if c.From < 0 && !c.Line.IsCircular && len(c.Stops) > 1 {
+			s *= 0.5 + 0.5*float64(i)/float64(len(c.Stops)-1)
+		}
+		if s > best.Score {
+			best = Match{Line: c.Line, Score: s, By: ByStop, Name: stop.Name}
+		}
+	}
+	return best
+}
+
+// Rank scores every candidate, best first
+func Rank(towards string, candidates []Candidate) []Match {
+	matches := make([]Match, len(candidates))
+	for i, c := range candidates {
+		matches[i] = Score(towards, c)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// Resolve returns the direction that best matches a text, failing with
+// ErrNoMatch when none reaches Threshold and with ErrAmbiguous when another
+// direction of the same line scores almost as well
+func Resolve(towards string, candidates []Candidate) (Match, error) {
+	matches := Rank(towards, candidates)
+	if len(matches) == 0 || matches[0].Score < Threshold {
+		return Match{}, ErrNoMatch
+	}
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if m.Line.Code != best.Line.Code && m.Line.Sign() == best.Line.Sign() &&
+			m.Line.Direction != best.Line.Direction && best.Score-m.Score < margin {
+			return best, ErrAmbiguous
+		}
+	}
+	return best, nil
+}
+
+// Words folds a name to lowercase ASCII words, expanding abbreviations as the
+// gazetteer does for stop names and dropping connecting words
+func Words(text string) []string {
+	var words []string
+	for _, word := range geocode.PlaceWords(text) {
+		if !stopWords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// similarity returns the share of the query words found in the name, a
+// partial credit being given for prefixes and single typos
+func similarity(query, name []string) float64 {
+	if len(query) == 0 || len(name) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, q := range query {
+		best := 0.0
+		for _, n := range name {
+			best = max(best, wordSimilarity(q, n))
+		}
+		total += best
+	}
+	return total / float64(len(query))
+}
+
+// wordSimilarity compares two words: 1 when equal, partial credit when one
+// starts with the other or they differ by a single letter
+func wordSimilarity(a, b string) float64 {
+	switch {
+	case a == b:
+		return 1
+	case isNumber(a) || isNumber(b):
+		return 0
+	case len(a) >= 3 && strings.HasPrefix(b, a):
+		return 0.9
+	case len(b) >= 3 && strings.HasPrefix(a, b):
+		return 0.8
+	case len(a) >= 5 && len(b) >= 5 && oneEdit(a, b):
+		return 0.8
+	}
+	return 0
+}
+
+// isNumber reports whether a word is made of digits, or is a roman numeral
+// such as the II of "D. Pedro II"
+func isNumber(word string) bool {
+	return strings.Trim(word, "0123456789") == "" || strings.Trim(word, "ivx") == ""
+}
+
+// oneEdit reports whether two words differ by one inserted, deleted,
+// replaced or swapped letter
+func oneEdit(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b)-len(a) > 1 {
+		return false
+	}
+	i := 0
+	for i < len(a) && a[i] == b[i] {
+		i++
+	}
+	if len(a) == len(b) {
+		if i+1 < len(a) && a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:] {
+			return true
+		}
+		return i == len(a) || a[i+1:] == b[i+1:]
+	}
+	return a[i:] == b[i+1:]
+}
@@ -0,0 +1,139 @@
+package direction
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Both directions of 875A-10, from Aclimação to Pq. D. Pedro II and back,
+// and the single direction of a circular line between two terminals
+var (
+	outbound = types.Line{Code: 1, Number: "875A", Type: 10, Direction: 1, Origin: "Aclimação", Destination: "Term. Pq. D. Pedro II"}
+	inbound  = types.Line{Code: 2, Number: "875A", Type: 10, Direction: 2, Origin: "Aclimação", Destination: "Term. Pq. D. Pedro II"}
+	circular = types.Line{Code: 3, Number: "6450", Type: 10, Direction: 1, IsCircular: true, Origin: "Term. Capelinha", Destination: "Shop. Morumbi"}
+)
+
+var itinerary = []types.Stop{
+	{Code: 10, Name: "Aclimação"},
+	{Code: 11, Name: "Hospital das Clínicas"},
+	{Code: 12, Name: "Praça da Sé"},
+	{Code: 13, Name: "Term. Pq. D. Pedro II"},
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Term. Pq. D. Pedro II", []string{"terminal", "parque", "dom", "pedro", "ii"}},
+		{"Sentido Jd. Ângela", []string{"jardim", "angela"}},
+		{"Shop. Morumbi", []string{"shopping", "morumbi"}},
+		{"Est. da Luz", []string{"estacao", "luz"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Words(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Words(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"pedro", "pedro", 1},
+		{"ped", "pedro", 0.9},
+		{"pedro", "ped", 0.8},
+		{"pe", "pedro", 0},
+		{"capelinha", "capelina", 0.8},
+		{"morumbi", "morumbl", 0.8},
+		{"clinicas", "cilnicas", 0.8},
+		{"luz", "lua", 0},
+		{"ii", "iii", 0},
+		{"2", "20", 0},
+	}
+	for _, tt := range tests {
+		if got := wordSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("wordSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		towards string
+		c       Candidate
+		score   float64
+		by      string
+		matched string
+	}{
+		{"destination", "Pq. D. Pedro II", Candidate{Line: outbound, From: -1}, 1, ByTerminal, "Term. Pq. D. Pedro II"},
+		{"full destination", "Terminal Parque Dom Pedro II", Candidate{Line: outbound, From: -1}, 1, ByTerminal, "Term. Pq. D. Pedro II"},
+		{"origin of the other direction", "Aclimação", Candidate{Line: inbound, From: -1}, 1, ByTerminal, "Aclimação"},
+		{"not the origin", "Aclimação", Candidate{Line: outbound, From: -1}, 0, "", ""},
+		{"stop late on the itinerary", "Sé", Candidate{Line: outbound, Stops: itinerary, From: -1}, 0.9 * (0.5 + 0.5*2.0/3), ByStop, "Praça da Sé"},
+		{"stop ahead of the boarding stop", "Sé", Candidate{Line: outbound, Stops: itinerary, From: 1}, 0.9, ByStop, "Praça da Sé"},
+		{"stop behind the boarding stop", "Hospital das Clínicas", Candidate{Line: outbound, Stops: itinerary, From: 2}, 0, "", ""},
+		{"terminal beats a stop of the same name", "D. Pedro II", Candidate{Line: outbound, Stops: itinerary, From: 0}, 1, ByTerminal, "Term. Pq. D. Pedro II"},
+		{"circular destination", "Morumbi", Candidate{Line: circular, From: -1}, 1, ByTerminal, "Shop. Morumbi"},
+		{"circular origin", "Capelinha", Candidate{Line: circular, From: -1}, 1, ByTerminal, "Term. Capelinha"},
+		{"typo", "Capelina", Candidate{Line: circular, From: -1}, 0.8, ByTerminal, "Term. Capelinha"},
+	}
+	for _, tt := range tests {
+		m := Score(tt.towards, tt.c)
+		if math.Abs(m.Score-tt.score) > 1e-9 || m.By != tt.by || m.Name != tt.matched {
+			t.Errorf("%s: Score(%q) = %.3f by %q %q, want %.3f by %q %q", tt.name, tt.towards, m.Score, m.By, m.Name, tt.score, tt.by, tt.matched)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	both := []Candidate{{Line: outbound, From: -1}, {Line: inbound, From: -1}}
+	tests := []struct {
+		name       string
+		towards    string
+		candidates []Candidate
+		line       int
+		err        error
+	}{
+		{"towards the destination", "Pedro II", both, outbound.Code, nil},
+		{"towards the origin", "Aclimacao", both, inbound.Code, nil},
+		{"nothing matches", "Santana", both, 0, ErrNoMatch},
+		{"weak match", "Parque Ibirapuera", both, 0, ErrNoMatch},
+		{"no candidates", "Pedro II", nil, 0, ErrNoMatch},
+		{"circular line either way", "Term. Capelinha", []Candidate{{Line: circular, From: -1}}, circular.Code, nil},
+		{"both directions equally", "Terminal", []Candidate{
+			{Line: types.Line{Code: 4, Number: "5100", Type: 10, Direction: 1, Origin: "Term. Bandeira", Destination: "Term. Sacomã"}, From: -1},
+			{Line: types.Line{Code: 5, Number: "5100", Type: 10, Direction: 2, Origin: "Term. Bandeira", Destination: "Term. Sacomã"}, From: -1},
+		}, 4, ErrAmbiguous},
+	}
+	for _, tt := range tests {
+		m, err := Resolve(tt.towards, tt.candidates)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if tt.err != ErrNoMatch && m.Line.Code != tt.line {
+			t.Errorf("%s: resolved line %d, want %d", tt.name, m.Line.Code, tt.line)
+		}
+	}
+}
+
+func TestTerminals(t *testing.T) {
+	if got := Terminals(outbound); !slices.Equal(got, []string{"Term. Pq. D. Pedro II"}) {
+		t.Errorf("Terminals(outbound) = %v", got)
+	}
+	if got := Terminals(inbound); !slices.Equal(got, []string{"Aclimação"}) {
+		t.Errorf("Terminals(inbound) = %v", got)
+	}
+	if got := Terminals(circular); !slices.Equal(got, []string{"Shop. Morumbi", "Term. Capelinha"}) {
+		t.Errorf("Terminals(circular) = %v", got)
+	}
+}
@@ -0,0 +1,189 @@
+package fare
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Modes of transport a fare is charged for
+const (
+	Bus  = "bus"  // SPTrans municipal buses
+	Rail = "rail" // Metrô and CPTM trains
+)
+
+// Fare categories
+const (
+	Full    = "full"
+	Student = "student"
+	Elderly = "elderly"
+)
+
+// Reasons a boarding starts a new integration instead of joining the open one
+const (
+	BreakWindow    = "window"    // The integration window has expired
+	BreakBoardings = "boardings" // The integration already has the maximum number of boardings
+	BreakRail      = "rail"      // The integration already includes a rail entry
+)
+
+// Table holds the fares, in cents, and the Bilhete Único integration rules
+type Table struct {
+	Bus           int            `json:"bus"`            // Single bus fare
+	Rail          int            `json:"rail"`           // Single metro or train fare
+	Integration   int            `json:"integration"`    // Fare of an integration with buses and one rail entry
+	WindowMinutes int            `json:"window_minutes"` // Time from the first boarding in which later boardings are integrated
+	MaxBoardings  int            `json:"max_boardings"`  // Boardings per integration, the rail entry included
+	Categories    map[string]int `json:"categories"`     // Percentage of the fare paid by each category
+}
+
+// Default is the 2025 fare table of São Paulo
+var Default = Table{
+	Bus:           500,
+	Rail:          520,
+	Integration:   890,
+	WindowMinutes: 180,
+	MaxBoardings:  4,
+	Categories:    map[string]int{Full: 100, Student: 50, Elderly: 0},
+}
+
+// Load reads a fare table from a JSON file, keeping the defaults for the
+// fields it leaves out
+func Load(path string) (Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Table{}, fmt.Errorf("failed to read fare table: %w", err)
+	}
+	t := Default
+	t.Categories = nil
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Table{}, fmt.Errorf("failed to parse fare table: %w", err)
+	}
+	if t.Categories == nil {
+		t.Categories = Default.Categories
+	}
+	if err := t.Validate(); err != nil {
+		return Table{}, err
+	}
+	return t, nil
+}
+
+// Validate checks that the fares and rules are usable
+func (t Table) Validate() error {
+	if t.Bus <= 0 || t.Rail <= 0 || t.Integration <= 0 {
+		return errors.New("fares must be positive")
+	}
+	if t.WindowMinutes <= 0 || t.MaxBoardings <= 0 {
+		return errors.New("window_minutes and max_boardings must be positive")
+	}
+	if _, ok := t.Categories[Full]; !ok {
+		return errors.New("the full fare category is required")
+	}
+	for category, percent := range t.Categories {
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("category %s must pay between 0 and 100 percent", category)
+		}
+	}
+	return nil
+}
+
+// Window returns the integration window
+func (t Table) Window() time.Duration {
+	return time.Duration(t.WindowMinutes) * time.Minute
+}
+
+// Leg is a boarding to be charged
+type Leg struct {
+	Mode string
+	At   time.Duration // Time of the boarding since the first one
+}
+
+// Charge is what a boarding costs
+type Charge struct {
+	Amount     int    // Cents charged at this boarding
+	Integrated bool   // Whether the boarding joins an integration started earlier
+	Break      string // Why the boarding could not join the open integration, if it did not
+}
+
+// Result is the cost of a sequence of boardings
+type Result struct {
+	Total   int // Cents paid
+	Fares   int // Integrations started, each paying at least one fare
+	Charges []Charge
+}
+
+// Integrated reports whether every boarding after the first joined an integration
+func (r Result) Integrated() bool {
+	return r.Fares <= 1
+}
+
+// integration is an open Bilhete Único integration
+type integration struct {
+	start     time.Duration
+	boardings int
+	buses     bool
+	rail      bool
+	paid      int
+}
+
+// Calculate charges the legs in order for a category, joining each boarding
+// to the open integration while the rules allow it. Joining an integration
+// costs the difference between its new fare and what it has paid so far.
+func (t Table) Calculate(legs []Leg, category string) (Result, error) {
+	percent, ok := t.Categories[category]
+	if !ok {
+		return Result{}, fmt.Errorf("unknown fare category %q", category)
+	}
+
+	var result Result
+	var open *integration
+	for _, leg := range legs {
+		if leg.Mode != Bus && leg.Mode != Rail {
+			return Result{}, fmt.Errorf("unknown mode %q", leg.Mode)
+		}
+
+		charge := Charge{}
+		if open != nil {
+			switch {
+			case leg.At-open.start > t.Window():
+				charge.Break = BreakWindow
+			case open.boardings >= t.MaxBoardings:
+				charge.Break = BreakBoardings
+			case leg.Mode == Rail && open.rail:
+				charge.Break = BreakRail
+			default:
+				charge.Integrated = true
+			}
+		}
+		if !charge.Integrated {
+			open = &integration{start: leg.At}
+			result.Fares++
+		}
+
+		open.boardings++
+		open.buses = open.buses || leg.Mode == Bus
+		open.rail = open.rail || leg.Mode == Rail
+		price := t.price(open.buses, open.rail, percent)
+		charge.Amount = price - open.paid
+		open.paid = price
+
+		result.Total += charge.Amount
+		result.Charges = append(result.Charges, charge)
+	}
+	return result, nil
+}
+
+// price returns the fare of an integration with buses, rail or both for a
+// category paying the given percentage
+func (t Table) price(buses, rail bool, percent int) int {
+	fare := t.Bus
+	switch {
+	case buses && rail:
+		fare = t.Integration
+	case rail:
+		fare = t.Rail
+	}
+	return int(math.Round(float64(fare*percent) / 100))
+}
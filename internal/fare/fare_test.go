@@ -0,0 +1,96 @@
+package fare
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func bus(minutes int) Leg  { return Leg{Mode: Bus, At: time.Duration(minutes) * time.Minute} }
+func rail(minutes int) Leg { return Leg{Mode: Rail, At: time.Duration(minutes) * time.Minute} }
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name     string
+		legs     []Leg
+		category string
+		total    int
+		fares    int
+		charges  []Charge
+	}{
+		{"single bus", []Leg{bus(0)}, Full, 500, 1, []Charge{{Amount: 500}}},
+		{"single rail", []Leg{rail(0)}, Full, 520, 1, []Charge{{Amount: 520}}},
+		{"bus to bus", []Leg{bus(0), bus(40)}, Full, 500, 1, []Charge{{Amount: 500}, {Integrated: true}}},
+		{"bus to rail", []Leg{bus(0), rail(30)}, Full, 890, 1, []Charge{{Amount: 500}, {Amount: 390, Integrated: true}}},
+		{"rail to bus", []Leg{rail(0), bus(30)}, Full, 890, 1, []Charge{{Amount: 520}, {Amount: 370, Integrated: true}}},
+		{"last minute of the window", []Leg{bus(0), bus(180)}, Full, 500, 1, []Charge{{Amount: 500}, {Integrated: true}}},
+		{"window expired", []Leg{bus(0), bus(181)}, Full, 1000, 2, []Charge{{Amount: 500}, {Amount: 500, Break: BreakWindow}}},
+		{"window counts from the first boarding", []Leg{bus(0), bus(120), bus(200)}, Full, 1000, 2,
+			[]Charge{{Amount: 500}, {Integrated: true}, {Amount: 500, Break: BreakWindow}}},
+		{"four boardings", []Leg{bus(0), bus(10), rail(20), bus(30)}, Full, 890, 1,
+			[]Charge{{Amount: 500}, {Integrated: true}, {Amount: 390, Integrated: true}, {Integrated: true}}},
+		{"fifth boarding", []Leg{bus(0), bus(10), bus(20), bus(30), bus(40)}, Full, 1000, 2,
+			[]Charge{{Amount: 500}, {Integrated: true}, {Integrated: true}, {Integrated: true}, {Amount: 500, Break: BreakBoardings}}},
+		{"second rail entry", []Leg{rail(0), bus(20), rail(40)}, Full, 1410, 2,
+			[]Charge{{Amount: 520}, {Amount: 370, Integrated: true}, {Amount: 520, Break: BreakRail}}},
+		{"student", []Leg{bus(0), rail(30)}, Student, 445, 1, []Charge{{Amount: 250}, {Amount: 195, Integrated: true}}},
+		{"elderly", []Leg{bus(0), rail(30), bus(200)}, Elderly, 0, 2,
+			[]Charge{{}, {Integrated: true}, {Break: BreakWindow}}},
+		{"no boardings", nil, Full, 0, 0, nil},
+	}
+	for _, tt := range tests {
+		result, err := Default.Calculate(tt.legs, tt.category)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if result.Total != tt.total || result.Fares != tt.fares || !reflect.DeepEqual(result.Charges, tt.charges) {
+			t.Errorf("%s: total %d in %d fares %+v, want %d in %d fares %+v", tt.name, result.Total, result.Fares, result.Charges, tt.total, tt.fares, tt.charges)
+		}
+		if result.Integrated() != (tt.fares <= 1) {
+			t.Errorf("%s: Integrated() = %v", tt.name, result.Integrated())
+		}
+	}
+}
+
+func TestCalculateErrors(t *testing.T) {
+	if _, err := Default.Calculate([]Leg{bus(0)}, "tourist"); err == nil {
+		t.Error("an unknown category was accepted")
+	}
+	if _, err := Default.Calculate([]Leg{{Mode: "ferry"}}, Full); err == nil {
+		t.Error("an unknown mode was accepted")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Table
+		fails   bool
+	}{
+		{"defaults kept", `{"bus": 440}`, Table{Bus: 440, Rail: 520, Integration: 890, WindowMinutes: 180, MaxBoardings: 4, Categories: Default.Categories}, false},
+		{"categories replaced", `{"categories": {"full": 100, "teacher": 50}}`, Table{Bus: 500, Rail: 520, Integration: 890, WindowMinutes: 180, MaxBoardings: 4, Categories: map[string]int{Full: 100, "teacher": 50}}, false},
+		{"no full category", `{"categories": {"student": 50}}`, Table{}, true},
+		{"negative fare", `{"rail": -1}`, Table{}, true},
+		{"percent over 100", `{"categories": {"full": 150}}`, Table{}, true},
+		{"no window", `{"window_minutes": 0}`, Table{}, true},
+		{"not json", `bus = 5`, Table{}, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "fares.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Load(path)
+		if (err != nil) != tt.fails {
+			t.Errorf("%s: error %v, want failure %v", tt.name, err, tt.fails)
+			continue
+		}
+		if !tt.fails && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: loaded %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// MaxZoom is the deepest web map zoom level
+	MaxZoom = 22
+	// clustersPerTile is the number of cluster cells across a 256 px map tile
+	clustersPerTile = 4
+	// tilesPerViewport is the typical number of map tiles across a viewport
+	tilesPerViewport = 4
+)
+
+// Cluster aggregates the points that fall in one cell of a clustering grid
+type Cluster struct {
+	Latitude  float64 // Centroid latitude
+	Longitude float64 // Centroid longitude
+	Count     int     // Number of points
+	South     float64 // Southern latitude of the points
+	West      float64 // Western longitude of the points
+	North     float64 // Northern latitude of the points
+	East      float64 // Eastern longitude of the points
+}
+
+// Clusters groups points into square cells so that a wide view can show
+// aggregated counts instead of every point
+type Clusters struct {
+	cellSize float64
+	cells    map[cell]*Cluster
+}
+
+// NewClusters creates an empty clustering grid with the given cell size in degrees
+func NewClusters(cellSize float64) *Clusters {
+	return &Clusters{
+		cellSize: cellSize,
+		cells:    make(map[cell]*Cluster),
+	}
+}
+
+// Add adds a point to the cluster of its cell
+func (c *Clusters) Add(lat, lon float64) {
+	key := cellAt(lat, lon, c.cellSize)
+	cluster, ok := c.cells[key]
+	if !ok {
+		cluster = &Cluster{South: lat, West: lon, North: lat, East: lon}
+		c.cells[key] = cluster
+	}
+	// Latitude and Longitude hold running sums until List computes centroids
+	cluster.Latitude += lat
+	cluster.Longitude += lon
+	cluster.Count++
+	cluster.South = math.Min(cluster.South, lat)
+	cluster.West = math.Min(cluster.West, lon)
+	cluster.North = math.Max(cluster.North, lat)
+	cluster.East = math.Max(cluster.East, lon)
+}
+
+// List returns the clusters, largest first
+func (c *Clusters) List() []Cluster {
+	clusters := make([]Cluster, 0, len(c.cells))
+	for _, cluster := range c.cells {
+		centroid := *cluster
+		centroid.Latitude /= float64(cluster.Count)
+		centroid.Longitude /= float64(cluster.Count)
+		clusters = append(clusters, centroid)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Count != clusters[j].Count {
+			return clusters[i].Count > clusters[j].Count
+		}
+		if clusters[i].Latitude != clusters[j].Latitude {
+			return clusters[i].Latitude < clusters[j].Latitude
+		}
+		return clusters[i].Longitude < clusters[j].Longitude
+	})
+	return clusters
+}
+
+// ZoomCellSize returns the clustering cell size in degrees for a web map zoom level
+func ZoomCellSize(zoom int) float64 {
+	return 360 / math.Exp2(float64(zoom)) / clustersPerTile
+}
+
+// ZoomForSpan estimates the web map zoom level that shows a longitude span
+// in degrees across a typical viewport
+func ZoomForSpan(lonSpan float64) int {
+	if lonSpan <= 0 {
+		return MaxZoom
+	}
+	zoom := int(math.Floor(math.Log2(360 * tilesPerViewport / lonSpan)))
+	return max(0, min(MaxZoom, zoom))
+}
@@ -0,0 +1,75 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClusters(t *testing.T) {
+	c := NewClusters(0.01)
+	// Three points in one cell, two in the next one east and one alone
+	for _, p := range []Point{
+		{Latitude: -23.551, Longitude: -46.639},
+		{Latitude: -23.553, Longitude: -46.635},
+		{Latitude: -23.555, Longitude: -46.631},
+		{Latitude: -23.551, Longitude: -46.629},
+		{Latitude: -23.559, Longitude: -46.621},
+		{Latitude: -23.401, Longitude: -46.401},
+	} {
+		c.Add(p.Latitude, p.Longitude)
+	}
+
+	want := []Cluster{
+		{Latitude: -23.553, Longitude: -46.635, Count: 3, South: -23.555, West: -46.639, North: -23.551, East: -46.631},
+		{Latitude: -23.555, Longitude: -46.625, Count: 2, South: -23.559, West: -46.629, North: -23.551, East: -46.621},
+		{Latitude: -23.401, Longitude: -46.401, Count: 1, South: -23.401, West: -46.401, North: -23.401, East: -46.401},
+	}
+	got := c.List()
+	if len(got) != len(want) {
+		t.Fatalf("List() returned %d clusters, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Count != w.Count || !near(g.Latitude, w.Latitude) || !near(g.Longitude, w.Longitude) ||
+			g.South != w.South || g.West != w.West || g.North != w.North || g.East != w.East {
+			t.Errorf("cluster %d = %+v, want %+v", i, g, w)
+		}
+	}
+
+	// List computes centroids without changing the sums kept for later points
+	c.Add(-23.401, -46.401)
+	if again := c.List(); again[2].Count != 2 || !near(again[2].Latitude, -23.401) {
+		t.Errorf("adding after List gave %+v", again)
+	}
+}
+
+func TestZoom(t *testing.T) {
+	tests := []struct {
+		lonSpan float64
+		zoom    int
+	}{
+		{360, 2},
+		{0.1, 13},
+		{0.01, 17},
+		{1e-9, MaxZoom},
+		{0, MaxZoom},
+		{100000, 0},
+	}
+	for _, tt := range tests {
+		if got := ZoomForSpan(tt.lonSpan); got != tt.zoom {
+			t.Errorf("ZoomForSpan(%v) = %d, want %d", tt.lonSpan, got, tt.zoom)
+		}
+	}
+	for zoom := 1; zoom <= MaxZoom; zoom++ {
+		if ratio := ZoomCellSize(zoom-1) / ZoomCellSize(zoom); !near(ratio, 2) {
+			t.Errorf("cells at zoom %d are %v times those at zoom %d, want 2", zoom-1, ratio, zoom)
+		}
+	}
+	if size := ZoomCellSize(0); size != 90 {
+		t.Errorf("ZoomCellSize(0) = %v, want 90", size)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
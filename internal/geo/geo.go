@@ -0,0 +1,95 @@
+package geo
+
+import "math"
+
+// EarthRadius is the mean Earth radius in meters
+const EarthRadius = 6371000.0
+
+const (
+	// WalkingDetourFactor scales straight-line distances to typical street distances
+	WalkingDetourFactor = 1.3
+	// WalkingSpeed is a typical walking speed in meters per second (about 4.5 km/h)
+	WalkingSpeed = 1.25
+)
+
+// Distance returns the haversine distance in meters between two coordinates
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := radians(lat1)
+	phi2 := radians(lat2)
+	dPhi := radians(lat2 - lat1)
+	dLambda := radians(lon2 - lon1)
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Bearing returns the initial compass bearing in degrees (0 to 360, clockwise
+// from north) from the first coordinate to the second
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := radians(lat1)
+	phi2 := radians(lat2)
+	dLambda := radians(lon2 - lon1)
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// compassPoints are the eight compass directions, clockwise from north
+var compassPoints = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// Compass returns the nearest of the eight compass directions for a bearing
+func Compass(bearing float64) string {
+	index := int(math.Round(math.Mod(bearing+360, 360)/45)) % len(compassPoints)
+	return compassPoints[index]
+}
+
+// Walker estimates street distances and walking times from straight-line distances
+type Walker struct {
+	DetourFactor float64 // Ratio of the street distance to the straight-line distance
+	Speed        float64 // Walking speed in meters per second
+}
+
+// DefaultWalker uses the typical detour factor and walking speed
+var DefaultWalker = Walker{DetourFactor: WalkingDetourFactor, Speed: WalkingSpeed}
+
+// Distance estimates the street distance in meters for a straight-line distance
+func (w Walker) Distance(distance float64) float64 {
+	return distance * w.DetourFactor
+}
+
+// Time estimates the time in seconds to walk a straight-line distance in meters
+func (w Walker) Time(distance float64) float64 {
+	return w.Distance(distance) / w.Speed
+}
+
+// Reach estimates the straight-line distance in meters walked in a number of seconds
+func (w Walker) Reach(seconds float64) float64 {
+	return seconds * w.Speed / w.DetourFactor
+}
+
+// ValidCoordinate reports whether the latitude and longitude are in range
+func ValidCoordinate(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// radians converts degrees to radians
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// metersPerDegreeLat is the length of a degree of latitude in meters
+const metersPerDegreeLat = math.Pi * EarthRadius / 180
+
+// degreeSpan returns the latitude and longitude spans in degrees covering a
+// distance in meters around the given latitude
+func degreeSpan(lat, meters float64) (dLat, dLon float64) {
+	dLat = meters / metersPerDegreeLat
+	cos := math.Cos(radians(lat))
+	if cos < 0.01 {
+		cos = 0.01
+	}
+	dLon = meters / (metersPerDegreeLat * cos)
+	return dLat, dLon
+}
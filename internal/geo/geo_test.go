@@ -0,0 +1,88 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"same point", -23.55, -46.63, -23.55, -46.63, 0},
+		{"degree of latitude", 0, 0, 1, 0, 111195},
+		{"degree of longitude at the equator", 0, 0, 0, 1, 111195},
+		{"degree of longitude in São Paulo", -23.55, -46.63, -23.55, -45.63, 101946},
+		{"Sé to Paulista", -23.5503, -46.6339, -23.5614, -46.6559, 2560},
+		{"antipodes", 0, 0, 0, 180, math.Pi * EarthRadius},
+	}
+	for _, tt := range tests {
+		got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+		if math.Abs(got-tt.want) > tt.want*0.001+0.01 {
+			t.Errorf("%s: Distance = %.1f, want %.1f", tt.name, got, tt.want)
+		}
+		if back := Distance(tt.lat2, tt.lon2, tt.lat1, tt.lon1); math.Abs(back-got) > 1e-6 {
+			t.Errorf("%s: Distance is not symmetric: %.3f and %.3f", tt.name, got, back)
+		}
+	}
+}
+
+func TestBearing(t *testing.T) {
+	tests := []struct {
+		lat2, lon2 float64
+		want       float64
+		compass    string
+	}{
+		{1, 0, 0, "N"},
+		{1, 1, 45, "NE"},
+		{0, 1, 90, "E"},
+		{-1, 0, 180, "S"},
+		{0, -1, 270, "W"},
+		{1, -1, 315, "NW"},
+	}
+	for _, tt := range tests {
+		got := Bearing(0, 0, tt.lat2, tt.lon2)
+		if math.Abs(got-tt.want) > 0.5 {
+			t.Errorf("Bearing to %v, %v = %.1f, want %.1f", tt.lat2, tt.lon2, got, tt.want)
+		}
+		if c := Compass(got); c != tt.compass {
+			t.Errorf("Compass(%.1f) = %s, want %s", got, c, tt.compass)
+		}
+	}
+	if c := Compass(359); c != "N" {
+		t.Errorf("Compass(359) = %s, want N", c)
+	}
+}
+
+func TestWalker(t *testing.T) {
+	w := Walker{DetourFactor: 1.5, Speed: 1.25}
+	if d := w.Distance(100); d != 150 {
+		t.Errorf("Distance(100) = %v, want 150", d)
+	}
+	if s := w.Time(100); s != 120 {
+		t.Errorf("Time(100) = %v, want 120", s)
+	}
+	if r := w.Reach(120); math.Abs(r-100) > 1e-9 {
+		t.Errorf("Reach(120) = %v, want 100", r)
+	}
+}
+
+func TestValidCoordinate(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		want     bool
+	}{
+		{-23.55, -46.63, true},
+		{90, 180, true},
+		{-90, -180, true},
+		{90.1, 0, false},
+		{0, -180.1, false},
+	}
+	for _, tt := range tests {
+		if got := ValidCoordinate(tt.lat, tt.lon); got != tt.want {
+			t.Errorf("ValidCoordinate(%v, %v) = %v, want %v", tt.lat, tt.lon, got, tt.want)
+		}
+	}
+}
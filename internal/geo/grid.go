@@ -0,0 +1,125 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultCellSize is the grid cell size in degrees, about 550 m of latitude
+const DefaultCellSize = 0.005
+
+// Grid is a spatial index that buckets values into fixed-size latitude and
+// longitude cells
+type Grid[T any] struct {
+	cellSize float64
+	cells    map[cell][]entry[T]
+	size     int
+}
+
+// cell identifies a grid cell by its row and column
+type cell struct {
+	row, col int
+}
+
+// entry is a value stored in the grid with its coordinate
+type entry[T any] struct {
+	lat, lon float64
+	value    T
+}
+
+// Neighbor is a value found near a point, with its distance in meters
+type Neighbor[T any] struct {
+	Value     T
+	Latitude  float64
+	Longitude float64
+	Distance  float64
+}
+
+// NewGrid creates an empty grid with the given cell size in degrees
+func NewGrid[T any](cellSize float64) *Grid[T] {
+	return &Grid[T]{
+		cellSize: cellSize,
+		cells:    make(map[cell][]entry[T]),
+	}
+}
+
+// Len returns the number of values in the grid
+func (g *Grid[T]) Len() int {
+	return g.size
+}
+
+// Insert adds a value at the given coordinate
+func (g *Grid[T]) Insert(lat, lon float64, value T) {
+	c := g.cellOf(lat, lon)
+	g.cells[c] = append(g.cells[c], entry[T]{lat: lat, lon: lon, value: value})
+	g.size++
+}
+
+// Within returns the values within radius meters of the point, nearest first.
+// A positive limit caps the number of values returned.
+func (g *Grid[T]) Within(lat, lon, radius float64, limit int) []Neighbor[T] {
+	dLat, dLon := degreeSpan(lat, radius)
+	var found []Neighbor[T]
+	g.scan(lat-dLat, lon-dLon, lat+dLat, lon+dLon, func(e entry[T]) {
+		if d := Distance(lat, lon, e.lat, e.lon); d <= radius {
+			found = append(found, Neighbor[T]{Value: e.value, Latitude: e.lat, Longitude: e.lon, Distance: d})
+		}
+	})
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Distance < found[j].Distance
+	})
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found
+}
+
+// InBox returns the values inside the rectangle. Box queries leave the
+// neighbor distances at zero.
+func (g *Grid[T]) InBox(south, west, north, east float64) []Neighbor[T] {
+	var found []Neighbor[T]
+	g.scan(south, west, north, east, func(e entry[T]) {
+		if e.lat >= south && e.lat <= north && e.lon >= west && e.lon <= east {
+			found = append(found, Neighbor[T]{Value: e.value, Latitude: e.lat, Longitude: e.lon})
+		}
+	})
+	return found
+}
+
+// scan visits every entry in the cells overlapping the rectangle
+func (g *Grid[T]) scan(south, west, north, east float64, visit func(e entry[T])) {
+	from := g.cellOf(south, west)
+	to := g.cellOf(north, east)
+	if (to.row-from.row+1)*(to.col-from.col+1) > len(g.cells) {
+		// The rectangle spans more cells than are populated, walk the populated ones
+		for c, entries := range g.cells {
+			if c.row >= from.row && c.row <= to.row && c.col >= from.col && c.col <= to.col {
+				for _, e := range entries {
+					visit(e)
+				}
+			}
+		}
+		return
+	}
+	for row := from.row; row <= to.row; row++ {
+		for col := from.col; col <= to.col; col++ {
+			for _, e := range g.cells[cell{row: row, col: col}] {
+				visit(e)
+			}
+		}
+	}
+}
+
+// cellOf returns the cell containing the coordinate
+func (g *Grid[T]) cellOf(lat, lon float64) cell {
+	return cellAt(lat, lon, g.cellSize)
+}
+
+// cellAt returns the cell of the given size in degrees containing the coordinate
+func cellAt(lat, lon, cellSize float64) cell {
+	return cell{
+		row: int(math.Floor(lat / cellSize)),
+		col: int(math.Floor(lon / cellSize)),
+	}
+}
@@ -0,0 +1,107 @@
+package geo
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// testPoints scatters points around São Paulo, deterministically
+func testPoints(n int) []Point {
+	r := rand.New(rand.NewSource(1))
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = Point{Latitude: -23.60 + r.Float64()*0.1, Longitude: -46.70 + r.Float64()*0.1}
+	}
+	return points
+}
+
+func TestGridWithin(t *testing.T) {
+	points := testPoints(2000)
+	g := NewGrid[int](DefaultCellSize)
+	for i, p := range points {
+		g.Insert(p.Latitude, p.Longitude, i)
+	}
+	if g.Len() != len(points) {
+		t.Fatalf("Len() = %d, want %d", g.Len(), len(points))
+	}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		radius   float64
+		limit    int
+	}{
+		{"small radius", -23.55, -46.65, 150, 0},
+		{"across cells", -23.55, -46.65, 1200, 0},
+		{"on a cell corner", -23.55, -46.65, 600, 0},
+		{"limited", -23.56, -46.66, 2000, 5},
+		{"outside the points", -23.30, -46.30, 500, 0},
+		{"whole area", -23.55, -46.65, 20000, 0},
+	}
+	for _, tt := range tests {
+		var want []int
+		for i, p := range points {
+			if Distance(tt.lat, tt.lon, p.Latitude, p.Longitude) <= tt.radius {
+				want = append(want, i)
+			}
+		}
+		sort.SliceStable(want, func(a, b int) bool {
+			pa, pb := points[want[a]], points[want[b]]
+			return Distance(tt.lat, tt.lon, pa.Latitude, pa.Longitude) < Distance(tt.lat, tt.lon, pb.Latitude, pb.Longitude)
+		})
+		if tt.limit > 0 && len(want) > tt.limit {
+			want = want[:tt.limit]
+		}
+
+		found := g.Within(tt.lat, tt.lon, tt.radius, tt.limit)
+		if len(found) != len(want) {
+			t.Errorf("%s: found %d points, want %d", tt.name, len(found), len(want))
+			continue
+		}
+		for i, n := range found {
+			p, w := points[n.Value], points[want[i]]
+			if n.Distance != Distance(tt.lat, tt.lon, w.Latitude, w.Longitude) {
+				t.Errorf("%s: neighbor %d is point %d, want %d", tt.name, i, n.Value, want[i])
+			}
+			if n.Latitude != p.Latitude || n.Longitude != p.Longitude || n.Distance > tt.radius {
+				t.Errorf("%s: neighbor %d has position %v, %v at %.1f m", tt.name, i, n.Latitude, n.Longitude, n.Distance)
+			}
+		}
+	}
+}
+
+func TestGridInBox(t *testing.T) {
+	points := testPoints(2000)
+	g := NewGrid[int](DefaultCellSize)
+	for i, p := range points {
+		g.Insert(p.Latitude, p.Longitude, i)
+	}
+
+	tests := []struct {
+		name                     string
+		south, west, north, east float64
+	}{
+		{"inner box", -23.56, -46.66, -23.54, -46.64},
+		{"thin box", -23.551, -46.70, -23.550, -46.60},
+		{"larger than the points", -24, -47, -23, -46},
+		{"empty", -22, -45, -21.9, -44.9},
+	}
+	for _, tt := range tests {
+		want := make(map[int]bool)
+		for i, p := range points {
+			if p.Latitude >= tt.south && p.Latitude <= tt.north && p.Longitude >= tt.west && p.Longitude <= tt.east {
+				want[i] = true
+			}
+		}
+		found := g.InBox(tt.south, tt.west, tt.north, tt.east)
+		if len(found) != len(want) {
+			t.Errorf("%s: found %d points, want %d", tt.name, len(found), len(want))
+		}
+		for _, n := range found {
+			if !want[n.Value] {
+				t.Errorf("%s: point %d is outside the box", tt.name, n.Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package geo
+
+import "math"
+
+// Circle is the area within a radius in meters of a point
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+// Outline returns a closed polygon around a set of circles, as seen from a
+// center: along each of the given number of bearings it reaches the farthest
+// edge of any circle the bearing crosses. The outline is star-shaped around
+// the center, so it also covers the gaps between circles along a bearing.
+func Outline(center Point, circles []Circle, bearings int) []Point {
+	if bearings < 3 {
+		bearings = 3
+	}
+	_, dLon := degreeSpan(center.Latitude, 1)
+	dLat := 1 / metersPerDegreeLat
+
+	// Place the circles on a plane in meters east and north of the center
+	type disc struct{ x, y, r float64 }
+	discs := make([]disc, 0, len(circles))
+	for _, c := range circles {
+		discs = append(discs, disc{
+			x: (c.Center.Longitude - center.Longitude) / dLon,
+			y: (c.Center.Latitude - center.Latitude) / dLat,
+			r: c.Radius,
+		})
+	}
+
+	ring := make([]Point, 0, bearings+1)
+	for i := 0; i < bearings; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(bearings)
+		east, north := math.Sin(angle), math.Cos(angle)
+		reach := 0.0
+		for _, d := range discs {
+			along := d.x*east + d.y*north
+			across := d.x*north - d.y*east
+			if math.Abs(across) > d.r {
+				continue
+			}
+			if far := along + math.Sqrt(d.r*d.r-across*across); far > reach {
+				reach = far
+			}
+		}
+		ring = append(ring, Point{
+			Latitude:  center.Latitude + reach*north*dLat,
+			Longitude: center.Longitude + reach*east*dLon,
+		})
+	}
+	return append(ring, ring[0])
+}
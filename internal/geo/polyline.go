@@ -0,0 +1,129 @@
+package geo
+
+import "math"
+
+// Point is a geographic coordinate
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Polyline is a path through ordered points, measured in meters along it
+type Polyline struct {
+	points     []Point
+	cumulative []float64 // Distance from the start to each point
+}
+
+// Snap is the position on a polyline nearest to a point
+type Snap struct {
+	Along   float64 // Distance from the start of the polyline, in meters
+	Offset  float64 // Distance from the point to the polyline, in meters
+	Segment int     // Index of the segment the position falls on
+}
+
+// NewPolyline creates a polyline through the points, skipping repeated points
+func NewPolyline(points []Point) *Polyline {
+	p := &Polyline{}
+	for _, pt := range points {
+		n := len(p.points)
+		if n > 0 && p.points[n-1] == pt {
+			continue
+		}
+		along := 0.0
+		if n > 0 {
+			prev := p.points[n-1]
+			along = p.cumulative[n-1] + Distance(prev.Latitude, prev.Longitude, pt.Latitude, pt.Longitude)
+		}
+		p.points = append(p.points, pt)
+		p.cumulative = append(p.cumulative, along)
+	}
+	return p
+}
+
+// Len returns the number of points of the polyline
+func (p *Polyline) Len() int {
+	return len(p.points)
+}
+
+// Length returns the length of the polyline in meters
+func (p *Polyline) Length() float64 {
+	if len(p.cumulative) == 0 {
+		return 0
+	}
+	return p.cumulative[len(p.cumulative)-1]
+}
+
+// Reverse returns the polyline traversed from its end to its start
+func (p *Polyline) Reverse() *Polyline {
+	points := make([]Point, len(p.points))
+	for i, pt := range p.points {
+		points[len(points)-1-i] = pt
+	}
+	return NewPolyline(points)
+}
+
+// Project returns the position on the polyline nearest to the point
+func (p *Polyline) Project(pt Point) Snap {
+	return p.ProjectFrom(pt, 0)
+}
+
+// ProjectFrom returns the position nearest to the point among those at least
+// from meters along the polyline, so ordered points can be snapped in order
+// on routes that pass the same place twice
+func (p *Polyline) ProjectFrom(pt Point, from float64) Snap {
+	if len(p.points) == 0 {
+		return Snap{}
+	}
+	if len(p.points) == 1 {
+		only := p.points[0]
+		return Snap{Offset: Distance(pt.Latitude, pt.Longitude, only.Latitude, only.Longitude)}
+	}
+
+	best := Snap{Offset: math.Inf(1)}
+	for i := 0; i+1 < len(p.points); i++ {
+		start, end := p.cumulative[i], p.cumulative[i+1]
+		if end < from {
+			continue
+		}
+		t := segmentFraction(p.points[i], p.points[i+1], pt)
+		if along := start + t*(end-start); along < from && end > start {
+			t = (from - start) / (end - start)
+		}
+		at := interpolate(p.points[i], p.points[i+1], t)
+		offset := Distance(pt.Latitude, pt.Longitude, at.Latitude, at.Longitude)
+		if offset < best.Offset {
+			best = Snap{Along: start + t*(end-start), Offset: offset, Segment: i}
+		}
+	}
+	if math.IsInf(best.Offset, 1) {
+		// Nothing lies beyond from, snap to the end
+		last := p.points[len(p.points)-1]
+		return Snap{
+			Along:   p.Length(),
+			Offset:  Distance(pt.Latitude, pt.Longitude, last.Latitude, last.Longitude),
+			Segment: len(p.points) - 2,
+		}
+	}
+	return best
+}
+
+// segmentFraction returns the fraction along the segment from a to b of the
+// point nearest to pt, using a local planar approximation
+func segmentFraction(a, b, pt Point) float64 {
+	cos := math.Cos(radians(a.Latitude))
+	bx, by := (b.Longitude-a.Longitude)*cos, b.Latitude-a.Latitude
+	px, py := (pt.Longitude-a.Longitude)*cos, pt.Latitude-a.Latitude
+	lengthSquared := bx*bx + by*by
+	if lengthSquared == 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (px*bx+py*by)/lengthSquared))
+}
+
+// interpolate returns the point at fraction t of the segment from a to b
+func interpolate(a, b Point, t float64) Point {
+	return Point{
+		Latitude:  a.Latitude + t*(b.Latitude-a.Latitude),
+		Longitude: a.Longitude + t*(b.Longitude-a.Longitude),
+	}
+}
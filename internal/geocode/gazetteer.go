@@ -0,0 +1,436 @@
+package geocode
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Precisions of a geocoded location
+const (
+	PrecisionCoordinate   = "coordinate"   // The query was a coordinate
+	PrecisionExact        = "exact"        // A stop lies at the requested number
+	PrecisionInterpolated = "interpolated" // Between the stops at the nearest lower and higher numbers
+	PrecisionNearest      = "nearest"      // At the stop with the nearest number, outside the known range
+	PrecisionStreet       = "street"       // On the street, without a number
+	PrecisionPlace        = "place"        // At a stop matched by name
+)
+
+// Kinds of a geocoded location
+const (
+	KindStreet = "street"
+	KindStop   = "stop"
+	KindPoint  = "point" // A coordinate given as the query
+)
+
+const (
+	// clusterRadius separates streets of the same name in different
+	// neighborhoods: a stop joins a street if it lies this close to one of
+	// its stops
+	clusterRadius = 1500.0
+	// minScore is the lowest name similarity returned by Geocode
+	minScore = 0.5
+	// duplicateRadius merges stops of the same name matched by Geocode
+	duplicateRadius = 300.0
+)
+
+// coordinatePattern matches queries that are already a coordinate
+var coordinatePattern = regexp.MustCompile(`^\s*(-?\d{1,3}(?:\.\d+)?)\s*[,; ]\s*(-?\d{1,3}(?:\.\d+)?)\s*$`)
+
+// point is a stop on a street, with the building number of its address
+type point struct {
+	number int
+	stop   types.Stop
+}
+
+// street is a stretch of a named street, built from the stops on it
+type street struct {
+	display string // Name as written in the first address seen
+	name    name
+	points  []point
+}
+
+// place is a stop matched by its name
+type place struct {
+	name name
+	stop types.Stop
+}
+
+// ref locates a stop on a street of the gazetteer
+type ref struct {
+	street int
+	point  int
+}
+
+// Gazetteer is an offline geocoder over the streets and stop names of the
+// stop catalog. It is immutable once built.
+type Gazetteer struct {
+	streets     []*street
+	streetWords map[string][]int // Streets containing each word
+	places      []place
+	placeWords  map[string][]int // Places containing each word
+	index       *geo.Grid[ref]
+}
+
+// Result is a location matched by Geocode
+type Result struct {
+	Kind      string     // KindStreet, KindStop or KindPoint
+	Name      string     // Street or stop name
+	Number    int        // Building number located, zero when none
+	Precision string     // How the location was derived
+	Latitude  float64    // Latitude
+	Longitude float64    // Longitude
+	Score     float64    // Similarity of the name to the query, from 0 to 1
+	Stop      types.Stop // The stop nearest to the location on the matched street, or the matched stop
+}
+
+// Location is a point described by Reverse
+type Location struct {
+	Street       string     // Nearest street
+	Number       int        // Estimated building number, zero when unknown
+	Precision    string     // PrecisionInterpolated, PrecisionNearest or PrecisionStreet
+	Stop         types.Stop // Nearest stop with a known address
+	StopDistance float64    // Distance to that stop, in meters
+	CrossStreets []string   // Other streets nearby, nearest first
+}
+
+// New builds a gazetteer from the stops, sorted by code so the result does
+// not depend on their order
+func New(stops []types.Stop) *Gazetteer {
+	stops = append([]types.Stop(nil), stops...)
+	sort.Slice(stops, func(i, j int) bool { return stops[i].Code < stops[j].Code })
+
+	g := &Gazetteer{
+		streetWords: make(map[string][]int),
+		placeWords:  make(map[string][]int),
+		index:       geo.NewGrid[ref](geo.DefaultCellSize),
+	}
+	byKey := make(map[string][]int)
+	for _, stop := range stops {
+		for _, a := range parseAddresses(stop.Address) {
+			n := parseName(a.street)
+			i := g.streetFor(byKey, n, a.street, stop)
+			g.streets[i].points = append(g.streets[i].points, point{number: a.number, stop: stop})
+			g.index.Insert(stop.Latitude, stop.Longitude, ref{street: i, point: len(g.streets[i].points) - 1})
+		}
+		if n := parsePlaceName(stop.Name); len(n.words) > 0 {
+			for _, word := range unique(n.words) {
+				g.placeWords[word] = append(g.placeWords[word], len(g.places))
+			}
+			g.places = append(g.places, place{name: n, stop: stop})
+		}
+	}
+	return g
+}
+
+// streetFor returns the street of that name the stop lies on, adding one if
+// the stop is far from every street of the name
+func (g *Gazetteer) streetFor(byKey map[string][]int, n name, display string, stop types.Stop) int {
+	key := n.key()
+	for _, i := range byKey[key] {
+		for _, p := range g.streets[i].points {
+			if geo.Distance(p.stop.Latitude, p.stop.Longitude, stop.Latitude, stop.Longitude) <= clusterRadius {
+				return i
+			}
+		}
+	}
+	i := len(g.streets)
+	g.streets = append(g.streets, &street{display: strings.Join(strings.Fields(display), " "), name: n})
+	byKey[key] = append(byKey[key], i)
+	for _, word := range unique(n.words) {
+		g.streetWords[word] = append(g.streetWords[word], i)
+	}
+	return i
+}
+
+// Streets returns the number of streets in the gazetteer
+func (g *Gazetteer) Streets() int {
+	return len(g.streets)
+}
+
+// Geocode locates a free-text place: a coordinate, a street with an optional
+// number such as "Av. Paulista, 1578", or a stop name. Results are sorted by
+// score, best first.
+func (g *Gazetteer) Geocode(query string, limit int) []Result {
+	if m := coordinatePattern.FindStringSubmatch(query); m != nil {
+		lat, _ := strconv.ParseFloat(m[1], 64)
+		lon, _ := strconv.ParseFloat(m[2], 64)
+		if geo.ValidCoordinate(lat, lon) {
+			return []Result{{Kind: KindPoint, Name: strings.TrimSpace(query), Precision: PrecisionCoordinate, Latitude: lat, Longitude: lon, Score: 1}}
+		}
+	}
+
+	a, ok := parseAddress(query)
+	if !ok {
+		return nil
+	}
+	q := parseName(a.street)
+
+	var results []Result
+	for _, i := range candidates(g.streetWords, q.words) {
+		s := g.streets[i]
+		if score := similarity(q, s.name); score >= minScore {
+			results = append(results, s.locate(a.number, score))
+		}
+	}
+	if a.number == 0 {
+		// Stop names such as "Terminal Parque Dom Pedro II" carry no number
+		q := parsePlaceName(a.street)
+		for _, i := range candidates(g.placeWords, q.words) {
+			p := g.places[i]
+			if score := similarity(q, p.name); score >= minScore && !nearDuplicate(results, p) {
+				results = append(results, Result{
+					Kind:      KindStop,
+					Name:      p.stop.Name,
+					Precision: PrecisionPlace,
+					Latitude:  p.stop.Latitude,
+					Longitude: p.stop.Longitude,
+					Score:     score,
+					Stop:      p.stop,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Kind == KindStreet && results[j].Kind != KindStreet
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Reverse describes the point by the nearest street with a known stop within
+// radius meters, estimating the building number from the numbered stops of
+// that street
+func (g *Gazetteer) Reverse(lat, lon, radius float64) (Location, bool) {
+	near := g.index.Within(lat, lon, radius, 0)
+	if len(near) == 0 {
+		return Location{}, false
+	}
+	nearest := near[0]
+	s := g.streets[nearest.Value.street]
+	loc := Location{
+		Street:       s.display,
+		Precision:    PrecisionStreet,
+		Stop:         s.points[nearest.Value.point].stop,
+		StopDistance: nearest.Distance,
+	}
+	loc.Number, loc.Precision = s.numberAt(lat, lon)
+
+	seen := map[string]bool{s.name.key(): true}
+	for _, n := range near[1:] {
+		other := g.streets[n.Value.street]
+		if key := other.name.key(); !seen[key] {
+			seen[key] = true
+			loc.CrossStreets = append(loc.CrossStreets, other.display)
+		}
+	}
+	return loc, true
+}
+
+// locate returns the location of a number on the street, or of the street
+// itself when number is zero
+func (s *street) locate(number int, score float64) Result {
+	r := Result{Kind: KindStreet, Name: s.display, Score: score, Precision: PrecisionStreet}
+	numbered := s.numbered()
+
+	switch {
+	case number == 0 || len(numbered) == 0:
+		// The stop nearest to the middle of the street, which stays on it
+		// even when the street curves
+		var lat, lon float64
+		for _, p := range s.points {
+			lat += p.stop.Latitude
+			lon += p.stop.Longitude
+		}
+		lat, lon = lat/float64(len(s.points)), lon/float64(len(s.points))
+		best := s.points[0]
+		for _, p := range s.points[1:] {
+			if geo.Distance(lat, lon, p.stop.Latitude, p.stop.Longitude) < geo.Distance(lat, lon, best.stop.Latitude, best.stop.Longitude) {
+				best = p
+			}
+		}
+		r.Latitude, r.Longitude, r.Stop = best.stop.Latitude, best.stop.Longitude, best.stop
+		return r
+
+	case number <= numbered[0].number || number >= numbered[len(numbered)-1].number:
+		end := numbered[0]
+		if number >= numbered[len(numbered)-1].number {
+			end = numbered[len(numbered)-1]
+		}
+		r.Number, r.Precision = number, PrecisionNearest
+		if end.number == number {
+			r.Precision = PrecisionExact
+		}
+		r.Latitude, r.Longitude, r.Stop = end.stop.Latitude, end.stop.Longitude, end.stop
+		return r
+	}
+
+	hi := sort.Search(len(numbered), func(i int) bool { return numbered[i].number >= number })
+	lo := numbered[hi-1]
+	r.Number = number
+	if numbered[hi].number == number {
+		p := numbered[hi]
+		r.Precision = PrecisionExact
+		r.Latitude, r.Longitude, r.Stop = p.stop.Latitude, p.stop.Longitude, p.stop
+		return r
+	}
+	t := float64(number-lo.number) / float64(numbered[hi].number-lo.number)
+	r.Precision = PrecisionInterpolated
+	r.Latitude = lo.stop.Latitude + t*(numbered[hi].stop.Latitude-lo.stop.Latitude)
+	r.Longitude = lo.stop.Longitude + t*(numbered[hi].stop.Longitude-lo.stop.Longitude)
+	r.Stop = lo.stop
+	if t > 0.5 {
+		r.Stop = numbered[hi].stop
+	}
+	return r
+}
+
+// numberAt estimates the building number at a point from the segment
+// between consecutive numbered stops passing nearest to it
+func (s *street) numberAt(lat, lon float64) (int, string) {
+	numbered := s.numbered()
+	pt := geo.Point{Latitude: lat, Longitude: lon}
+	switch len(numbered) {
+	case 0:
+		return 0, PrecisionStreet
+	case 1:
+		return numbered[0].number, PrecisionNearest
+	}
+
+	best, bestOffset := 0, math.Inf(1)
+	for i := 1; i < len(numbered); i++ {
+		lo, hi := numbered[i-1], numbered[i]
+		segment := geo.NewPolyline([]geo.Point{
+			{Latitude: lo.stop.Latitude, Longitude: lo.stop.Longitude},
+			{Latitude: hi.stop.Latitude, Longitude: hi.stop.Longitude},
+		})
+		snap := segment.Project(pt)
+		if snap.Offset >= bestOffset {
+			continue
+		}
+		bestOffset = snap.Offset
+		t := 0.0
+		if segment.Length() > 0 {
+			t = snap.Along / segment.Length()
+		}
+		best = lo.number + int(math.Round(t*float64(hi.number-lo.number)))
+	}
+	return best, PrecisionInterpolated
+}
+
+// numbered returns the numbered stops of the street sorted by number, one per
+// number
+func (s *street) numbered() []point {
+	var numbered []point
+	for _, p := range s.points {
+		if p.number > 0 {
+			numbered = append(numbered, p)
+		}
+	}
+	sort.SliceStable(numbered, func(i, j int) bool { return numbered[i].number < numbered[j].number })
+	unique := numbered[:0]
+	for _, p := range numbered {
+		if len(unique) == 0 || unique[len(unique)-1].number != p.number {
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
+
+// candidates returns the entries of an inverted index sharing a word with the
+// query, or starting with its last word, which may still be being typed
+func candidates(index map[string][]int, words []string) []int {
+	seen := make(map[int]bool)
+	var found []int
+	add := func(entries []int) {
+		for _, i := range entries {
+			if !seen[i] {
+				seen[i] = true
+				found = append(found, i)
+			}
+		}
+	}
+	for _, word := range words {
+		add(index[word])
+	}
+	if len(words) > 0 {
+		if last := words[len(words)-1]; len(last) >= 3 {
+			for word, entries := range index {
+				if word != last && strings.HasPrefix(word, last) {
+					add(entries)
+				}
+			}
+		}
+	}
+	sort.Ints(found)
+	return found
+}
+
+// similarity scores how well a name matches the query, from 0 to 1: the
+// share of words in common, adjusted when both give a street type
+func similarity(query, n name) float64 {
+	if len(query.words) == 0 || len(n.words) == 0 {
+		return 0
+	}
+	matched := 0
+	for i, word := range query.words {
+		last := i == len(query.words)-1
+		for _, other := range n.words {
+			if word == other || (last && len(word) >= 3 && strings.HasPrefix(other, word)) {
+				matched++
+				break
+			}
+		}
+	}
+	score := 2 * float64(matched) / float64(len(query.words)+len(n.words))
+	if query.kind != "" && n.kind != "" {
+		if query.kind == n.kind {
+			score += 0.1
+		} else {
+			score -= 0.2
+		}
+	}
+	return math.Max(0, math.Min(1, score))
+}
+
+// nearDuplicate reports whether the place is already among the results: as a
+// street of the same name, or as a stop of the same name close to it
+func nearDuplicate(results []Result, p place) bool {
+	for _, r := range results {
+		n := parseName(r.Name)
+		if r.Kind == KindStop {
+			n = parsePlaceName(r.Name)
+		}
+		if n.key() != p.name.key() {
+			continue
+		}
+		if r.Kind == KindStreet || geo.Distance(r.Latitude, r.Longitude, p.stop.Latitude, p.stop.Longitude) <= duplicateRadius {
+			return true
+		}
+	}
+	return false
+}
+
+// unique returns the distinct words in order
+func unique(words []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			out = append(out, word)
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package geocode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// testStops lie along Av. Paulista, Av. Prefeito Passos and R. Refinaria,
+// plus a terminal known only by its name
+var testStops = []types.Stop{
+	{Code: 1, Name: "Paulista A", Address: "AV PAULISTA, 1000", Latitude: -23.5700, Longitude: -46.6500},
+	{Code: 2, Name: "Paulista B", Address: "AV PAULISTA, 2000", Latitude: -23.5650, Longitude: -46.6550},
+	{Code: 3, Name: "Prefeito Passos", Address: "AV PREFEITO PASSOS, 100", Latitude: -23.5900, Longitude: -46.6300},
+	{Code: 4, Name: "Refinaria", Address: "R. REFINARIA, 20 REF.: POSTO", Latitude: -23.6000, Longitude: -46.6200},
+	{Code: 5, Name: "Terminal Parque Dom Pedro II", Address: "", Latitude: -23.5480, Longitude: -46.6290},
+}
+
+func TestGazetteerGeocode(t *testing.T) {
+	g := New(testStops)
+	tests := []struct {
+		query     string
+		kind      string
+		name      string
+		number    int
+		precision string
+		stop      int
+		lat, lon  float64
+	}{
+		{"Av. Paulista, 1000", KindStreet, "AV PAULISTA", 1000, PrecisionExact, 1, -23.5700, -46.6500},
+		{"av paulista 1500", KindStreet, "AV PAULISTA", 1500, PrecisionInterpolated, 1, -23.5675, -46.6525},
+		{"Avenida Paulista, 3000", KindStreet, "AV PAULISTA", 3000, PrecisionNearest, 2, -23.5650, -46.6550},
+		{"Av. Pref. Passos, 100", KindStreet, "AV PREFEITO PASSOS", 100, PrecisionExact, 3, -23.5900, -46.6300},
+		{"Rua Refinaria", KindStreet, "R. REFINARIA", 0, PrecisionStreet, 4, -23.6000, -46.6200},
+		{"Term. Pq. D. Pedro II", KindStop, "Terminal Parque Dom Pedro II", 0, PrecisionPlace, 5, -23.5480, -46.6290},
+		{"-23.55, -46.63", KindPoint, "-23.55, -46.63", 0, PrecisionCoordinate, 0, -23.55, -46.63},
+	}
+	for _, tt := range tests {
+		results := g.Geocode(tt.query, 1)
+		if len(results) != 1 {
+			t.Errorf("Geocode(%q) returned %d results, want 1", tt.query, len(results))
+			continue
+		}
+		r := results[0]
+		if r.Kind != tt.kind || r.Name != tt.name || r.Number != tt.number || r.Precision != tt.precision || r.Stop.Code != tt.stop {
+			t.Errorf("Geocode(%q) = %s %q %d %s stop %d, want %s %q %d %s stop %d",
+				tt.query, r.Kind, r.Name, r.Number, r.Precision, r.Stop.Code, tt.kind, tt.name, tt.number, tt.precision, tt.stop)
+		}
+		if math.Abs(r.Latitude-tt.lat) > 1e-9 || math.Abs(r.Longitude-tt.lon) > 1e-9 {
+			t.Errorf("Geocode(%q) at %f, %f, want %f, %f", tt.query, r.Latitude, r.Longitude, tt.lat, tt.lon)
+		}
+	}
+
+	for _, query := range []string{"Rua Inexistente", "", "de da"} {
+		if results := g.Geocode(query, 1); len(results) != 0 {
+			t.Errorf("Geocode(%q) = %+v, want no results", query, results)
+		}
+	}
+}
+
+func TestGazetteerReverse(t *testing.T) {
+	g := New(testStops)
+	loc, ok := g.Reverse(-23.5675, -46.6525, 1000)
+	if !ok {
+		t.Fatal("Reverse found no street")
+	}
+	if loc.Street != "AV PAULISTA" || loc.Precision != PrecisionInterpolated || loc.Number < 1450 || loc.Number > 1550 {
+		t.Errorf("Reverse = %s %d %s, want AV PAULISTA near 1500 interpolated", loc.Street, loc.Number, loc.Precision)
+	}
+	if _, ok := g.Reverse(-23.0, -46.0, 500); ok {
+		t.Error("Reverse found a street far from every stop")
+	}
+}
@@ -0,0 +1,190 @@
+package geocode
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// accentFolds maps accented letters to their plain forms
+var accentFolds = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "º", "", "ª", "",
+)
+
+// streetTypes expands the abbreviations of street types to their full names
+var streetTypes = map[string]string{
+	"r": "rua", "rua": "rua",
+	"av": "avenida", "avda": "avenida", "avenida": "avenida",
+	"pc": "praca", "pca": "praca", "praca": "praca",
+	"al": "alameda", "alameda": "alameda",
+	"est": "estrada", "estr": "estrada", "estrada": "estrada",
+	"rod": "rodovia", "rodovia": "rodovia",
+	"tv": "travessa", "trav": "travessa", "travessa": "travessa",
+	"lg": "largo", "lgo": "largo", "largo": "largo",
+	"vd": "viaduto", "viad": "viaduto", "viaduto": "viaduto",
+	"pte": "ponte", "ponte": "ponte",
+	"vl": "viela", "viela": "viela",
+	"pq": "parque", "pque": "parque", "parque": "parque",
+	"ptg": "passagem", "pass": "passagem", "passagem": "passagem",
+}
+
+// placeTypes expands the abbreviations that name a kind of place in stop and
+// terminal names but a street type in addresses, e.g. "Est. da Luz" is a
+// station while "Est. do M'Boi Mirim" is a road
+var placeTypes = map[string]string{
+	"est": "estacao", "estac": "estacao", "vl": "vila",
+}
+
+// titles expands the abbreviations of titles and place words common in
+// street, stop and terminal names
+var titles = map[string]string{
+	"dr": "doutor", "dra": "doutora", "prof": "professor", "profa": "professora",
+	"eng": "engenheiro", "gen": "general", "gal": "general", "cel": "coronel",
+	"cap": "capitao", "ten": "tenente", "mal": "marechal", "alm": "almirante",
+	"brig": "brigadeiro", "pres": "presidente", "sen": "senador", "dep": "deputado",
+	"ver": "vereador", "gov": "governador", "min": "ministro", "cons": "conselheiro",
+	"sta": "santa", "sto": "santo", "s": "sao", "pe": "padre", "d": "dom",
+	"n": "nossa", "sra": "senhora", "pref": "prefeito",
+	"jd": "jardim", "jdm": "jardim", "vla": "vila", "term": "terminal",
+	"cid": "cidade", "conj": "conjunto", "cj": "conjunto", "hab": "habitacional",
+	"res": "residencial", "hosp": "hospital", "univ": "universidade", "shop": "shopping",
+}
+
+// reference matches the start of the reference some addresses carry, e.g.
+// "REF.: ..." or "REF: ...", but not words such as "PREFEITO" or "REFINARIA"
+var reference = regexp.MustCompile(`(?i)\bref\s*[.:]`)
+
+// stopWords are connecting words ignored when matching names
+var stopWords = map[string]bool{
+	"de": true, "da": true, "do": true, "das": true, "dos": true, "e": true,
+}
+
+// Tokenize folds text to lowercase ASCII and splits it into words and numbers
+func Tokenize(text string) []string {
+	text = accentFolds.Replace(strings.ToLower(text))
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// name is a street or place name split into its type and its significant words
+type name struct {
+	kind  string   // Street type, e.g. "rua", empty when not given
+	words []string // Significant words, abbreviations expanded
+}
+
+// parseName normalizes a street name, as given in an address
+func parseName(text string) name {
+	return normalizeName(text, true)
+}
+
+// parsePlaceName normalizes the name of a stop or terminal
+func parsePlaceName(text string) name {
+	return normalizeName(text, false)
+}
+
+// PlaceWords folds the name of a stop or terminal to lowercase ASCII words,
+// expanding abbreviations and dropping connecting words
+func PlaceWords(text string) []string {
+	n := parsePlaceName(text)
+	if n.kind != "" {
+		return append([]string{n.kind}, n.words...)
+	}
+	return n.words
+}
+
+// normalizeName splits a name into its type and significant words. The
+// abbreviations of placeTypes are street types in addresses only.
+func normalizeName(text string, street bool) name {
+	var n name
+	for i, token := range Tokenize(text) {
+		if full, ok := placeTypes[token]; ok && !street {
+			token = full
+		} else if kind, ok := streetTypes[token]; ok {
+			if i == 0 {
+				n.kind = kind
+				continue
+			}
+			token = kind
+		} else if full, ok := titles[token]; ok {
+			token = full
+		}
+		if stopWords[token] {
+			continue
+		}
+		n.words = append(n.words, token)
+	}
+	return n
+}
+
+// key returns the identity of a name: its type and words
+func (n name) key() string {
+	return n.kind + ":" + strings.Join(n.words, " ")
+}
+
+// address is a street name with an optional building number
+type address struct {
+	street string
+	number int
+}
+
+// parseAddresses splits a stop address such as "R ARMINDA/ R BALTHAZAR DA
+// VEIGA" or "AV PAULISTA, 1578" into the streets it names
+func parseAddresses(text string) []address {
+	// Drop the references some addresses carry, e.g. "REF.: ..."
+	if loc := reference.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+	var addresses []address
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == '/' || r == ';' || r == '(' }) {
+		if a, ok := parseAddress(part); ok {
+			addresses = append(addresses, a)
+		}
+	}
+	return addresses
+}
+
+// parseAddress splits a single address into its street and number, taking
+// the number after a comma or at the end of the text
+func parseAddress(text string) (address, bool) {
+	street, rest, _ := strings.Cut(text, ",")
+	a := address{street: strings.TrimSpace(street)}
+	for _, token := range strings.Fields(rest) {
+		if n, ok := buildingNumber(token); ok {
+			a.number = n
+			break
+		}
+	}
+	if a.number == 0 {
+		fields := strings.Fields(a.street)
+		if len(fields) >= 2 {
+			if n, ok := buildingNumber(fields[len(fields)-1]); ok {
+				a.number = n
+				a.street = strings.Join(fields[:len(fields)-1], " ")
+			}
+		}
+	}
+	if len(parseName(a.street).words) == 0 {
+		return address{}, false
+	}
+	return a, true
+}
+
+// buildingNumber parses a building number, ignoring trailing punctuation and
+// the "nº" prefix
+func buildingNumber(token string) (int, bool) {
+	token = strings.TrimPrefix(strings.ToLower(token), "nº")
+	token = strings.TrimPrefix(token, "n.")
+	token = strings.Trim(token, ".,;-")
+	n, err := strconv.Atoi(token)
+	if err != nil || n <= 0 || n > 99999 {
+		return 0, false
+	}
+	return n, true
+}
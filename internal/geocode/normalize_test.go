@@ -0,0 +1,90 @@
+package geocode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAddresses(t *testing.T) {
+	tests := []struct {
+		text string
+		want []address
+	}{
+		{"AV PAULISTA, 1578", []address{{street: "AV PAULISTA", number: 1578}}},
+		{"R ARMINDA/ R BALTHAZAR DA VEIGA", []address{{street: "R ARMINDA"}, {street: "R BALTHAZAR DA VEIGA"}}},
+		{"AV PREFEITO PASSOS, 100", []address{{street: "AV PREFEITO PASSOS", number: 100}}},
+		{"R. REFINARIA, 20", []address{{street: "R. REFINARIA", number: 20}}},
+		{"R DA CONSOLACAO, 2000 REF.: PROX. MACKENZIE", []address{{street: "R DA CONSOLACAO", number: 2000}}},
+		{"AV REBOUCAS 300 ref: hospital", []address{{street: "AV REBOUCAS", number: 300}}},
+		{"R AUGUSTA, Nº 50; AL SANTOS", []address{{street: "R AUGUSTA", number: 50}, {street: "AL SANTOS"}}},
+		{"AV DR ARNALDO (REF. HC)", []address{{street: "AV DR ARNALDO"}}},
+		{"REF.: PRACA", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := parseAddresses(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAddresses(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		text string
+		want name
+	}{
+		{"R. Dr. Arnaldo", name{kind: "rua", words: []string{"doutor", "arnaldo"}}},
+		{"AV PREF PASSOS", name{kind: "avenida", words: []string{"prefeito", "passos"}}},
+		{"Av. Prefeito Passos", name{kind: "avenida", words: []string{"prefeito", "passos"}}},
+		{"Pça. da Sé", name{kind: "praca", words: []string{"se"}}},
+		{"Term. Pq. D. Pedro II", name{words: []string{"terminal", "parque", "dom", "pedro", "ii"}}},
+		{"Jd. São Luís", name{words: []string{"jardim", "sao", "luis"}}},
+		{"EST DO M BOI MIRIM", name{kind: "estrada", words: []string{"m", "boi", "mirim"}}},
+		{"R. Vl. Madalena", name{kind: "rua", words: []string{"viela", "madalena"}}},
+		{"de da do", name{}},
+	}
+	for _, tt := range tests {
+		if got := parseName(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseName(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPlaceWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Est. da Luz", []string{"estacao", "luz"}},
+		{"Term. Vl. Mariana", []string{"terminal", "vila", "mariana"}},
+		{"Av. Paulista", []string{"avenida", "paulista"}},
+		{"Pq. D. Pedro II", []string{"parque", "dom", "pedro", "ii"}},
+		{"Hosp. N. Sra. de Fátima", []string{"hospital", "nossa", "senhora", "fatima"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := PlaceWords(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PlaceWords(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBuildingNumber(t *testing.T) {
+	tests := []struct {
+		token string
+		want  int
+		ok    bool
+	}{
+		{"1578", 1578, true},
+		{"nº50", 50, true},
+		{"N.12,", 12, true},
+		{"0", 0, false},
+		{"100000", 0, false},
+		{"A", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := buildingNumber(tt.token); got != tt.want || ok != tt.ok {
+			t.Errorf("buildingNumber(%q) = %d, %v, want %d, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
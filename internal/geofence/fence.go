@@ -0,0 +1,175 @@
+package geofence
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Shapes of a fence
+const (
+	Circle  = "circle"
+	Polygon = "polygon"
+)
+
+const (
+	// DefaultHysteresis is the distance a vehicle must cross past the
+	// boundary before it counts as having entered or left, in meters
+	DefaultHysteresis = 25.0
+	minRadius         = 20.0
+	maxRadius         = 20000.0
+	maxPolygonPoints  = 500
+	maxHysteresis     = 500.0
+)
+
+// Fence is a watched area and the vehicles it applies to. A fence with no
+// line or vehicle filter watches every vehicle.
+type Fence struct {
+	ID         string
+	Name       string
+	Shape      string             // Circle or Polygon
+	Center     types.Coordinate   // Center of a circle
+	Radius     float64            // Radius of a circle, in meters
+	Polygon    []types.Coordinate // Vertices of a polygon, in order
+	LineCode   int                // Only vehicles of this line code
+	LinePrefix string             // Only vehicles of lines whose identifier starts with this, e.g. 6450
+	VehicleID  int                // Only this vehicle
+	Hysteresis float64            // Distance past the boundary an event needs, in meters
+	Webhook    string             // Local URL that events are posted to
+	CreatedAt  time.Time
+}
+
+// Validate checks the geometry, filters and webhook of the fence
+func (f Fence) Validate() error {
+	switch f.Shape {
+	case Circle:
+		if !geo.ValidCoordinate(f.Center.Latitude, f.Center.Longitude) {
+			return errors.New("circle center is not a valid coordinate")
+		}
+		if f.Radius < minRadius || f.Radius > maxRadius {
+			return fmt.Errorf("circle radius must be between %g and %g meters", minRadius, maxRadius)
+		}
+	case Polygon:
+		if len(f.Polygon) < 3 || len(f.Polygon) > maxPolygonPoints {
+			return fmt.Errorf("polygon must have between 3 and %d points", maxPolygonPoints)
+		}
+		for _, pt := range f.Polygon {
+			if !geo.ValidCoordinate(pt.Latitude, pt.Longitude) {
+				return errors.New("polygon has an invalid coordinate")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown shape %q", f.Shape)
+	}
+	if f.Hysteresis < 0 || f.Hysteresis > maxHysteresis {
+		return fmt.Errorf("hysteresis must be between 0 and %g meters", maxHysteresis)
+	}
+	if f.Webhook != "" {
+		return ValidateWebhook(f.Webhook)
+	}
+	return nil
+}
+
+// ValidateWebhook checks that a webhook URL is http or https on this machine,
+// so fences cannot be used to make the server post to other hosts
+func ValidateWebhook(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook %q is not an http or https URL", rawURL)
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("webhook %q must point to localhost", rawURL)
+}
+
+// Matches reports whether the fence applies to a vehicle of a line
+func (f Fence) Matches(line types.VehicleLine, vehicle types.Vehicle) bool {
+	if f.LineCode != 0 && line.Code != f.LineCode {
+		return false
+	}
+	if f.LinePrefix != "" && !strings.HasPrefix(strings.ToUpper(line.Identifier), strings.ToUpper(f.LinePrefix)) {
+		return false
+	}
+	return f.VehicleID == 0 || vehicle.ID == f.VehicleID
+}
+
+// Bounds returns the rectangle enclosing the fence
+func (f Fence) Bounds() types.BoundingBox {
+	if f.Shape == Circle {
+		dLat := f.Radius / (math.Pi * geo.EarthRadius / 180)
+		dLon := dLat / math.Cos(f.Center.Latitude*math.Pi/180)
+		return types.BoundingBox{
+			South: f.Center.Latitude - dLat, West: f.Center.Longitude - dLon,
+			North: f.Center.Latitude + dLat, East: f.Center.Longitude + dLon,
+		}
+	}
+	box := types.BoundingBox{South: 90, West: 180, North: -90, East: -180}
+	for _, pt := range f.Polygon {
+		box.South = math.Min(box.South, pt.Latitude)
+		box.West = math.Min(box.West, pt.Longitude)
+		box.North = math.Max(box.North, pt.Latitude)
+		box.East = math.Max(box.East, pt.Longitude)
+	}
+	return box
+}
+
+// hysteresis returns the band around the boundary in which a vehicle keeps
+// its previous state, narrowed for small circles
+func (f Fence) hysteresis() float64 {
+	if f.Shape == Circle {
+		return math.Min(f.Hysteresis, f.Radius/2)
+	}
+	return f.Hysteresis
+}
+
+// signedDistance returns the distance from a point to the boundary of the
+// fence in meters, negative inside the fence
+func (f Fence) signedDistance(lat, lon float64) float64 {
+	if f.Shape == Circle {
+		return geo.Distance(f.Center.Latitude, f.Center.Longitude, lat, lon) - f.Radius
+	}
+
+	// Far from the polygon the exact distance does not matter
+	box := f.Bounds()
+	dLat := 1000 / (math.Pi * geo.EarthRadius / 180)
+	dLon := dLat / math.Cos(lat*math.Pi/180)
+	if lat < box.South-dLat || lat > box.North+dLat || lon < box.West-dLon || lon > box.East+dLon {
+		return math.Inf(1)
+	}
+
+	ring := make([]geo.Point, 0, len(f.Polygon)+1)
+	for _, pt := range f.Polygon {
+		ring = append(ring, geo.Point{Latitude: pt.Latitude, Longitude: pt.Longitude})
+	}
+	ring = append(ring, ring[0])
+	edge := geo.NewPolyline(ring).Project(geo.Point{Latitude: lat, Longitude: lon}).Offset
+	if f.contains(lat, lon) {
+		return -edge
+	}
+	return edge
+}
+
+// contains reports whether a point is inside the polygon, by ray casting
+func (f Fence) contains(lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(f.Polygon)-1; i < len(f.Polygon); j, i = i, i+1 {
+		a, b := f.Polygon[i], f.Polygon[j]
+		if (a.Latitude > lat) != (b.Latitude > lat) &&
+			lon < (b.Longitude-a.Longitude)*(lat-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,387 @@
+package geofence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Kinds of events
+const (
+	Enter = "enter"
+	Exit  = "exit"
+)
+
+const (
+	// maxEvents is the number of recent events kept for get_geofence_events
+	maxEvents = 500
+	// forgetAfter is how long a vehicle missing from the snapshots keeps its
+	// state, covering short gaps in its reports
+	forgetAfter = 15 * time.Minute
+	// webhookTimeout bounds a webhook post
+	webhookTimeout = 5 * time.Second
+)
+
+// Event is a vehicle entering or leaving a fence, as posted to webhooks
+type Event struct {
+	FenceID        string    `json:"fence_id"`
+	FenceName      string    `json:"fence_name"`
+	Kind           string    `json:"event"` // Enter or Exit
+	VehicleID      int       `json:"vehicle_id"`
+	LineIdentifier string    `json:"line_identifier"`
+	LineCode       int       `json:"line_code"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	Time           time.Time `json:"time"` // When the position was reported
+}
+
+// Source streams vehicle position snapshots, as the SPTrans client does
+type Source interface {
+	StreamVehiclePositions(ctx context.Context, visit func(line types.VehicleLine)) (string, error)
+}
+
+// Notifier delivers events to the connected clients
+type Notifier func(ctx context.Context, event Event)
+
+// Observer receives every vehicle positions snapshot the watcher fetches
+type Observer func(lines []types.VehicleLine)
+
+// Status describes the evaluator
+type Status struct {
+	Interval      time.Duration
+	LastSnapshot  string    // Hour of the last evaluated snapshot
+	LastEvaluated time.Time // When the last snapshot was evaluated
+	LastError     string    // Error of the last evaluation, if it failed
+}
+
+// vehicleState is whether a vehicle is inside a fence
+type vehicleState struct {
+	inside   bool
+	lastSeen time.Time
+}
+
+// Watcher holds the fences and evaluates them against periodic snapshots of
+// the vehicle positions
+type Watcher struct {
+	source   Source
+	interval time.Duration
+	notify   Notifier
+	http     *http.Client
+
+	mu      sync.Mutex
+	observe Observer
+	fences  map[string]*Fence
+	states  map[string]map[int]*vehicleState // Vehicle states of each fence
+	events  []Event                          // Most recent events, oldest first
+	nextID  int
+	status  Status
+}
+
+// NewWatcher creates a watcher evaluating fences every interval
+func NewWatcher(source Source, interval time.Duration, notify Notifier) *Watcher {
+	return &Watcher{
+		source:   source,
+		interval: interval,
+		notify:   notify,
+		http:     &http.Client{Timeout: webhookTimeout},
+		fences:   make(map[string]*Fence),
+		states:   make(map[string]map[int]*vehicleState),
+		status:   Status{Interval: interval},
+	}
+}
+
+// Add validates and stores a new fence, assigning its ID
+func (w *Watcher) Add(f Fence) (Fence, error) {
+	if f.Hysteresis == 0 {
+		f.Hysteresis = DefaultHysteresis
+	}
+	if err := f.Validate(); err != nil {
+		return Fence{}, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.nextID++
+	f.ID = "gf" + strconv.Itoa(w.nextID)
+	f.CreatedAt = time.Now()
+	w.fences[f.ID] = &f
+	w.states[f.ID] = make(map[int]*vehicleState)
+	return f, nil
+}
+
+// Update applies change to a fence. Vehicle states start over, since the
+// area or the vehicles watched may have changed.
+func (w *Watcher) Update(id string, change func(f *Fence)) (Fence, bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	current, ok := w.fences[id]
+	if !ok {
+		return Fence{}, false, nil
+	}
+	f := *current
+	change(&f)
+	f.ID, f.CreatedAt = current.ID, current.CreatedAt
+	if f.Hysteresis == 0 {
+		f.Hysteresis = DefaultHysteresis
+	}
+	if err := f.Validate(); err != nil {
+		return Fence{}, true, err
+	}
+	w.fences[id] = &f
+	w.states[id] = make(map[int]*vehicleState)
+	return f, true, nil
+}
+
+// Remove deletes a fence
+func (w *Watcher) Remove(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.fences[id]; !ok {
+		return false
+	}
+	delete(w.fences, id)
+	delete(w.states, id)
+	return true
+}
+
+// Get returns a fence
+func (w *Watcher) Get(id string) (Fence, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	f, ok := w.fences[id]
+	if !ok {
+		return Fence{}, false
+	}
+	return *f, true
+}
+
+// List returns every fence in creation order
+func (w *Watcher) List() []Fence {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fences := make([]Fence, 0, len(w.fences))
+	for _, f := range w.fences {
+		fences = append(fences, *f)
+	}
+	sort.Slice(fences, func(i, j int) bool {
+		return fences[i].CreatedAt.Before(fences[j].CreatedAt) ||
+			(fences[i].CreatedAt.Equal(fences[j].CreatedAt) && fences[i].ID < fences[j].ID)
+	})
+	return fences
+}
+
+// Inside returns the number of vehicles currently inside a fence
+func (w *Watcher) Inside(id string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	count := 0
+	for _, state := range w.states[id] {
+		if state.inside {
+			count++
+		}
+	}
+	return count
+}
+
+// Events returns the most recent events, newest first, of one fence or of
+// all fences when id is empty
+func (w *Watcher) Events(id string, limit int) []Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var events []Event
+	for i := len(w.events) - 1; i >= 0 && (limit <= 0 || len(events) < limit); i-- {
+		if id == "" || w.events[i].FenceID == id {
+			events = append(events, w.events[i])
+		}
+	}
+	return events
+}
+
+// Status returns the state of the evaluator
+func (w *Watcher) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+// SetObserver hands every snapshot to observe, fetching one each interval
+// even while there are no fences
+func (w *Watcher) SetObserver(observe Observer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.observe = observe
+}
+
+// Run evaluates the fences every interval until the context is cancelled,
+// skipping the snapshot while there are no fences and no observer
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		w.mu.Lock()
+		idle := len(w.fences) == 0 && w.observe == nil
+		w.mu.Unlock()
+		if idle {
+			continue
+		}
+
+		if err := w.Evaluate(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Geofence evaluation failed: %v", err)
+		}
+	}
+}
+
+// Evaluate fetches one snapshot of the vehicle positions and emits the events
+// of the vehicles that crossed a fence since the previous one
+func (w *Watcher) Evaluate(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, w.interval+time.Minute)
+	defer cancel()
+
+	var events []Event
+	now := time.Now()
+	w.mu.Lock()
+	fences := make([]Fence, 0, len(w.fences))
+	for _, f := range w.fences {
+		fences = append(fences, *f)
+	}
+	observe := w.observe
+	w.mu.Unlock()
+
+	var lines []types.VehicleLine
+	hour, err := w.source.StreamVehiclePositions(ctx, func(line types.VehicleLine) {
+		if observe != nil {
+			lines = append(lines, line)
+		}
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		for _, f := range fences {
+			states, ok := w.states[f.ID]
+			if !ok {
+				continue // Removed while the snapshot was being read
+			}
+			for _, vehicle := range line.Vehicles {
+				if !f.Matches(line, vehicle) {
+					continue
+				}
+				if event, ok := transition(f, states, line, vehicle, now); ok {
+					events = append(events, event)
+				}
+			}
+		}
+	})
+
+	w.mu.Lock()
+	w.status.LastEvaluated = now
+	if err != nil {
+		w.status.LastError = err.Error()
+		w.mu.Unlock()
+		return fmt.Errorf("failed to get vehicle positions: %w", err)
+	}
+	w.status.LastSnapshot = hour
+	w.status.LastError = ""
+	for _, states := range w.states {
+		for id, state := range states {
+			if now.Sub(state.lastSeen) > forgetAfter {
+				delete(states, id)
+			}
+		}
+	}
+	w.events = append(w.events, events...)
+	if len(w.events) > maxEvents {
+		w.events = append([]Event(nil), w.events[len(w.events)-maxEvents:]...)
+	}
+	w.mu.Unlock()
+
+	if observe != nil {
+		observe(lines)
+	}
+	for _, event := range events {
+		w.deliver(ctx, event)
+	}
+	return nil
+}
+
+// transition updates the state of a vehicle in a fence and returns the event
+// it produced, if any. Vehicles first seen set their state silently, and a
+// vehicle within the hysteresis band of the boundary keeps its state, so
+// position jitter near the boundary produces no events. The caller holds
+// the lock.
+func transition(f Fence, states map[int]*vehicleState, line types.VehicleLine, vehicle types.Vehicle, now time.Time) (Event, bool) {
+	band := f.hysteresis()
+	distance := f.signedDistance(vehicle.Latitude, vehicle.Longitude)
+
+	state, known := states[vehicle.ID]
+	if !known {
+		states[vehicle.ID] = &vehicleState{inside: distance < 0, lastSeen: now}
+		return Event{}, false
+	}
+	state.lastSeen = now
+
+	var kind string
+	switch {
+	case !state.inside && distance <= -band:
+		kind = Enter
+	case state.inside && distance >= band:
+		kind = Exit
+	default:
+		return Event{}, false
+	}
+	state.inside = kind == Enter
+	return Event{
+		FenceID:        f.ID,
+		FenceName:      f.Name,
+		Kind:           kind,
+		VehicleID:      vehicle.ID,
+		LineIdentifier: line.Identifier,
+		LineCode:       line.Code,
+		Latitude:       vehicle.Latitude,
+		Longitude:      vehicle.Longitude,
+		Time:           vehicle.LastUpdate,
+	}, true
+}
+
+// deliver notifies the clients of an event and posts it to the webhook of
+// its fence
+func (w *Watcher) deliver(ctx context.Context, event Event) {
+	if w.notify != nil {
+		w.notify(ctx, event)
+	}
+	f, ok := w.Get(event.FenceID)
+	if !ok || f.Webhook == "" {
+		return
+	}
+	go w.post(f.Webhook, event)
+}
+
+// post sends an event to a webhook as JSON
+func (w *Watcher) post(webhook string, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode geofence event: %v", err)
+		return
+	}
+	resp, err := w.http.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Geofence webhook %s failed: %v", webhook, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("Geofence webhook %s returned %s", webhook, resp.Status)
+	}
+}
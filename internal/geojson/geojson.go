@@ -0,0 +1,53 @@
+package geojson
+
+// Position is a GeoJSON position, longitude first
+type Position [2]float64
+
+// Geometry is a GeoJSON geometry
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature with its properties
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// NewFeatureCollection creates an empty feature collection
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+// Add appends a feature with the given geometry and properties
+func (fc *FeatureCollection) Add(geometry Geometry, properties map[string]any) {
+	fc.Features = append(fc.Features, Feature{Type: "Feature", Geometry: geometry, Properties: properties})
+}
+
+// At returns the position of a latitude and longitude
+func At(lat, lon float64) Position {
+	return Position{lon, lat}
+}
+
+// Point returns a point geometry at the latitude and longitude
+func Point(lat, lon float64) Geometry {
+	return Geometry{Type: "Point", Coordinates: At(lat, lon)}
+}
+
+// LineString returns a line geometry through the positions
+func LineString(positions []Position) Geometry {
+	return Geometry{Type: "LineString", Coordinates: positions}
+}
+
+// Polygon returns a polygon geometry bounded by the rings, the outer ring first
+func Polygon(rings [][]Position) Geometry {
+	return Geometry{Type: "Polygon", Coordinates: rings}
+}
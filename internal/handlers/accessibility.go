@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultFleetLines = 20
+	maxFleetLines     = 200
+)
+
+// GetAccessibleFleetParams defines the parameters for the accessible share of the fleet
+type GetAccessibleFleetParams struct {
+	LinePrefix string `json:"line_prefix,omitempty" jsonschema:"Only include lines whose identifier starts with this prefix (e.g. 875A)"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"Maximum number of lines to return, defaults to 20 (max 200)"`
+	Format     string `json:"format,omitempty"`
+}
+
+// Validate checks the get_accessible_fleet arguments
+func (p GetAccessibleFleetParams) Validate() error {
+	if p.Limit < 0 || p.Limit > maxFleetLines {
+		return pipeline.Invalid("error.limit_range", maxFleetLines)
+	}
+	return nil
+}
+
+// GetAccessibleFleet handles the get_accessible_fleet MCP tool
+func GetAccessibleFleet(ctx context.Context, call *pipeline.Call, args GetAccessibleFleetParams) (any, error) {
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultFleetLines
+	}
+
+	// Count the vehicles of both directions of each line in one snapshot
+	filter := types.VehicleFilter{LinePrefix: args.LinePrefix}
+	fleets := make(map[string]*types.AccessibleFleetLineResponse)
+	response := types.GetAccessibleFleetResponse{LinePrefix: args.LinePrefix}
+	hour, err := GlobalClient.StreamVehiclePositions(ctx, func(line types.VehicleLine) {
+		if !filter.MatchLine(line) || len(line.Vehicles) == 0 {
+			return
+		}
+		fleet, ok := fleets[line.Identifier]
+		if !ok {
+			fleet = &types.AccessibleFleetLineResponse{Line: line.Identifier}
+			fleets[line.Identifier] = fleet
+		}
+		for _, vehicle := range line.Vehicles {
+			fleet.Vehicles++
+			response.Vehicles++
+			if vehicle.Accessible {
+				fleet.Accessible++
+				response.Accessible++
+			}
+		}
+	})
+	if err != nil {
+		return nil, pipeline.Failed("error.get_accessible_fleet", err)
+	}
+
+	response.Timestamp = hour
+	response.Percent = percent(response.Accessible, response.Vehicles)
+	response.TotalLines = len(fleets)
+	response.Lines = make([]types.AccessibleFleetLineResponse, 0, len(fleets))
+	for _, fleet := range fleets {
+		fleet.Percent = percent(fleet.Accessible, fleet.Vehicles)
+		response.Lines = append(response.Lines, *fleet)
+	}
+
+	// Lines with the smallest accessible share first, the busiest first among equals
+	sort.Slice(response.Lines, func(i, j int) bool {
+		a, b := response.Lines[i], response.Lines[j]
+		if a.Percent != b.Percent {
+			return a.Percent < b.Percent
+		}
+		if a.Vehicles != b.Vehicles {
+			return a.Vehicles > b.Vehicles
+		}
+		return a.Line < b.Line
+	})
+	if len(response.Lines) > limit {
+		response.Lines = response.Lines[:limit]
+	}
+	response.TotalResults = len(response.Lines)
+	return response, nil
+}
+
+// percent returns part as a percentage of total, rounded to one decimal
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	maxBatchQueries   = 20
+	defaultBatchLimit = 5
+	batchWorkers      = 4 // Lookups run at once by a batch, all within the client rate limit
+)
+
+// PredictionQuery is a stop, and optionally a line, to get predictions for
+type PredictionQuery struct {
+	StopCode Ref `json:"stop_code" jsonschema:"The stop: a stop code, a stop name or an address"`
+	LineCode Ref `json:"line_code,omitempty" jsonschema:"Only include this line: a line code, a sign such as 875A-10 with an optional direction such as 875A-10/1, or a search term"`
+}
+
+// GetPredictionsBatchParams defines the parameters for getting the predictions of several stops and lines
+type GetPredictionsBatchParams struct {
+	Queries        []PredictionQuery `json:"queries" jsonschema:"The stops to get predictions for, each with an optional line (1-20)"`
+	AccessibleOnly *bool             `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	Limit          int               `json:"limit,omitempty" jsonschema:"Maximum number of departures per query, defaults to 5 (max 50)"`
+	Format         string            `json:"format,omitempty"`
+}
+
+// Validate checks the get_predictions_batch arguments
+func (p GetPredictionsBatchParams) Validate() error {
+	if len(p.Queries) == 0 || len(p.Queries) > maxBatchQueries {
+		return pipeline.Invalid("error.batch_size", maxBatchQueries)
+	}
+	for i, q := range p.Queries {
+		if err := validStopRef(fmt.Sprintf("queries[%d].stop_code", i), q.StopCode); err != nil {
+			return err
+		}
+		if q.LineCode != nil {
+			if err := validLineRef(fmt.Sprintf("queries[%d].line_code", i), q.LineCode); err != nil {
+				return err
+			}
+		}
+	}
+	if p.Limit < 0 || p.Limit > maxDepartures {
+		return pipeline.Invalid("error.limit_range", maxDepartures)
+	}
+	return nil
+}
+
+// GetPredictionsBatch handles the get_predictions_batch MCP tool. Equal stops
+// and lines are resolved once and the predictions of each stop are fetched
+// once, however many queries name it; a query that fails is reported in its
+// item without failing the others.
+func GetPredictionsBatch(ctx context.Context, call *pipeline.Call, args GetPredictionsBatchParams) (any, error) {
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultBatchLimit
+	}
+	queries := args.Queries
+
+	// Resolve each distinct stop, then each distinct line at its stop
+	stopKeys := make([]string, len(queries))
+	for i, q := range queries {
+		stopKeys[i] = refKey(q.StopCode)
+	}
+	stopRefs := distinct(stopKeys)
+	stops := make(map[string]types.Stop, len(stopRefs))
+	stopErrs := make(map[string]error)
+	var mu sync.Mutex
+	fanOut(len(stopRefs), func(i int) {
+		key := stopRefs[i]
+		q := indexOf(stopKeys, key)
+		stop, err := resolveStopRef(ctx, fmt.Sprintf("queries[%d].stop_code", q), queries[q].StopCode)
+		mu.Lock()
+		defer mu.Unlock()
+		stops[key], stopErrs[key] = stop, err
+	})
+
+	lineKeys := make([]string, len(queries))
+	for i, q := range queries {
+		if q.LineCode != nil && stopErrs[stopKeys[i]] == nil {
+			lineKeys[i] = strconv.Itoa(stops[stopKeys[i]].Code) + "|" + refKey(q.LineCode)
+		}
+	}
+	lineRefs := distinct(lineKeys)
+	lines := make(map[string]types.Line, len(lineRefs))
+	lineErrs := make(map[string]error)
+	fanOut(len(lineRefs), func(i int) {
+		key := lineRefs[i]
+		q := indexOf(lineKeys, key)
+		line, err := resolveLineRef(ctx, fmt.Sprintf("queries[%d].line_code", q), queries[q].LineCode, stops[stopKeys[q]].Code)
+		mu.Lock()
+		defer mu.Unlock()
+		lines[key], lineErrs[key] = line, err
+	})
+
+	// Fetch the predictions of each distinct stop
+	codeKeys := make([]string, len(queries))
+	for i := range queries {
+		if stopErrs[stopKeys[i]] == nil && lineErrs[lineKeys[i]] == nil {
+			codeKeys[i] = strconv.Itoa(stops[stopKeys[i]].Code)
+		}
+	}
+	codes := distinct(codeKeys)
+	predictions := make(map[string]*types.ArrivalPredictionsByLine, len(codes))
+	fetchErrs := make(map[string]error)
+	fanOut(len(codes), func(i int) {
+		code, _ := strconv.Atoi(codes[i])
+		found, err := GlobalClient.GetArrivalPredictionsByStop(ctx, code)
+		mu.Lock()
+		defer mu.Unlock()
+		predictions[codes[i]], fetchErrs[codes[i]] = found, err
+	})
+
+	locale := SessionLocale(call.Session)
+	filter := boardFilter{accessibleOnly: accessibleOnly(call, args.AccessibleOnly)}
+	response := types.GetPredictionsBatchResponse{
+		TotalQueries: len(queries),
+		StopsFetched: len(codes),
+		Items:        make([]types.PredictionsBatchItemResponse, len(queries)),
+	}
+	for i, q := range queries {
+		item := types.PredictionsBatchItemResponse{Index: i, Stop: fmt.Sprint(q.StopCode)}
+		if q.LineCode != nil {
+			item.Line = fmt.Sprint(q.LineCode)
+		}
+		err := stopErrs[stopKeys[i]]
+		if err == nil {
+			err = lineErrs[lineKeys[i]]
+		}
+		if err == nil && fetchErrs[codeKeys[i]] != nil {
+			err = pipeline.Failed("error.get_arrival_predictions_by_stop", fetchErrs[codeKeys[i]])
+		}
+		if err != nil {
+			description := pipeline.Describe(err, locale)
+			item.Error = &description
+			response.Failed++
+			response.Items[i] = item
+			continue
+		}
+
+		query := filter
+		if line := lines[lineKeys[i]]; line.Code != 0 {
+			item.LineCode = line.Code
+			query.lines = map[int]bool{line.Code: true}
+		}
+		board := buildBoard(ctx, stops[stopKeys[i]].Code, predictions[codeKeys[i]], query, limit)
+		item.Board = &board
+		response.Succeeded++
+		response.Items[i] = item
+	}
+	return response, nil
+}
+
+// refKey identifies a reference, so that equal ones are looked up once
+func refKey(ref Ref) string {
+	code, text, _ := parseRef(ref)
+	if code != 0 {
+		return strconv.Itoa(code)
+	}
+	return strings.ToLower(text)
+}
+
+// distinct returns the non-empty keys without repetitions, in order
+func distinct(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	var unique []string
+	for _, key := range keys {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// indexOf returns the position of the first occurrence of a key
+func indexOf(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// fanOut calls fn with each index below n on at most batchWorkers goroutines
+func fanOut(n int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(n, batchWorkers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// GetCatalogStatusParams defines the parameters for getting the catalog status
+type GetCatalogStatusParams struct {
+	Format string `json:"format,omitempty"`
+}
+
+// GetCatalogStatus handles the get_catalog_status MCP tool
+func GetCatalogStatus(ctx context.Context, call *pipeline.Call, args GetCatalogStatusParams) (any, error) {
+	version, updatedAt := GlobalCatalog.Version()
+	response := types.CatalogStatusResponse{
+		Version:   version,
+		UpdatedAt: formatTime(updatedAt),
+		Lines:     GlobalCatalog.LineCount(),
+		Stops:     GlobalCatalog.StopCount(),
+		Corridors: GlobalCatalog.CorridorCount(),
+	}
+
+	if GlobalCrawler != nil {
+		status := GlobalCrawler.Status()
+		response.Crawler = true
+		response.Crawling = status.Running
+		response.LinesPending = status.LinesPending
+		response.LinesFetched = status.LinesFetched
+		response.Failures = status.Failures
+		response.LastError = status.LastError
+		response.NextCrawl = formatTime(status.NextCrawl)
+	}
+	return response, nil
+}
+
+// formatTime formats t as RFC 3339, or returns an empty string for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -1,11 +1,99 @@
 package handlers
 
-import "github.com/thunderjr/sptrans-mcp/internal/client"
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/catalog"
+	"github.com/thunderjr/sptrans-mcp/internal/client"
+	"github.com/thunderjr/sptrans-mcp/internal/fare"
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/geofence"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/journey"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/session"
+	"github.com/thunderjr/sptrans-mcp/internal/shape"
+)
 
 // GlobalClient holds the SPTrans client instance for use by handlers
 var GlobalClient *client.Client
 
+// GlobalCatalog holds the network known to the server, filled by the crawler
+// and fed by stop responses
+var GlobalCatalog = catalog.New()
+
+// GlobalCrawler keeps GlobalCatalog fresh, or is nil when crawling is disabled
+var GlobalCrawler *catalog.Crawler
+
+// GlobalGeofences holds the watched areas, or is nil when geofencing is disabled
+var GlobalGeofences *geofence.Watcher
+
+// GlobalShapes holds the route shapes of the lines, or is nil when they are
+// not downloaded and vehicles are snapped to the stop sequence
+var GlobalShapes *shape.Store
+
+// GlobalWalker estimates walking distances and times for the walking tools
+var GlobalWalker = geo.DefaultWalker
+
+// GlobalFares holds the fare table and integration rules used to price trips
+var GlobalFares = fare.Default
+
+// GlobalSpeeds measures the running speed of the lines from the position
+// snapshots fetched by the isochrone tool
+var GlobalSpeeds = journey.NewSpeeds()
+
+// Sessions holds the preferences of each connected session
+var Sessions = session.NewStore(session.Preferences{Locale: i18n.English})
+
+// Metrics holds the call metrics recorded by the tool pipeline
+var Metrics = pipeline.NewMetrics()
+
 // SetGlobalClient sets the global SPTrans client
 func SetGlobalClient(c *client.Client) {
 	GlobalClient = c
-}
\ No newline at end of file
+}
+
+// SetGlobalCrawler sets the crawler that keeps the global catalog fresh
+func SetGlobalCrawler(c *catalog.Crawler) {
+	GlobalCrawler = c
+}
+
+// SetGlobalGeofences sets the watcher evaluating geofences
+func SetGlobalGeofences(w *geofence.Watcher) {
+	GlobalGeofences = w
+}
+
+// SetGlobalShapes sets the store of route shapes
+func SetGlobalShapes(s *shape.Store) {
+	GlobalShapes = s
+}
+
+// SetGlobalWalker sets the detour factor and walking speed used by default
+func SetGlobalWalker(w geo.Walker) {
+	GlobalWalker = w
+}
+
+// SetGlobalFares sets the fare table used to price trips
+func SetGlobalFares(t fare.Table) {
+	GlobalFares = t
+}
+
+// SetDefaultLocale sets the locale of sessions that have not chosen one
+func SetDefaultLocale(l i18n.Locale) {
+	Sessions.SetDefaults(session.Preferences{Locale: l})
+}
+
+// SessionLocale returns the locale selected for the session
+func SessionLocale(ss *mcp.ServerSession) i18n.Locale {
+	return Sessions.Get(ss).Locale
+}
+
+// ForgetOnClose drops the preferences of the session once its client
+// disconnects, so the store only holds live sessions
+func ForgetOnClose(_ context.Context, ss *mcp.ServerSession, _ *mcp.InitializedParams) {
+	go func() {
+		_ = ss.Wait()
+		Sessions.Forget(ss)
+	}()
+}
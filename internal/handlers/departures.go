@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/direction"
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultDepartures = 10
+	maxDepartures     = 50
+	maxTowardsLookups = 8 // Itineraries fetched per call to match towards against their stops
+)
+
+// DepartureBoardParams defines the parameters for the departure board of a stop
+type DepartureBoardParams struct {
+	StopCode       Ref    `json:"stop_code" jsonschema:"The stop to list the next departures for: a stop code, a stop name or an address"`
+	Lines          []Ref  `json:"lines,omitempty" jsonschema:"Only include these lines: line codes, signs such as 8000-10, numbers such as 8000 or search terms"`
+	Direction      int    `json:"direction,omitempty" jsonschema:"Only include lines running in this direction (1 or 2)"`
+	Towards        string `json:"towards,omitempty" jsonschema:"Only include lines heading towards this terminal or stop, in place of direction"`
+	AccessibleOnly *bool  `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	Limit          int    `json:"limit,omitempty" jsonschema:"Maximum number of departures to return, defaults to 10 (max 50)"`
+	Format         string `json:"format,omitempty"`
+}
+
+// Validate checks the departure_board arguments
+func (p DepartureBoardParams) Validate() error {
+	if err := validStopRef("stop_code", p.StopCode); err != nil {
+		return err
+	}
+	if err := validLineRefs("lines", p.Lines); err != nil {
+		return err
+	}
+	if p.Direction != 0 && p.Direction != 1 && p.Direction != 2 {
+		return pipeline.Invalid("error.direction")
+	}
+	if p.Direction != 0 && p.Towards != "" {
+		return pipeline.Invalid("error.direction_or_towards")
+	}
+	if p.Limit < 0 || p.Limit > maxDepartures {
+		return pipeline.Invalid("error.limit_range", maxDepartures)
+	}
+	return nil
+}
+
+// DepartureBoard handles the departure_board MCP tool
+func DepartureBoard(ctx context.Context, call *pipeline.Call, args DepartureBoardParams) (any, error) {
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultDepartures
+	}
+
+	stop, err := resolveStopRef(ctx, "stop_code", args.StopCode)
+	if err != nil {
+		return nil, err
+	}
+
+	predictions, err := GlobalClient.GetArrivalPredictionsByStop(ctx, stop.Code)
+	if err != nil {
+		return nil, pipeline.Failed("error.departure_board", err)
+	}
+	lines, err := resolveBoardLines(ctx, "lines", args.Lines, stop.Code, predictions)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildBoard(ctx, stop.Code, predictions, boardFilter{
+		lines:          lines,
+		direction:      args.Direction,
+		towards:        args.Towards,
+		accessibleOnly: accessibleOnly(call, args.AccessibleOnly),
+	}, limit), nil
+}
+
+// boardFilter selects the departures listed on a board
+type boardFilter struct {
+	lines          map[int]bool // Codes of the lines to list, all when nil
+	direction      int          // Direction of the lines to list, both when zero
+	towards        string       // Terminal or stop the lines listed head towards
+	accessibleOnly bool         // List only wheelchair-accessible vehicles
+}
+
+// buildBoard lists the departures predicted at a stop that pass the filter,
+// soonest first, with the wait for the next accessible bus of each line
+func buildBoard(ctx context.Context, stopCode int, predictions *types.ArrivalPredictionsByLine, filter boardFilter, limit int) types.DepartureBoardResponse {
+	response := types.DepartureBoardResponse{
+		Timestamp:      predictions.Hour,
+		StopCode:       stopCode,
+		AccessibleOnly: filter.accessibleOnly,
+		Departures:     []types.DepartureResponse{},
+		NextAccessible: []types.AccessibleWaitResponse{},
+	}
+	lookups := maxTowardsLookups
+	for _, stop := range predictions.Stops {
+		if response.StopName == "" {
+			response.StopName = stop.Name
+		}
+		for _, line := range stop.Lines {
+			if filter.direction != 0 && line.Direction != filter.direction {
+				continue
+			}
+			if filter.lines != nil && !filter.lines[line.Code] {
+				continue
+			}
+			if filter.towards != "" {
+				candidate := types.Line{Code: line.Code, Direction: line.Direction, Origin: line.Origin, Destination: line.Destination}
+				if known, ok := GlobalCatalog.Line(line.Code); ok {
+					candidate = known
+				}
+				if !headingTowards(ctx, filter.towards, stop.Code, candidate, &lookups) {
+					continue
+				}
+			}
+			headsign := types.Line{Direction: line.Direction, Origin: line.Origin, Destination: line.Destination}.Headsign()
+			wait := types.AccessibleWaitResponse{LineCode: line.Code, Line: line.Identifier, Destination: headsign}
+			for _, p := range line.Predictions {
+				minutes, ok := types.MinutesUntil(predictions.Hour, p.ArrivalTime)
+				if !ok {
+					continue
+				}
+				minutes = max(minutes, 0)
+				if p.Accessible && (wait.MinutesUntil == nil || minutes < *wait.MinutesUntil) {
+					wait.MinutesUntil = &minutes
+					wait.ArrivalTime = p.ArrivalTime
+					wait.VehicleID = p.VehicleID
+				}
+				if response.AccessibleOnly && !p.Accessible {
+					continue
+				}
+				departure := types.DepartureResponse{
+					LineCode:     line.Code,
+					Line:         line.Identifier,
+					Direction:    line.Direction,
+					Destination:  headsign,
+					ArrivalTime:  p.ArrivalTime,
+					MinutesUntil: minutes,
+					VehicleID:    p.VehicleID,
+					Accessible:   p.Accessible,
+					LastUpdate:   p.LastUpdate,
+				}
+				if geo.ValidCoordinate(p.Latitude, p.Longitude) && (p.Latitude != 0 || p.Longitude != 0) {
+					distance := int(math.Round(geo.Distance(stop.Latitude, stop.Longitude, p.Latitude, p.Longitude)))
+					departure.DistanceMeters = &distance
+				}
+				response.Departures = append(response.Departures, departure)
+			}
+			if len(line.Predictions) > 0 {
+				response.NextAccessible = append(response.NextAccessible, wait)
+			}
+		}
+	}
+
+	sort.SliceStable(response.Departures, func(i, j int) bool {
+		a, b := response.Departures[i], response.Departures[j]
+		if a.MinutesUntil != b.MinutesUntil {
+			return a.MinutesUntil < b.MinutesUntil
+		}
+		return a.Line < b.Line
+	})
+	sort.SliceStable(response.NextAccessible, func(i, j int) bool {
+		a, b := response.NextAccessible[i], response.NextAccessible[j]
+		if (a.MinutesUntil == nil) != (b.MinutesUntil == nil) {
+			return b.MinutesUntil == nil
+		}
+		if a.MinutesUntil != nil && *a.MinutesUntil != *b.MinutesUntil {
+			return *a.MinutesUntil < *b.MinutesUntil
+		}
+		return a.Line < b.Line
+	})
+	if len(response.Departures) > limit {
+		response.Departures = response.Departures[:limit]
+	}
+	response.TotalResults = len(response.Departures)
+	return response
+}
+
+// resolveBoardLines finds the codes of the lines a board is limited to. Text
+// naming the sign, number or code of lines predicted at the stop selects
+// them directly, covering both directions of a number; any other argument is
+// resolved like a line argument, preferring the lines serving the stop.
+func resolveBoardLines(ctx context.Context, name string, refs []Ref, stopCode int, predictions *types.ArrivalPredictionsByLine) (map[int]bool, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	codes := make(map[int]bool, len(refs))
+	for i, ref := range refs {
+		code, text, _ := parseRef(ref)
+		if code != 0 {
+			codes[code] = true
+			continue
+		}
+		matched := false
+		for _, stop := range predictions.Stops {
+			for _, line := range stop.Lines {
+				if matchesLine(text, line.Code, line.Identifier) {
+					codes[line.Code] = true
+					matched = true
+				}
+			}
+		}
+		if matched {
+			continue
+		}
+		line, err := resolveLineRef(ctx, fmt.Sprintf("%s[%d]", name, i), ref, stopCode)
+		if err != nil {
+			return nil, err
+		}
+		codes[line.Code] = true
+	}
+	return codes, nil
+}
+
+// matchesLine reports whether text is the sign, number or code of a line
+func matchesLine(text string, code int, identifier string) bool {
+	number, _, _ := strings.Cut(identifier, "-")
+	return strings.EqualFold(text, identifier) || strings.EqualFold(text, number) || text == strconv.Itoa(code)
+}
+
+// headingTowards reports whether a line leaving a stop heads towards a place,
+// matching its terminals and then the stops after this one on its itinerary,
+// fetching at most lookups itineraries missing from the catalog
+func headingTowards(ctx context.Context, towards string, stopCode int, line types.Line, lookups *int) bool {
+	candidate := direction.Candidate{Line: line, From: -1}
+	if direction.Score(towards, candidate).Score >= direction.Threshold {
+		return true
+	}
+
+	stops, ok := GlobalCatalog.LineStops(line.Code)
+	if !ok || len(stops) == 0 {
+		if *lookups == 0 {
+			return false
+		}
+		*lookups--
+		var err error
+		if stops, err = stopsOfLine(ctx, line.Code); err != nil {
+			return false
+		}
+	}
+	candidate.Stops = stops
+	for i, stop := range stops {
+		if stop.Code == stopCode {
+			candidate.From = i
+			break
+		}
+	}
+	return direction.Score(towards, candidate).Score >= direction.Threshold
+}
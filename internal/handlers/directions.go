@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/direction"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// ResolveDirectionParams defines the parameters for finding the direction of a line towards a place
+type ResolveDirectionParams struct {
+	Line    Ref    `json:"line" jsonschema:"The line: a line code, a number such as 8000 for both directions, a sign such as 8000-10, or a search term"`
+	Towards string `json:"towards" jsonschema:"Where the rider is heading: a terminal such as Pq. D. Pedro II or a stop on the way"`
+	Format  string `json:"format,omitempty"`
+}
+
+// Validate checks the resolve_direction arguments
+func (p ResolveDirectionParams) Validate() error {
+	if err := validLineRef("line", p.Line); err != nil {
+		return err
+	}
+	if strings.TrimSpace(p.Towards) == "" {
+		return pipeline.Invalid("error.required", "towards")
+	}
+	return nil
+}
+
+// ResolveDirection handles the resolve_direction MCP tool
+func ResolveDirection(ctx context.Context, call *pipeline.Call, args ResolveDirectionParams) (any, error) {
+	lines, err := lineDirections(ctx, args.Line)
+	if err != nil {
+		return nil, err
+	}
+	candidates := towardsCandidates(lines)
+	if err := addItineraries(ctx, candidates); err != nil {
+		return nil, err
+	}
+	matches := direction.Rank(args.Towards, candidates)
+
+	response := types.ResolveDirectionResponse{
+		Line:       fmt.Sprint(args.Line),
+		Towards:    args.Towards,
+		Directions: make([]types.DirectionMatchResponse, len(matches)),
+	}
+	for i, m := range matches {
+		response.Directions[i] = types.DirectionMatchResponse{
+			Line:      types.ConvertLine(m.Line),
+			Terminals: direction.Terminals(m.Line),
+			Score:     math.Round(m.Score*100) / 100,
+			MatchedBy: m.By,
+			Matched:   m.Name,
+		}
+	}
+	if best, err := direction.Resolve(args.Towards, candidates); err == nil {
+		line := types.ConvertLine(best.Line)
+		response.Resolved = &line
+	}
+	return response, nil
+}
+
+// lineDirections finds the lines a line argument refers to together with
+// their other direction, looked up in the catalog first and then by
+// searching the line number
+func lineDirections(ctx context.Context, ref Ref) ([]types.Line, error) {
+	lines, err := resolveLineRefs(ctx, "line", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	have := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		have[line.Code] = true
+	}
+	siblings := func(candidates []types.Line) {
+		for _, line := range lines {
+			for _, c := range candidates {
+				if !have[c.Code] && line.Number != "" && c.Sign() == line.Sign() && c.Direction != line.Direction {
+					have[c.Code] = true
+					lines = append(lines, c)
+				}
+			}
+		}
+	}
+	siblings(GlobalCatalog.Lines())
+	for _, line := range lines {
+		if line.IsCircular || line.Number == "" || hasDirection(lines, line, 3-line.Direction) {
+			continue
+		}
+		found, err := GlobalClient.SearchLines(ctx, line.Number)
+		if err != nil {
+			return nil, pipeline.Failed("error.resolve_direction", err)
+		}
+		GlobalCatalog.AddLines(found)
+		siblings(found)
+		break
+	}
+	return lines, nil
+}
+
+// hasDirection reports whether the lines include the given direction of a line
+func hasDirection(lines []types.Line, line types.Line, dir int) bool {
+	for _, l := range lines {
+		if l.Sign() == line.Sign() && l.Direction == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTowards picks the direction of the lines heading towards a place,
+// matching its terminals first and then the stops of the itineraries
+func resolveTowards(ctx context.Context, lines []types.Line, towards string) (direction.Match, error) {
+	candidates := towardsCandidates(lines)
+	best, err := direction.Resolve(towards, candidates)
+	if err == nil {
+		return best, nil
+	}
+
+	if err := addItineraries(ctx, candidates); err != nil {
+		return direction.Match{}, err
+	}
+	best, err = direction.Resolve(towards, candidates)
+	switch {
+	case errors.Is(err, direction.ErrAmbiguous):
+		return direction.Match{}, pipeline.Invalid("error.towards_ambiguous", towards, terminalList(lines))
+	case err != nil:
+		return direction.Match{}, pipeline.Missing("error.towards_unknown", towards, terminalList(lines))
+	}
+	return best, nil
+}
+
+// towardsCandidates returns the directions of the lines as candidates
+// without itineraries
+func towardsCandidates(lines []types.Line) []direction.Candidate {
+	candidates := make([]direction.Candidate, len(lines))
+	for i, line := range lines {
+		candidates[i] = direction.Candidate{Line: line, From: -1}
+	}
+	return candidates
+}
+
+// addItineraries fills in the ordered stops of each candidate direction
+func addItineraries(ctx context.Context, candidates []direction.Candidate) error {
+	for i := range candidates {
+		stops, err := stopsOfLine(ctx, candidates[i].Line.Code)
+		if err != nil {
+			return pipeline.Failed("error.resolve_direction", err)
+		}
+		candidates[i].Stops = stops
+	}
+	return nil
+}
+
+// terminalList describes the terminals each direction heads to, e.g.
+// "8000-10 (1) → Lapa; 8000-10 (2) → Pq. D. Pedro II"
+func terminalList(lines []types.Line) string {
+	parts := make([]string, len(lines))
+	for i, line := range lines {
+		parts[i] = fmt.Sprintf("%s (%d) → %s", line.Sign(), line.Direction, strings.Join(direction.Terminals(line), " / "))
+	}
+	return strings.Join(parts, "; ")
+}
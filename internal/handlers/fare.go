@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/fare"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// maxFareLegs is the maximum number of boardings priced in one call
+const maxFareLegs = 10
+
+// FareLegParams defines a boarding to be priced
+type FareLegParams struct {
+	Mode    string `json:"mode" jsonschema:"bus for SPTrans buses, rail for metro and CPTM trains"`
+	BoardAt string `json:"board_at,omitempty" jsonschema:"Local boarding time (HH:MM), defaults to the time of the previous leg"`
+}
+
+// CalculateFareParams defines the parameters for pricing a sequence of boardings
+type CalculateFareParams struct {
+	Legs     []FareLegParams `json:"legs" jsonschema:"Boardings in the order they are made"`
+	Category string          `json:"category,omitempty" jsonschema:"Fare category: full, student or elderly, defaults to full"`
+	Format   string          `json:"format,omitempty"`
+}
+
+// Validate checks the calculate_fare arguments
+func (p CalculateFareParams) Validate() error {
+	if len(p.Legs) == 0 || len(p.Legs) > maxFareLegs {
+		return pipeline.Invalid("error.fare_legs", maxFareLegs)
+	}
+	for _, leg := range p.Legs {
+		if fareMode(leg.Mode) == "" {
+			return pipeline.Invalid("error.fare_mode", leg.Mode)
+		}
+		if leg.BoardAt != "" {
+			if _, err := time.Parse("15:04", leg.BoardAt); err != nil {
+				return pipeline.Invalid("error.fare_time", leg.BoardAt)
+			}
+		}
+	}
+	return validFareCategory(p.Category)
+}
+
+// validFareCategory checks that a category, if given, is in the fare table
+func validFareCategory(category string) error {
+	if _, ok := GlobalFares.Categories[category]; category != "" && !ok {
+		categories := make([]string, 0, len(GlobalFares.Categories))
+		for c := range GlobalFares.Categories {
+			categories = append(categories, c)
+		}
+		sort.Strings(categories)
+		return pipeline.Invalid("error.fare_category", strings.Join(categories, ", "))
+	}
+	return nil
+}
+
+// fareMode returns the fare mode of a mode name, or empty if it is unknown
+func fareMode(mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case fare.Bus, "onibus", "ônibus":
+		return fare.Bus
+	case fare.Rail, "metro", "metrô", "train", "trem", "cptm":
+		return fare.Rail
+	}
+	return ""
+}
+
+// CalculateFare handles the calculate_fare MCP tool
+func CalculateFare(ctx context.Context, call *pipeline.Call, args CalculateFareParams) (any, error) {
+	category := args.Category
+	if category == "" {
+		category = fare.Full
+	}
+
+	// Time each boarding from the first one with a boarding time
+	reference := ""
+	for _, leg := range args.Legs {
+		if leg.BoardAt != "" {
+			reference = leg.BoardAt
+			break
+		}
+	}
+	legs := make([]fare.Leg, len(args.Legs))
+	boardAt := make([]string, len(args.Legs))
+	var at time.Duration
+	for i, leg := range args.Legs {
+		if leg.BoardAt != "" {
+			minutes, _ := types.MinutesUntil(reference, leg.BoardAt)
+			next := time.Duration(minutes) * time.Minute
+			if next < at {
+				next += 24 * time.Hour
+			}
+			at = next
+		}
+		legs[i] = fare.Leg{Mode: fareMode(leg.Mode), At: at}
+		boardAt[i] = leg.BoardAt
+	}
+
+	result, err := GlobalFares.Calculate(legs, category)
+	if err != nil {
+		return nil, pipeline.Invalid("error.calculate_fare", err)
+	}
+	return convertFare(result, legs, boardAt, category), nil
+}
+
+// priceTrip prices the bus rides of a trip option, boarded at the given
+// times since the start of the trip, for a category
+func priceTrip(rides []time.Duration, boardAt []string, category string) *types.FareResponse {
+	legs := make([]fare.Leg, len(rides))
+	for i, at := range rides {
+		legs[i] = fare.Leg{Mode: fare.Bus, At: at}
+	}
+	result, err := GlobalFares.Calculate(legs, category)
+	if err != nil {
+		return nil
+	}
+	price := convertFare(result, legs, boardAt, category)
+	return &price
+}
+
+// convertFare converts the charges of the legs to a fare response
+func convertFare(result fare.Result, legs []fare.Leg, boardAt []string, category string) types.FareResponse {
+	response := types.FareResponse{
+		Category:      category,
+		Currency:      "BRL",
+		Total:         reais(result.Total),
+		FaresPaid:     result.Fares,
+		Integrated:    result.Integrated(),
+		WindowMinutes: GlobalFares.WindowMinutes,
+		MaxBoardings:  GlobalFares.MaxBoardings,
+		Legs:          make([]types.FareLegResponse, len(result.Charges)),
+	}
+	for i, charge := range result.Charges {
+		response.Legs[i] = types.FareLegResponse{
+			Mode:       legs[i].Mode,
+			BoardAt:    boardAt[i],
+			Amount:     reais(charge.Amount),
+			Integrated: charge.Integrated,
+			Break:      charge.Break,
+		}
+	}
+	return response
+}
+
+// reais converts cents to reais
+func reais(cents int) float64 {
+	return float64(cents) / 100
+}
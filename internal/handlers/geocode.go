@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/geocode"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultGeocodeLimit  = 5
+	maxGeocodeLimit      = 50
+	defaultReverseRadius = 300
+	maxReverseRadius     = 2000
+)
+
+// GeocodeAddressParams defines the parameters for geocoding a free-text place
+type GeocodeAddressParams struct {
+	Query  string `json:"query" jsonschema:"A street address such as 'Av. Paulista, 1578', a stop name, or a latitude,longitude pair"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Maximum number of locations to return, defaults to 5 (max 50)"`
+	Format string `json:"format,omitempty"`
+}
+
+// Validate checks the geocode_address arguments
+func (p GeocodeAddressParams) Validate() error {
+	if strings.TrimSpace(p.Query) == "" {
+		return pipeline.Invalid("error.required", "query")
+	}
+	if p.Limit < 0 || p.Limit > maxGeocodeLimit {
+		return pipeline.Invalid("error.limit_range", maxGeocodeLimit)
+	}
+	return nil
+}
+
+// GeocodeAddress handles the geocode_address MCP tool
+func GeocodeAddress(ctx context.Context, call *pipeline.Call, args GeocodeAddressParams) (any, error) {
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultGeocodeLimit
+	}
+
+	found := GlobalCatalog.Gazetteer().Geocode(args.Query, limit)
+	results := make([]types.GeocodeResultResponse, len(found))
+	for i, r := range found {
+		results[i] = convertGeocodeResult(r)
+	}
+
+	return types.GeocodeAddressResponse{
+		Query:        args.Query,
+		TotalResults: len(results),
+		CatalogSize:  GlobalCatalog.StopCount(),
+		Results:      results,
+	}, nil
+}
+
+// ReverseGeocodeParams defines the parameters for describing a coordinate by its street
+type ReverseGeocodeParams struct {
+	Latitude     float64 `json:"latitude" jsonschema:"Latitude of the point"`
+	Longitude    float64 `json:"longitude" jsonschema:"Longitude of the point"`
+	RadiusMeters int     `json:"radius_meters,omitempty" jsonschema:"How far to look for a street, defaults to 300 (max 2000)"`
+	Format       string  `json:"format,omitempty"`
+}
+
+// Validate checks the reverse_geocode arguments
+func (p ReverseGeocodeParams) Validate() error {
+	if !geo.ValidCoordinate(p.Latitude, p.Longitude) {
+		return pipeline.Invalid("error.coordinate")
+	}
+	if p.RadiusMeters < 0 || p.RadiusMeters > maxReverseRadius {
+		return pipeline.Invalid("error.radius_range", maxReverseRadius)
+	}
+	return nil
+}
+
+// ReverseGeocode handles the reverse_geocode MCP tool
+func ReverseGeocode(ctx context.Context, call *pipeline.Call, args ReverseGeocodeParams) (any, error) {
+	radius := args.RadiusMeters
+	if radius == 0 {
+		radius = defaultReverseRadius
+	}
+
+	response := types.ReverseGeocodeResponse{
+		Latitude:    args.Latitude,
+		Longitude:   args.Longitude,
+		CatalogSize: GlobalCatalog.StopCount(),
+	}
+	loc, ok := GlobalCatalog.Gazetteer().Reverse(args.Latitude, args.Longitude, float64(radius))
+	if !ok {
+		return response, nil
+	}
+	response.Found = true
+	response.Street = loc.Street
+	response.Number = loc.Number
+	response.Precision = loc.Precision
+	response.StopCode = loc.Stop.Code
+	response.StopName = loc.Stop.Name
+	response.StopDistanceMeters = int(math.Round(loc.StopDistance))
+	response.CrossStreets = loc.CrossStreets
+	return response, nil
+}
+
+// resolvePlace returns the coordinate of a free-text place with the name it
+// matched, or the coordinate given when place is empty
+func resolvePlace(place string, lat, lon float64) (float64, float64, string, error) {
+	if place == "" {
+		return lat, lon, "", nil
+	}
+	found := GlobalCatalog.Gazetteer().Geocode(place, 1)
+	if len(found) == 0 {
+		return 0, 0, "", pipeline.Missing("error.place_not_found", place)
+	}
+	name := found[0].Name
+	if found[0].Number != 0 {
+		name = fmt.Sprintf("%s, %d", name, found[0].Number)
+	}
+	return found[0].Latitude, found[0].Longitude, name, nil
+}
+
+// validPlace checks that a tool locating a point got a place or a coordinate
+func validPlace(place string, lat, lon float64) error {
+	if place != "" {
+		return nil
+	}
+	if (lat == 0 && lon == 0) || !geo.ValidCoordinate(lat, lon) {
+		return pipeline.Invalid("error.coordinate_or_place")
+	}
+	return nil
+}
+
+// convertGeocodeResult converts a gazetteer result to GeocodeResultResponse
+func convertGeocodeResult(r geocode.Result) types.GeocodeResultResponse {
+	return types.GeocodeResultResponse{
+		Kind:      r.Kind,
+		Name:      r.Name,
+		Number:    r.Number,
+		Precision: r.Precision,
+		Latitude:  r.Latitude,
+		Longitude: r.Longitude,
+		Score:     math.Round(r.Score*100) / 100,
+		StopCode:  r.Stop.Code,
+		StopName:  r.Stop.Name,
+	}
+}
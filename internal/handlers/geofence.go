@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/geofence"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultGeofenceEventLimit = 50
+	maxGeofenceEventLimit     = 500
+)
+
+// CreateGeofenceParams defines the parameters for creating a geofence
+type CreateGeofenceParams struct {
+	Name             string             `json:"name" jsonschema:"A name for the watched area, e.g. Terminal Capelinha"`
+	Center           *types.Coordinate  `json:"center,omitempty" jsonschema:"Center of a circular area"`
+	Place            string             `json:"place,omitempty" jsonschema:"A street address or stop name to use as the center of a circular area"`
+	RadiusMeters     int                `json:"radius_meters,omitempty" jsonschema:"Radius of a circular area in meters (20-20000)"`
+	Polygon          []types.Coordinate `json:"polygon,omitempty" jsonschema:"Vertices of a polygonal area, in order (3-500), instead of a circle"`
+	LineCode         int                `json:"line_code,omitempty" jsonschema:"Only watch vehicles of this line code"`
+	LinePrefix       string             `json:"line_prefix,omitempty" jsonschema:"Only watch vehicles of lines whose identifier starts with this, e.g. 6450 for both directions"`
+	VehicleID        int                `json:"vehicle_id,omitempty" jsonschema:"Only watch this vehicle"`
+	HysteresisMeters int                `json:"hysteresis_meters,omitempty" jsonschema:"Distance a vehicle must cross past the boundary to count as entering or leaving, defaults to 25 (max 500)"`
+	Webhook          string             `json:"webhook,omitempty" jsonschema:"A localhost http or https URL that enter and exit events are posted to as JSON"`
+	Format           string             `json:"format,omitempty"`
+}
+
+// Validate checks the create_geofence arguments
+func (p CreateGeofenceParams) Validate() error {
+	if p.Name == "" {
+		return pipeline.Invalid("error.required", "name")
+	}
+	circle := p.Center != nil || p.Place != ""
+	if circle == (len(p.Polygon) > 0) {
+		return pipeline.Invalid("error.geofence_shape")
+	}
+	if circle && p.RadiusMeters == 0 {
+		return pipeline.Invalid("error.required", "radius_meters")
+	}
+	return validGeofenceFilters(p.LineCode, p.VehicleID, p.HysteresisMeters)
+}
+
+// UpdateGeofenceParams defines the parameters for changing a geofence
+type UpdateGeofenceParams struct {
+	ID               string             `json:"id" jsonschema:"The geofence to change"`
+	Name             string             `json:"name,omitempty" jsonschema:"A new name"`
+	Center           *types.Coordinate  `json:"center,omitempty" jsonschema:"A new center, making the area a circle"`
+	Place            string             `json:"place,omitempty" jsonschema:"A street address or stop name to use as the new center, making the area a circle"`
+	RadiusMeters     int                `json:"radius_meters,omitempty" jsonschema:"A new radius in meters (20-20000)"`
+	Polygon          []types.Coordinate `json:"polygon,omitempty" jsonschema:"New vertices, making the area a polygon"`
+	LineCode         *int               `json:"line_code,omitempty" jsonschema:"Only watch vehicles of this line code, or 0 to stop filtering by line code"`
+	LinePrefix       *string            `json:"line_prefix,omitempty" jsonschema:"Only watch vehicles of lines whose identifier starts with this, or an empty string to stop filtering by prefix"`
+	VehicleID        *int               `json:"vehicle_id,omitempty" jsonschema:"Only watch this vehicle, or 0 to watch every vehicle"`
+	HysteresisMeters int                `json:"hysteresis_meters,omitempty" jsonschema:"A new hysteresis distance in meters (max 500)"`
+	Webhook          *string            `json:"webhook,omitempty" jsonschema:"A new localhost webhook URL, or an empty string to stop posting events"`
+	Format           string             `json:"format,omitempty"`
+}
+
+// Validate checks the update_geofence arguments
+func (p UpdateGeofenceParams) Validate() error {
+	if p.ID == "" {
+		return pipeline.Invalid("error.required", "id")
+	}
+	if (p.Center != nil || p.Place != "") && len(p.Polygon) > 0 {
+		return pipeline.Invalid("error.geofence_shape")
+	}
+	var lineCode, vehicleID int
+	if p.LineCode != nil {
+		lineCode = *p.LineCode
+	}
+	if p.VehicleID != nil {
+		vehicleID = *p.VehicleID
+	}
+	return validGeofenceFilters(lineCode, vehicleID, p.HysteresisMeters)
+}
+
+// validGeofenceFilters checks the filters shared by the geofence tools
+func validGeofenceFilters(lineCode, vehicleID, hysteresis int) error {
+	if lineCode < 0 {
+		return pipeline.Invalid("error.positive_integer", "line_code")
+	}
+	if vehicleID < 0 {
+		return pipeline.Invalid("error.positive_integer", "vehicle_id")
+	}
+	if hysteresis < 0 {
+		return pipeline.Invalid("error.positive_integer", "hysteresis_meters")
+	}
+	return nil
+}
+
+// GeofenceIDParams defines the parameters of the tools acting on one geofence
+type GeofenceIDParams struct {
+	ID     string `json:"id" jsonschema:"The geofence identifier"`
+	Format string `json:"format,omitempty"`
+}
+
+// Validate checks the geofence identifier
+func (p GeofenceIDParams) Validate() error {
+	if p.ID == "" {
+		return pipeline.Invalid("error.required", "id")
+	}
+	return nil
+}
+
+// ListGeofencesParams defines the parameters for listing geofences
+type ListGeofencesParams struct {
+	Format string `json:"format,omitempty"`
+}
+
+// GetGeofenceEventsParams defines the parameters for listing recent geofence events
+type GetGeofenceEventsParams struct {
+	ID     string `json:"id,omitempty" jsonschema:"Only events of this geofence"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Maximum number of events to return, defaults to 50 (max 500)"`
+	Format string `json:"format,omitempty"`
+}
+
+// Validate checks the get_geofence_events arguments
+func (p GetGeofenceEventsParams) Validate() error {
+	if p.Limit < 0 || p.Limit > maxGeofenceEventLimit {
+		return pipeline.Invalid("error.limit_range", maxGeofenceEventLimit)
+	}
+	return nil
+}
+
+// CreateGeofence handles the create_geofence MCP tool
+func CreateGeofence(ctx context.Context, call *pipeline.Call, args CreateGeofenceParams) (any, error) {
+	if GlobalGeofences == nil {
+		return nil, pipeline.Invalid("error.geofences_disabled")
+	}
+	f := geofence.Fence{
+		Name:       args.Name,
+		LineCode:   args.LineCode,
+		LinePrefix: args.LinePrefix,
+		VehicleID:  args.VehicleID,
+		Hysteresis: float64(args.HysteresisMeters),
+		Webhook:    args.Webhook,
+	}
+	if err := setGeofenceShape(&f, args.Center, args.Place, args.RadiusMeters, args.Polygon); err != nil {
+		return nil, err
+	}
+
+	f, err := GlobalGeofences.Add(f)
+	if err != nil {
+		return nil, pipeline.Invalid("error.invalid_geofence", err)
+	}
+	return convertGeofence(f), nil
+}
+
+// UpdateGeofence handles the update_geofence MCP tool
+func UpdateGeofence(ctx context.Context, call *pipeline.Call, args UpdateGeofenceParams) (any, error) {
+	if GlobalGeofences == nil {
+		return nil, pipeline.Invalid("error.geofences_disabled")
+	}
+	current, ok := GlobalGeofences.Get(args.ID)
+	if !ok {
+		return nil, pipeline.Missing("error.unknown_geofence", args.ID)
+	}
+
+	// Resolve a new center before taking the watcher lock
+	radius := args.RadiusMeters
+	if radius == 0 {
+		radius = int(current.Radius)
+	}
+	changed := current
+	if args.Center != nil || args.Place != "" || len(args.Polygon) > 0 {
+		if err := setGeofenceShape(&changed, args.Center, args.Place, radius, args.Polygon); err != nil {
+			return nil, err
+		}
+	} else if args.RadiusMeters != 0 {
+		changed.Radius = float64(args.RadiusMeters)
+	}
+
+	f, ok, err := GlobalGeofences.Update(args.ID, func(f *geofence.Fence) {
+		f.Shape, f.Center, f.Radius, f.Polygon = changed.Shape, changed.Center, changed.Radius, changed.Polygon
+		if args.Name != "" {
+			f.Name = args.Name
+		}
+		// Filters and the webhook given as zero or empty are cleared
+		if args.LineCode != nil {
+			f.LineCode = *args.LineCode
+		}
+		if args.LinePrefix != nil {
+			f.LinePrefix = *args.LinePrefix
+		}
+		if args.VehicleID != nil {
+			f.VehicleID = *args.VehicleID
+		}
+		if args.HysteresisMeters != 0 {
+			f.Hysteresis = float64(args.HysteresisMeters)
+		}
+		if args.Webhook != nil {
+			f.Webhook = *args.Webhook
+		}
+	})
+	if !ok {
+		return nil, pipeline.Missing("error.unknown_geofence", args.ID)
+	}
+	if err != nil {
+		return nil, pipeline.Invalid("error.invalid_geofence", err)
+	}
+	return convertGeofence(f), nil
+}
+
+// DeleteGeofence handles the delete_geofence MCP tool
+func DeleteGeofence(ctx context.Context, call *pipeline.Call, args GeofenceIDParams) (any, error) {
+	if GlobalGeofences == nil {
+		return nil, pipeline.Invalid("error.geofences_disabled")
+	}
+	if !GlobalGeofences.Remove(args.ID) {
+		return nil, pipeline.Missing("error.unknown_geofence", args.ID)
+	}
+	return types.DeleteGeofenceResponse{ID: args.ID, Deleted: true}, nil
+}
+
+// ListGeofences handles the list_geofences MCP tool
+func ListGeofences(ctx context.Context, call *pipeline.Call, args ListGeofencesParams) (any, error) {
+	if GlobalGeofences == nil {
+		return nil, pipeline.Invalid("error.geofences_disabled")
+	}
+	status := GlobalGeofences.Status()
+	response := types.ListGeofencesResponse{
+		IntervalSeconds: int(status.Interval.Seconds()),
+		LastSnapshot:    status.LastSnapshot,
+		LastError:       status.LastError,
+		Fences:          []types.GeofenceResponse{},
+	}
+	for _, f := range GlobalGeofences.List() {
+		response.Fences = append(response.Fences, convertGeofence(f))
+	}
+	response.TotalFences = len(response.Fences)
+	return response, nil
+}
+
+// GetGeofenceEvents handles the get_geofence_events MCP tool
+func GetGeofenceEvents(ctx context.Context, call *pipeline.Call, args GetGeofenceEventsParams) (any, error) {
+	if GlobalGeofences == nil {
+		return nil, pipeline.Invalid("error.geofences_disabled")
+	}
+	if args.ID != "" {
+		if _, ok := GlobalGeofences.Get(args.ID); !ok {
+			return nil, pipeline.Missing("error.unknown_geofence", args.ID)
+		}
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultGeofenceEventLimit
+	}
+
+	response := types.GeofenceEventsResponse{FenceID: args.ID, Events: []types.GeofenceEventResponse{}}
+	for _, event := range GlobalGeofences.Events(args.ID, limit) {
+		response.Events = append(response.Events, convertGeofenceEvent(event))
+	}
+	response.TotalEvents = len(response.Events)
+	return response, nil
+}
+
+// GeofenceNotifier returns a notifier sending geofence events to every
+// connected session as log messages, in the locale of each session. The SDK
+// drops log messages until a session sets its level with logging/setLevel,
+// so sessions that never do only see events through get_geofence_events and
+// webhooks.
+func GeofenceNotifier(server *mcp.Server) geofence.Notifier {
+	return func(ctx context.Context, event geofence.Event) {
+		for ss := range server.Sessions() {
+			message := i18n.T(SessionLocale(ss), "render.geofence_"+event.Kind,
+				event.LineIdentifier, event.VehicleID, event.FenceName, event.Time.Format("15:04:05"))
+			err := ss.Log(ctx, &mcp.LoggingMessageParams{
+				Level:  "notice",
+				Logger: "geofence",
+				Data: map[string]any{
+					"message": message,
+					"event":   convertGeofenceEvent(event),
+				},
+			})
+			if err != nil {
+				log.Printf("Failed to notify geofence event: %v", err)
+			}
+		}
+	}
+}
+
+// setGeofenceShape makes the fence a circle around a center or geocoded
+// place, or a polygon
+func setGeofenceShape(f *geofence.Fence, center *types.Coordinate, place string, radius int, polygon []types.Coordinate) error {
+	if len(polygon) > 0 {
+		f.Shape, f.Polygon, f.Center, f.Radius = geofence.Polygon, polygon, types.Coordinate{}, 0
+		return nil
+	}
+	f.Shape, f.Polygon, f.Radius = geofence.Circle, nil, float64(radius)
+	if center != nil {
+		f.Center = *center
+		return nil
+	}
+	lat, lon, _, err := resolvePlace(place, 0, 0)
+	if err != nil {
+		return err
+	}
+	f.Center = types.Coordinate{Latitude: lat, Longitude: lon}
+	return nil
+}
+
+// convertGeofence converts a fence to GeofenceResponse
+func convertGeofence(f geofence.Fence) types.GeofenceResponse {
+	response := types.GeofenceResponse{
+		ID:               f.ID,
+		Name:             f.Name,
+		Shape:            f.Shape,
+		Polygon:          f.Polygon,
+		LineCode:         f.LineCode,
+		LinePrefix:       f.LinePrefix,
+		VehicleID:        f.VehicleID,
+		HysteresisMeters: int(math.Round(f.Hysteresis)),
+		Webhook:          f.Webhook,
+		CreatedAt:        formatTime(f.CreatedAt),
+		VehiclesInside:   GlobalGeofences.Inside(f.ID),
+	}
+	if f.Shape == geofence.Circle {
+		center := f.Center
+		response.Center = &center
+		response.RadiusMeters = int(math.Round(f.Radius))
+	}
+	return response
+}
+
+// convertGeofenceEvent converts a geofence event to GeofenceEventResponse
+func convertGeofenceEvent(event geofence.Event) types.GeofenceEventResponse {
+	return types.GeofenceEventResponse{
+		FenceID:        event.FenceID,
+		FenceName:      event.FenceName,
+		Event:          event.Kind,
+		VehicleID:      event.VehicleID,
+		LineIdentifier: event.LineIdentifier,
+		LineCode:       event.LineCode,
+		Latitude:       event.Latitude,
+		Longitude:      event.Longitude,
+		Time:           event.Time.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/journey"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	maxIsochroneMinutes = 90
+	maxIsochroneBudgets = 6
+	defaultReachable    = 50
+	maxReachable        = 1000
+	isochroneBearings   = 72 // Vertices of each polygon
+)
+
+// defaultIsochroneMinutes are the time budgets used when none are given
+var defaultIsochroneMinutes = []int{10, 20, 30}
+
+// IsochroneParams defines the parameters for finding what can be reached from a point
+type IsochroneParams struct {
+	FromStopCode  Ref               `json:"from_stop_code,omitempty" jsonschema:"Start at this stop: a stop code, a stop name or an address"`
+	From          *types.Coordinate `json:"from,omitempty" jsonschema:"Start at this coordinate"`
+	FromPlace     string            `json:"from_place,omitempty" jsonschema:"Start at this street address or stop name"`
+	Minutes       []int             `json:"minutes,omitempty" jsonschema:"Time budgets in minutes (1-90, up to 6), defaults to 10, 20 and 30"`
+	MaxTransfers  *int              `json:"max_transfers,omitempty" jsonschema:"Maximum number of changes between buses (0-2), defaults to 2"`
+	MaxWalkMeters int               `json:"max_walk_meters,omitempty" jsonschema:"Maximum straight-line distance walked to the first stop and from the last stop, defaults to 800 (100-2000)"`
+	Polygons      bool              `json:"polygons,omitempty" jsonschema:"Include the outline of the area reached within each budget, rendered as GeoJSON polygons with the geojson format"`
+	Limit         int               `json:"limit,omitempty" jsonschema:"Maximum number of stops to return, defaults to 50 (max 1000)"`
+	Format        string            `json:"format,omitempty"`
+}
+
+// Validate checks the isochrone arguments
+func (p IsochroneParams) Validate() error {
+	if err := validEndpoint("from", p.FromStopCode, p.From, p.FromPlace); err != nil {
+		return err
+	}
+	if len(p.Minutes) > maxIsochroneBudgets {
+		return pipeline.Invalid("error.isochrone_minutes", maxIsochroneMinutes, maxIsochroneBudgets)
+	}
+	for _, minutes := range p.Minutes {
+		if minutes < 1 || minutes > maxIsochroneMinutes {
+			return pipeline.Invalid("error.isochrone_minutes", maxIsochroneMinutes, maxIsochroneBudgets)
+		}
+	}
+	if p.MaxTransfers != nil && (*p.MaxTransfers < 0 || *p.MaxTransfers > maxTripTransfers) {
+		return pipeline.Invalid("error.transfers_range", maxTripTransfers)
+	}
+	if p.MaxWalkMeters != 0 && (p.MaxWalkMeters < minTripWalk || p.MaxWalkMeters > maxTripWalk) {
+		return pipeline.Invalid("error.walk_range", minTripWalk, maxTripWalk)
+	}
+	if p.Limit < 0 || p.Limit > maxReachable {
+		return pipeline.Invalid("error.limit_range", maxReachable)
+	}
+	return nil
+}
+
+// Isochrone handles the isochrone MCP tool
+func Isochrone(ctx context.Context, call *pipeline.Call, args IsochroneParams) (any, error) {
+	opts := journey.Options{
+		MaxTransfers: defaultTripTransfers,
+		MaxWalk:      defaultTripWalk,
+		TransferWalk: tripTransferWalk,
+		Walker:       GlobalWalker,
+	}
+	if args.MaxTransfers != nil {
+		opts.MaxTransfers = *args.MaxTransfers
+	}
+	if args.MaxWalkMeters != 0 {
+		opts.MaxWalk = float64(args.MaxWalkMeters)
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultReachable
+	}
+	budgets := budgetMinutes(args.Minutes)
+
+	from, err := resolveEndpoint(ctx, "from", args.FromStopCode, args.From, args.FromPlace)
+	if err != nil {
+		return nil, err
+	}
+
+	network := GlobalCatalog.Network()
+	response := types.IsochroneResponse{
+		Origin:       from,
+		Departure:    time.Now().In(saoPaulo).Format("15:04"),
+		NetworkLines: network.Lines(),
+		Budgets:      []types.IsochroneBudgetResponse{},
+		Stops:        []types.ReachableStopResponse{},
+	}
+	if response.NetworkLines == 0 {
+		return response, nil
+	}
+
+	// Count the running vehicles of each line and measure their speeds
+	vehicles := make(map[int]int)
+	var lines []types.VehicleLine
+	hour, err := GlobalClient.StreamVehiclePositions(ctx, func(line types.VehicleLine) {
+		vehicles[line.Code] += len(line.Vehicles)
+		lines = append(lines, line)
+	})
+	if err != nil {
+		return nil, pipeline.Failed("error.isochrone", err)
+	}
+	GlobalSpeeds.Observe(lines)
+	response.Timestamp = hour
+	for code, count := range vehicles {
+		if count == 0 {
+			continue
+		}
+		response.RunningLines++
+		if _, measured := GlobalSpeeds.Speed(types.Line{Code: code}); measured {
+			response.MeasuredLines++
+		}
+	}
+
+	origin := geo.Point{Latitude: from.Latitude, Longitude: from.Longitude}
+	budget := time.Duration(budgets[len(budgets)-1]) * time.Minute
+	reached := network.Reach(origin, budget, opts, journey.Conditions{
+		Speed: func(line types.Line) float64 {
+			speed, _ := GlobalSpeeds.Speed(line)
+			return speed
+		},
+		Vehicles: func(line types.Line) int {
+			return vehicles[line.Code]
+		},
+	})
+
+	for _, minutes := range budgets {
+		cutoff := time.Duration(minutes) * time.Minute
+		band := types.IsochroneBudgetResponse{Minutes: minutes}
+		circles := []geo.Circle{{Center: origin, Radius: min(opts.Walker.Reach(cutoff.Seconds()), opts.MaxWalk)}}
+		for _, r := range reached {
+			if r.Arrival > cutoff {
+				break
+			}
+			band.Stops++
+			circles = append(circles, geo.Circle{
+				Center: geo.Point{Latitude: r.Stop.Latitude, Longitude: r.Stop.Longitude},
+				Radius: min(opts.Walker.Reach((cutoff - r.Arrival).Seconds()), opts.MaxWalk),
+			})
+		}
+		if args.Polygons {
+			for _, p := range geo.Outline(origin, circles, isochroneBearings) {
+				band.Polygon = append(band.Polygon, types.Coordinate{Latitude: p.Latitude, Longitude: p.Longitude})
+			}
+		}
+		response.Budgets = append(response.Budgets, band)
+	}
+
+	response.TotalStops = len(reached)
+	for _, r := range reached {
+		if len(response.Stops) == limit {
+			break
+		}
+		arrival := int(math.Ceil(r.Arrival.Minutes()))
+		within := budgets[sort.SearchInts(budgets, arrival)]
+		response.Stops = append(response.Stops, types.ReachableStopResponse{
+			Code:          r.Stop.Code,
+			Name:          r.Stop.Name,
+			Latitude:      r.Stop.Latitude,
+			Longitude:     r.Stop.Longitude,
+			Minutes:       arrival,
+			WithinMinutes: within,
+			Rides:         r.Rides,
+		})
+	}
+	response.TotalResults = len(response.Stops)
+	return response, nil
+}
+
+// budgetMinutes returns the given time budgets sorted and without
+// duplicates, or the defaults when none are given
+func budgetMinutes(minutes []int) []int {
+	if len(minutes) == 0 {
+		return defaultIsochroneMinutes
+	}
+	sorted := append([]int(nil), minutes...)
+	sort.Ints(sorted)
+	budgets := sorted[:1]
+	for _, m := range sorted[1:] {
+		if m != budgets[len(budgets)-1] {
+			budgets = append(budgets, m)
+		}
+	}
+	return budgets
+}
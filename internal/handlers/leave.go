@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultLeaveMargin  = 2
+	maxLeaveMargin      = 15
+	defaultLeaveOptions = 5
+	maxLeaveOptions     = 20
+	maxLeaveWalk        = 5000.0           // Straight-line meters beyond which a stop is too far to walk to
+	freshPosition       = 90 * time.Second // Positions up to this age give a high confidence
+	stalePosition       = 5 * time.Minute  // Positions older than this give a low confidence
+)
+
+// Confidence levels of a leave advice
+const (
+	confidenceHigh   = "high"
+	confidenceMedium = "medium"
+	confidenceLow    = "low"
+)
+
+// LeaveAdviceParams defines the parameters for advising when to leave for a stop
+type LeaveAdviceParams struct {
+	From           *types.Coordinate `json:"from,omitempty" jsonschema:"Where the rider is"`
+	FromPlace      string            `json:"from_place,omitempty" jsonschema:"Where the rider is: a place saved with set_preferences such as home, a street address or a stop name"`
+	StopCode       Ref               `json:"stop_code" jsonschema:"The stop to catch the bus at: a stop code, a stop name or an address"`
+	Lines          []Ref             `json:"lines,omitempty" jsonschema:"Only catch these lines: line codes, signs such as 8000-10, numbers such as 8000 or search terms"`
+	SpeedKmh       float64           `json:"speed_kmh,omitempty" jsonschema:"Walking speed in km/h (1-10), defaults to the server setting"`
+	MarginMinutes  *int              `json:"margin_minutes,omitempty" jsonschema:"Minutes to be at the stop before the bus arrives (0-15), defaults to 2"`
+	AccessibleOnly *bool             `json:"accessible_only,omitempty" jsonschema:"Only catch wheelchair-accessible vehicles, defaults to the session setting"`
+	Limit          int               `json:"limit,omitempty" jsonschema:"Maximum number of buses to list, defaults to 5 (max 20)"`
+	Format         string            `json:"format,omitempty"`
+}
+
+// Validate checks the leave_advice arguments
+func (p LeaveAdviceParams) Validate() error {
+	if (p.From != nil) == (p.FromPlace != "") {
+		return pipeline.Invalid("error.leave_origin")
+	}
+	if p.From != nil && !geo.ValidCoordinate(p.From.Latitude, p.From.Longitude) {
+		return pipeline.Invalid("error.coordinate")
+	}
+	if err := validStopRef("stop_code", p.StopCode); err != nil {
+		return err
+	}
+	if err := validLineRefs("lines", p.Lines); err != nil {
+		return err
+	}
+	if p.SpeedKmh != 0 && (p.SpeedKmh < minWalkingSpeed || p.SpeedKmh > maxWalkingSpeed) {
+		return pipeline.Invalid("error.speed_range", minWalkingSpeed, maxWalkingSpeed)
+	}
+	if p.MarginMinutes != nil && (*p.MarginMinutes < 0 || *p.MarginMinutes > maxLeaveMargin) {
+		return pipeline.Invalid("error.margin_range", maxLeaveMargin)
+	}
+	if p.Limit < 0 || p.Limit > maxLeaveOptions {
+		return pipeline.Invalid("error.limit_range", maxLeaveOptions)
+	}
+	return nil
+}
+
+// LeaveAdvice handles the leave_advice MCP tool
+func LeaveAdvice(ctx context.Context, call *pipeline.Call, args LeaveAdviceParams) (any, error) {
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultLeaveOptions
+	}
+	margin := defaultLeaveMargin
+	if args.MarginMinutes != nil {
+		margin = *args.MarginMinutes
+	}
+	walker := GlobalWalker
+	if args.SpeedKmh != 0 {
+		walker.Speed = args.SpeedKmh / 3.6
+	}
+
+	origin, err := riderPlace(call, args.From, args.FromPlace)
+	if err != nil {
+		return nil, err
+	}
+	stop, err := resolveStopRef(ctx, "stop_code", args.StopCode)
+	if err != nil {
+		return nil, err
+	}
+	predictions, err := GlobalClient.GetArrivalPredictionsByStop(ctx, stop.Code)
+	if err != nil {
+		return nil, pipeline.Failed("error.leave_advice", err)
+	}
+	lines, err := resolveBoardLines(ctx, "lines", args.Lines, stop.Code, predictions)
+	if err != nil {
+		return nil, err
+	}
+	// Count down from the time of the predictions, as the departure board
+	// does, and age vehicle positions by the wall clock
+	clock := time.Now().In(saoPaulo)
+	now := snapshotTime(predictions.Hour, clock)
+
+	// Stops missing from the catalog are located by their predictions
+	for _, s := range predictions.Stops {
+		if stop.Latitude == 0 && stop.Longitude == 0 {
+			stop.Latitude, stop.Longitude = s.Latitude, s.Longitude
+		}
+		if stop.Name == "" {
+			stop.Name = s.Name
+		}
+	}
+	if stop.Latitude == 0 && stop.Longitude == 0 {
+		return nil, pipeline.Missing("error.stop_location", stop.Code)
+	}
+	distance := geo.Distance(origin.Latitude, origin.Longitude, stop.Latitude, stop.Longitude)
+	if distance > maxLeaveWalk {
+		return nil, pipeline.Invalid("error.leave_too_far", distance/1000)
+	}
+	walk := time.Duration(walker.Time(distance) * float64(time.Second))
+
+	response := types.LeaveAdviceResponse{
+		Timestamp:     predictions.Hour,
+		Now:           now.Format("15:04"),
+		Origin:        origin,
+		StopCode:      stop.Code,
+		StopName:      stop.Name,
+		WalkMeters:    int(math.Round(walker.Distance(distance))),
+		WalkMinutes:   int(math.Ceil(walk.Minutes())),
+		MarginMinutes: margin,
+		Options:       []types.LeaveOptionResponse{},
+	}
+	board := buildBoard(ctx, stop.Code, predictions, boardFilter{
+		lines:          lines,
+		accessibleOnly: accessibleOnly(call, args.AccessibleOnly),
+	}, maxDepartures)
+	for _, d := range board.Departures {
+		until, ok := untilClock(now, d.ArrivalTime)
+		if !ok {
+			continue
+		}
+		leaveIn := until - walk - time.Duration(margin)*time.Minute
+		if leaveIn < 0 {
+			response.Missed++
+			continue
+		}
+		if len(response.Options) == limit {
+			continue
+		}
+		age := clock.Sub(d.LastUpdate)
+		response.Options = append(response.Options, types.LeaveOptionResponse{
+			DepartureResponse:  d,
+			LeaveBy:            now.Add(leaveIn).Format("15:04"),
+			LeaveInMinutes:     int(leaveIn.Minutes()),
+			PositionAgeSeconds: max(int(age.Seconds()), 0),
+			Confidence:         confidence(age),
+		})
+	}
+	if len(response.Options) > 0 {
+		catch := response.Options[0]
+		response.Catch = &catch
+	}
+	response.TotalResults = len(response.Options)
+	return response, nil
+}
+
+// confidence grades a prediction by the age of the vehicle position it is based on
+func confidence(age time.Duration) string {
+	switch {
+	case age <= freshPosition:
+		return confidenceHigh
+	case age <= stalePosition:
+		return confidenceMedium
+	}
+	return confidenceLow
+}
+
+// riderPlace locates where a rider is from a coordinate, or from a place
+// saved in the session or located by the gazetteer, returning its name
+func riderPlace(call *pipeline.Call, coordinate *types.Coordinate, place string) (types.WalkEndpointResponse, error) {
+	if coordinate != nil {
+		return types.WalkEndpointResponse{Latitude: coordinate.Latitude, Longitude: coordinate.Longitude}, nil
+	}
+	var ss *mcp.ServerSession
+	if call != nil {
+		ss = call.Session
+	}
+	if saved, ok := Sessions.Get(ss).Place(place); ok {
+		return types.WalkEndpointResponse{Name: saved.Name, Latitude: saved.Latitude, Longitude: saved.Longitude}, nil
+	}
+	lat, lon, name, err := resolvePlace(place, 0, 0)
+	if err != nil {
+		return types.WalkEndpointResponse{}, err
+	}
+	return types.WalkEndpointResponse{Name: name, Latitude: lat, Longitude: lon}, nil
+}
@@ -2,94 +2,81 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"strings"
 
-	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
 	"github.com/thunderjr/sptrans-mcp/internal/types"
 )
 
 // SearchLinesParams defines the parameters for searching lines
 type SearchLinesParams struct {
 	SearchTerm string `json:"search_term" jsonschema:"The line name or number to search for (partial or complete)"`
+	Format     string `json:"format,omitempty"`
+}
+
+// Validate checks the search_lines arguments
+func (p SearchLinesParams) Validate() error {
+	if p.SearchTerm == "" {
+		return pipeline.Invalid("error.required", "search_term")
+	}
+	return nil
 }
 
 // SearchLineByDirectionParams defines the parameters for searching lines by direction
 type SearchLineByDirectionParams struct {
 	SearchTerm string `json:"search_term" jsonschema:"The line code or identifier to search for"`
-	Direction  int    `json:"direction" jsonschema:"The direction to search for (1 or 2)"`
+	Direction  int    `json:"direction,omitempty" jsonschema:"The direction to search for (1 or 2), required unless towards is given"`
+	Towards    string `json:"towards,omitempty" jsonschema:"Where the rider is heading, in place of direction: a terminal such as Pq. D. Pedro II or a stop on the way"`
+	Format     string `json:"format,omitempty"`
 }
 
-// SearchLines handles the search_lines MCP tool
-func SearchLines(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SearchLinesParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.SearchTerm == "" {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "search_term parameter is required"}},
-		}, nil
+// Validate checks the search_line_by_direction arguments
+func (p SearchLineByDirectionParams) Validate() error {
+	if p.SearchTerm == "" {
+		return pipeline.Invalid("error.required", "search_term")
 	}
-
-	lines, err := GlobalClient.SearchLines(ctx, params.Arguments.SearchTerm)
-	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to search lines: %v", err)}},
-		}, nil
+	if p.Towards != "" {
+		if p.Direction != 0 {
+			return pipeline.Invalid("error.direction_or_towards")
+		}
+		return nil
 	}
+	if p.Direction != 1 && p.Direction != 2 {
+		return pipeline.Invalid("error.direction")
+	}
+	return nil
+}
 
-	response := types.BuildSearchLinesResponse(len(lines), params.Arguments.SearchTerm, lines)
-
-	responseJSON, err := json.Marshal(response)
+// SearchLines handles the search_lines MCP tool
+func SearchLines(ctx context.Context, call *pipeline.Call, args SearchLinesParams) (any, error) {
+	lines, err := GlobalClient.SearchLines(ctx, args.SearchTerm)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.search_lines", err)
 	}
+	GlobalCatalog.AddLines(lines)
 
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
+	return types.BuildSearchLinesResponse(len(lines), args.SearchTerm, lines), nil
 }
 
 // SearchLineByDirection handles the search_line_by_direction MCP tool
-func SearchLineByDirection(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SearchLineByDirectionParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.SearchTerm == "" {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "search_term parameter is required"}},
-		}, nil
-	}
-
-	if params.Arguments.Direction != 1 && params.Arguments.Direction != 2 {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "direction parameter must be 1 or 2"}},
-		}, nil
-	}
-
-	lines, err := GlobalClient.SearchLineByDirection(ctx, params.Arguments.SearchTerm, params.Arguments.Direction)
-	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to search line by direction: %v", err)}},
-		}, nil
+func SearchLineByDirection(ctx context.Context, call *pipeline.Call, args SearchLineByDirectionParams) (any, error) {
+	if args.Towards != "" {
+		lines, err := lineDirections(ctx, strings.TrimSpace(args.SearchTerm))
+		if err != nil {
+			return nil, err
+		}
+		best, err := resolveTowards(ctx, lines, args.Towards)
+		if err != nil {
+			return nil, err
+		}
+		return types.BuildSearchLinesResponse(1, args.SearchTerm, []types.Line{best.Line}), nil
 	}
 
-	response := types.BuildSearchLinesResponse(len(lines), params.Arguments.SearchTerm, lines)
-
-	responseJSON, err := json.Marshal(response)
+	lines, err := GlobalClient.SearchLineByDirection(ctx, args.SearchTerm, args.Direction)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.search_line_by_direction", err)
 	}
+	GlobalCatalog.AddLines(lines)
 
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
+	return types.BuildSearchLinesResponse(len(lines), args.SearchTerm, lines), nil
 }
-
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/mapdraw"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/render"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultMapWidth  = 800
+	defaultMapHeight = 600
+	minMapSize       = 200
+	maxMapSize       = 2000
+)
+
+// RenderMapParams defines the parameters for rendering a line map
+type RenderMapParams struct {
+	LineCode          Ref  `json:"line_code" jsonschema:"The line to draw: a line code, a sign such as 875A-10 with an optional direction such as 875A-10/1, or a search term"`
+	HighlightStopCode Ref  `json:"highlight_stop_code,omitempty" jsonschema:"A stop to highlight on the map: a stop code, a stop name or an address"`
+	HideVehicles      bool `json:"hide_vehicles,omitempty" jsonschema:"Draw only the stops, without live vehicles"`
+	Width             int  `json:"width,omitempty" jsonschema:"Image width in pixels, defaults to 800 (200-2000)"`
+	Height            int  `json:"height,omitempty" jsonschema:"Image height in pixels, defaults to 600 (200-2000)"`
+}
+
+// Validate checks the render_map arguments
+func (p RenderMapParams) Validate() error {
+	if err := validLineRef("line_code", p.LineCode); err != nil {
+		return err
+	}
+	if p.HighlightStopCode != nil {
+		if err := validStopRef("highlight_stop_code", p.HighlightStopCode); err != nil {
+			return err
+		}
+	}
+	for _, size := range []int{p.Width, p.Height} {
+		if size != 0 && (size < minMapSize || size > maxMapSize) {
+			return pipeline.Invalid("error.map_size", minMapSize, maxMapSize)
+		}
+	}
+	return nil
+}
+
+// RenderMap handles the render_map MCP tool
+func RenderMap(ctx context.Context, call *pipeline.Call, args RenderMapParams) (any, error) {
+	locale := SessionLocale(call.Session)
+
+	var highlight types.Stop
+	if args.HighlightStopCode != nil {
+		stop, err := resolveStopRef(ctx, "highlight_stop_code", args.HighlightStopCode)
+		if err != nil {
+			return nil, err
+		}
+		highlight = stop
+	}
+	line, err := resolveLineRef(ctx, "line_code", args.LineCode, highlight.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	stops, err := GlobalClient.GetStopsByLine(ctx, line.Code)
+	if err != nil {
+		return nil, pipeline.Failed("error.render_map", err)
+	}
+	if len(stops) == 0 {
+		return nil, pipeline.Missing("error.no_stops", line.Code)
+	}
+	GlobalCatalog.SetLineStops(line.Code, stops)
+
+	m := mapdraw.Map{
+		Width:  args.Width,
+		Height: args.Height,
+		Labels: mapdraw.Labels{
+			Title:      i18n.T(locale, "render.map_line", line.Code),
+			Stop:       i18n.T(locale, "render.map_stop"),
+			Highlight:  i18n.T(locale, "render.map_highlight"),
+			Vehicle:    i18n.T(locale, "render.map_vehicle"),
+			Accessible: i18n.T(locale, "render.map_accessible"),
+		},
+	}
+	if m.Width == 0 {
+		m.Width = defaultMapWidth
+	}
+	if m.Height == 0 {
+		m.Height = defaultMapHeight
+	}
+	if line.Number != "" {
+		m.Labels.Title = line.Sign() + " → " + line.Headsign()
+	}
+
+	response := types.RenderMapResponse{
+		LineCode: line.Code,
+		Title:    m.Labels.Title,
+		Width:    m.Width,
+		Height:   m.Height,
+		Stops:    len(stops),
+	}
+
+	for _, stop := range stops {
+		m.Stops = append(m.Stops, mapdraw.Point{Latitude: stop.Latitude, Longitude: stop.Longitude})
+		if stop.Code == highlight.Code {
+			m.Highlight = &mapdraw.Point{Latitude: stop.Latitude, Longitude: stop.Longitude}
+		}
+	}
+	if highlight.Code != 0 && m.Highlight == nil {
+		stop, ok := GlobalCatalog.Stop(highlight.Code)
+		if !ok {
+			return nil, pipeline.Missing("error.unknown_stop", highlight.Code)
+		}
+		m.Highlight = &mapdraw.Point{Latitude: stop.Latitude, Longitude: stop.Longitude}
+	}
+	if m.Highlight != nil {
+		response.HighlightStopCode = highlight.Code
+	}
+
+	if !args.HideVehicles {
+		positions, err := GlobalClient.GetVehiclePositionsByLine(ctx, line.Code)
+		if err != nil {
+			return nil, pipeline.Failed("error.render_map", err)
+		}
+		response.Timestamp = positions.Hour
+		for _, line := range positions.Lines {
+			for _, vehicle := range line.Vehicles {
+				m.Vehicles = append(m.Vehicles, mapdraw.Vehicle{
+					Point:      mapdraw.Point{Latitude: vehicle.Latitude, Longitude: vehicle.Longitude},
+					Accessible: vehicle.Accessible,
+				})
+				response.Vehicles++
+				if vehicle.Accessible {
+					response.AccessibleVehicles++
+				}
+			}
+		}
+	}
+
+	data, info, err := m.PNG()
+	if err != nil {
+		return nil, err
+	}
+	response.ScaleMeters = info.ScaleMeters
+	response.BoundingBox = types.BoundingBox{South: info.South, West: info.West, North: info.North, East: info.East}
+
+	text, err := render.Render(response, render.Compact, locale)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{
+			&mcp.ImageContent{Data: data, MIMEType: "image/png"},
+			&mcp.TextContent{Text: text},
+		},
+		StructuredContent: response,
+	}, nil
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultNearbyRadius       = 500
+	maxNearbyRadius           = 5000
+	defaultNearbyLimit        = 10
+	maxNearbyLimit            = 100
+	defaultNearbyVehicleLimit = 20
+	maxNearbyVehicleLimit     = 200
+)
+
+// FindStopsNearParams defines the parameters for finding stops near a point
+type FindStopsNearParams struct {
+	Latitude     float64 `json:"latitude,omitempty" jsonschema:"Latitude of the point, required unless place is given"`
+	Longitude    float64 `json:"longitude,omitempty" jsonschema:"Longitude of the point, required unless place is given"`
+	Place        string  `json:"place,omitempty" jsonschema:"A street address such as 'Av. Paulista, 1578' or a stop name, in place of latitude and longitude"`
+	RadiusMeters int     `json:"radius_meters,omitempty" jsonschema:"Search radius in meters, defaults to 500 (max 5000)"`
+	Limit        int     `json:"limit,omitempty" jsonschema:"Maximum number of stops to return, defaults to 10 (max 100)"`
+	Format       string  `json:"format,omitempty"`
+}
+
+// Validate checks the find_stops_near arguments
+func (p FindStopsNearParams) Validate() error {
+	if err := validPlace(p.Place, p.Latitude, p.Longitude); err != nil {
+		return err
+	}
+	if p.RadiusMeters < 0 || p.RadiusMeters > maxNearbyRadius {
+		return pipeline.Invalid("error.radius_range", maxNearbyRadius)
+	}
+	if p.Limit < 0 || p.Limit > maxNearbyLimit {
+		return pipeline.Invalid("error.limit_range", maxNearbyLimit)
+	}
+	return nil
+}
+
+// FindStopsNear handles the find_stops_near MCP tool
+func FindStopsNear(ctx context.Context, call *pipeline.Call, args FindStopsNearParams) (any, error) {
+	radius := args.RadiusMeters
+	if radius == 0 {
+		radius = defaultNearbyRadius
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultNearbyLimit
+	}
+	lat, lon, place, err := resolvePlace(args.Place, args.Latitude, args.Longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	neighbors := GlobalCatalog.StopsNear(lat, lon, float64(radius), limit)
+
+	stops := make([]types.NearbyStopResponse, len(neighbors))
+	for i, n := range neighbors {
+		stops[i] = types.ConvertNearbyStop(n.Value, n.Distance, GlobalWalker.Distance(n.Distance), GlobalWalker.Time(n.Distance))
+	}
+
+	return types.FindStopsNearResponse{
+		Latitude:     lat,
+		Longitude:    lon,
+		Place:        place,
+		RadiusMeters: radius,
+		TotalResults: len(stops),
+		CatalogSize:  GlobalCatalog.StopCount(),
+		Stops:        stops,
+	}, nil
+}
+
+// FindVehiclesNearParams defines the parameters for finding vehicles near a point
+type FindVehiclesNearParams struct {
+	Latitude       float64 `json:"latitude,omitempty" jsonschema:"Latitude of the point, required unless place is given"`
+	Longitude      float64 `json:"longitude,omitempty" jsonschema:"Longitude of the point, required unless place is given"`
+	Place          string  `json:"place,omitempty" jsonschema:"A street address such as 'Av. Paulista, 1578' or a stop name, in place of latitude and longitude"`
+	RadiusMeters   int     `json:"radius_meters,omitempty" jsonschema:"Search radius in meters, defaults to 500 (max 5000)"`
+	Limit          int     `json:"limit,omitempty" jsonschema:"Maximum number of vehicles to return, defaults to 20 (max 200)"`
+	AccessibleOnly *bool   `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	Format         string  `json:"format,omitempty"`
+}
+
+// Validate checks the find_vehicles_near arguments
+func (p FindVehiclesNearParams) Validate() error {
+	if err := validPlace(p.Place, p.Latitude, p.Longitude); err != nil {
+		return err
+	}
+	if p.RadiusMeters < 0 || p.RadiusMeters > maxNearbyRadius {
+		return pipeline.Invalid("error.radius_range", maxNearbyRadius)
+	}
+	if p.Limit < 0 || p.Limit > maxNearbyVehicleLimit {
+		return pipeline.Invalid("error.limit_range", maxNearbyVehicleLimit)
+	}
+	return nil
+}
+
+// lineVehicle is a vehicle of a positions snapshot together with its line
+type lineVehicle struct {
+	line    types.VehicleLine
+	vehicle types.Vehicle
+}
+
+// FindVehiclesNear handles the find_vehicles_near MCP tool
+func FindVehiclesNear(ctx context.Context, call *pipeline.Call, args FindVehiclesNearParams) (any, error) {
+	radius := args.RadiusMeters
+	if radius == 0 {
+		radius = defaultNearbyRadius
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultNearbyVehicleLimit
+	}
+	lat, lon, place, err := resolvePlace(args.Place, args.Latitude, args.Longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the vehicles of one fresh snapshot as it is decoded
+	accessible := accessibleOnly(call, args.AccessibleOnly)
+	index := geo.NewGrid[lineVehicle](geo.DefaultCellSize)
+	hour, err := GlobalClient.StreamVehiclePositions(ctx, func(line types.VehicleLine) {
+		vehicles := line.Vehicles
+		line.Vehicles = nil
+		for _, vehicle := range vehicles {
+			if accessible && !vehicle.Accessible {
+				continue
+			}
+			if geo.ValidCoordinate(vehicle.Latitude, vehicle.Longitude) {
+				index.Insert(vehicle.Latitude, vehicle.Longitude, lineVehicle{line: line, vehicle: vehicle})
+			}
+		}
+	})
+	if err != nil {
+		return nil, pipeline.Failed("error.find_vehicles_near", err)
+	}
+
+	now := time.Now()
+	neighbors := index.Within(lat, lon, float64(radius), limit)
+	vehicles := make([]types.NearbyVehicleResponse, len(neighbors))
+	for i, n := range neighbors {
+		v := n.Value.vehicle
+		bearing := geo.Bearing(lat, lon, v.Latitude, v.Longitude)
+		vehicles[i] = types.ConvertNearbyVehicle(n.Value.line, v, n.Distance, bearing, geo.Compass(bearing), now)
+	}
+
+	return types.FindVehiclesNearResponse{
+		Timestamp:    hour,
+		Latitude:     lat,
+		Longitude:    lon,
+		Place:        place,
+		RadiusMeters: radius,
+		TotalResults: len(vehicles),
+		Vehicles:     vehicles,
+	}, nil
+}
@@ -2,78 +2,131 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"slices"
 
-	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
 	"github.com/thunderjr/sptrans-mcp/internal/types"
 )
 
 // GetVehiclePositionsParams defines the parameters for getting all vehicle positions
 type GetVehiclePositionsParams struct {
-	// No parameters needed for getting all vehicle positions
+	LinePrefix     string             `json:"line_prefix,omitempty" jsonschema:"Only include lines whose identifier starts with this prefix (e.g. 875A)"`
+	PrefixArea     int                `json:"prefix_area,omitempty" jsonschema:"Only include vehicles whose prefix starts with this digit (1-8). Fleet numbering ties it to the operating area. There is no operating company filter, as /Posicao does not report the operator of a vehicle"`
+	AccessibleOnly *bool              `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	BoundingBox    *types.BoundingBox `json:"bounding_box,omitempty" jsonschema:"Only include vehicles inside this rectangle"`
+	Limit          int                `json:"limit,omitempty" jsonschema:"Maximum number of vehicles (or lines in summary mode) to return, defaults to 200"`
+	Cursor         string             `json:"cursor,omitempty" jsonschema:"The next_cursor value of a previous call, to fetch the following page. Pages are ordered by line code and vehicle ID and never repeat a vehicle, even though each call reads a fresh snapshot"`
+	Fields         []string           `json:"fields,omitempty" jsonschema:"Vehicle fields to include: id, accessible, last_update, latitude, longitude"`
+	SummaryOnly    bool               `json:"summary_only,omitempty" jsonschema:"Return only per-line vehicle counts instead of vehicle positions"`
+	Format         string             `json:"format,omitempty"`
+}
+
+const (
+	defaultVehiclePageLimit = 200
+	maxVehiclePageLimit     = 5000
+
+	// maxPrefixArea is the highest operating area in the fleet numbering
+	maxPrefixArea = 8
+)
+
+// Validate checks the get_vehicle_positions arguments
+func (p GetVehiclePositionsParams) Validate() error {
+	if p.PrefixArea < 0 || p.PrefixArea > maxPrefixArea {
+		return pipeline.Invalid("error.prefix_area", maxPrefixArea)
+	}
+	if p.Limit < 0 || p.Limit > maxVehiclePageLimit {
+		return pipeline.Invalid("error.limit_range", maxVehiclePageLimit)
+	}
+	if _, err := p.cursor(); err != nil {
+		return err
+	}
+	for _, field := range p.Fields {
+		if !slices.Contains(types.VehicleFields, field) {
+			return pipeline.Invalid("error.unknown_field", field)
+		}
+	}
+	if p.BoundingBox != nil && !p.BoundingBox.Valid() {
+		return pipeline.Invalid("error.bounding_box")
+	}
+	return nil
+}
+
+// cursor decodes the pagination cursor into the last item of the previous page
+func (p GetVehiclePositionsParams) cursor() (types.VehicleCursor, error) {
+	cursor, err := types.ParseVehicleCursor(p.Cursor)
+	if err != nil {
+		return types.VehicleCursor{}, pipeline.Invalid("error.invalid_cursor")
+	}
+	return cursor, nil
 }
 
 // GetVehiclePositionsByLineParams defines the parameters for getting vehicle positions by line
 type GetVehiclePositionsByLineParams struct {
-	LineCode int `json:"line_code" jsonschema:"The line code to get vehicle positions for"`
+	LineCode       Ref    `json:"line_code" jsonschema:"The line to get vehicle positions for: a line code, a sign such as 875A-10 with an optional direction such as 875A-10/1, or a search term"`
+	AccessibleOnly *bool  `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	Format         string `json:"format,omitempty"`
+}
+
+// Validate checks the get_vehicle_positions_by_line arguments
+func (p GetVehiclePositionsByLineParams) Validate() error {
+	return validLineRef("line_code", p.LineCode)
 }
 
 // GetVehiclePositions handles the get_vehicle_positions MCP tool
-func GetVehiclePositions(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetVehiclePositionsParams]) (*mcp.CallToolResultFor[any], error) {
-	positions, err := GlobalClient.GetVehiclePositions(ctx)
+func GetVehiclePositions(ctx context.Context, call *pipeline.Call, args GetVehiclePositionsParams) (any, error) {
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultVehiclePageLimit
+	}
+	after, err := args.cursor()
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get vehicle positions: %v", err)}},
-		}, nil
+		return nil, err
 	}
 
-	response := types.BuildGetVehiclePositionsResponse(*positions)
+	filter := types.VehicleFilter{
+		LinePrefix:     args.LinePrefix,
+		PrefixArea:     args.PrefixArea,
+		AccessibleOnly: accessibleOnly(call, args.AccessibleOnly),
+		BoundingBox:    args.BoundingBox,
+	}
+	page := types.NewVehiclePage(filter, after, limit, args.Fields, args.SummaryOnly)
 
-	responseJSON, err := json.Marshal(response)
+	hour, err := GlobalClient.StreamVehiclePositions(ctx, page.Add)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.get_vehicle_positions", err)
 	}
 
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
+	return page.Response(hour), nil
 }
 
 // GetVehiclePositionsByLine handles the get_vehicle_positions_by_line MCP tool
-func GetVehiclePositionsByLine(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetVehiclePositionsByLineParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.LineCode <= 0 {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "line_code parameter must be a positive integer"}},
-		}, nil
+func GetVehiclePositionsByLine(ctx context.Context, call *pipeline.Call, args GetVehiclePositionsByLineParams) (any, error) {
+	line, err := resolveLineRef(ctx, "line_code", args.LineCode, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	positions, err := GlobalClient.GetVehiclePositionsByLine(ctx, params.Arguments.LineCode)
+	positions, err := GlobalClient.GetVehiclePositionsByLine(ctx, line.Code)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get vehicle positions by line: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.get_vehicle_positions_by_line", err)
+	}
+	if accessibleOnly(call, args.AccessibleOnly) {
+		for i := range positions.Lines {
+			positions.Lines[i].Vehicles = accessibleVehicles(positions.Lines[i].Vehicles)
+			positions.Lines[i].VehicleQty = len(positions.Lines[i].Vehicles)
+		}
 	}
 
-	response := types.BuildGetVehiclePositionsByLineResponse(params.Arguments.LineCode, *positions)
+	return types.BuildGetVehiclePositionsByLineResponse(line.Code, *positions), nil
+}
 
-	responseJSON, err := json.Marshal(response)
-	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+// accessibleVehicles keeps the wheelchair-accessible vehicles
+func accessibleVehicles(vehicles []types.Vehicle) []types.Vehicle {
+	var kept []types.Vehicle
+	for _, vehicle := range vehicles {
+		if vehicle.Accessible {
+			kept = append(kept, vehicle)
+		}
 	}
-
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
-}
\ No newline at end of file
+	return kept
+}
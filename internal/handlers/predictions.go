@@ -2,131 +2,125 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
-	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
 	"github.com/thunderjr/sptrans-mcp/internal/types"
 )
 
 // GetArrivalPredictionsParams defines the parameters for getting arrival predictions
 type GetArrivalPredictionsParams struct {
-	StopCode int `json:"stop_code" jsonschema:"The stop code to get predictions for"`
-	LineCode int `json:"line_code" jsonschema:"The line code to get predictions for"`
+	StopCode       Ref    `json:"stop_code" jsonschema:"The stop to get predictions for: a stop code, a stop name or an address"`
+	LineCode       Ref    `json:"line_code" jsonschema:"The line to get predictions for: a line code, a sign such as 875A-10 with an optional direction such as 875A-10/1, or a search term"`
+	AccessibleOnly *bool  `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	Format         string `json:"format,omitempty"`
+}
+
+// Validate checks the get_arrival_predictions arguments
+func (p GetArrivalPredictionsParams) Validate() error {
+	if err := validStopRef("stop_code", p.StopCode); err != nil {
+		return err
+	}
+	return validLineRef("line_code", p.LineCode)
 }
 
 // GetArrivalPredictionsByLineParams defines the parameters for getting predictions by line
 type GetArrivalPredictionsByLineParams struct {
-	LineCode int `json:"line_code" jsonschema:"The line code to get all predictions for"`
+	LineCode       Ref    `json:"line_code" jsonschema:"The line to get all predictions for: a line code, a sign such as 875A-10 with an optional direction such as 875A-10/1, or a search term"`
+	AccessibleOnly *bool  `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	Format         string `json:"format,omitempty"`
+}
+
+// Validate checks the get_arrival_predictions_by_line arguments
+func (p GetArrivalPredictionsByLineParams) Validate() error {
+	return validLineRef("line_code", p.LineCode)
 }
 
 // GetArrivalPredictionsByStopParams defines the parameters for getting predictions by stop
 type GetArrivalPredictionsByStopParams struct {
-	StopCode int `json:"stop_code" jsonschema:"The stop code to get all predictions for"`
+	StopCode       Ref    `json:"stop_code" jsonschema:"The stop to get all predictions for: a stop code, a stop name or an address"`
+	AccessibleOnly *bool  `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	Format         string `json:"format,omitempty"`
 }
 
-// GetArrivalPredictions handles the get_arrival_predictions MCP tool
-func GetArrivalPredictions(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetArrivalPredictionsParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.StopCode <= 0 {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "stop_code parameter must be a positive integer"}},
-		}, nil
-	}
+// Validate checks the get_arrival_predictions_by_stop arguments
+func (p GetArrivalPredictionsByStopParams) Validate() error {
+	return validStopRef("stop_code", p.StopCode)
+}
 
-	if params.Arguments.LineCode <= 0 {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "line_code parameter must be a positive integer"}},
-		}, nil
+// GetArrivalPredictions handles the get_arrival_predictions MCP tool
+func GetArrivalPredictions(ctx context.Context, call *pipeline.Call, args GetArrivalPredictionsParams) (any, error) {
+	stop, err := resolveStopRef(ctx, "stop_code", args.StopCode)
+	if err != nil {
+		return nil, err
 	}
-
-	predictions, err := GlobalClient.GetArrivalPredictions(ctx, params.Arguments.StopCode, params.Arguments.LineCode)
+	line, err := resolveLineRef(ctx, "line_code", args.LineCode, stop.Code)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get arrival predictions: %v", err)}},
-		}, nil
+		return nil, err
 	}
 
-	response := types.BuildGetArrivalPredictionsResponse(params.Arguments.StopCode, params.Arguments.LineCode, *predictions)
-
-	responseJSON, err := json.Marshal(response)
+	predictions, err := GlobalClient.GetArrivalPredictions(ctx, stop.Code, line.Code)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.get_arrival_predictions", err)
+	}
+	if accessibleOnly(call, args.AccessibleOnly) {
+		keepAccessible(predictions.Stop.Lines)
 	}
 
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
+	return types.BuildGetArrivalPredictionsResponse(stop.Code, line.Code, *predictions), nil
 }
 
 // GetArrivalPredictionsByLine handles the get_arrival_predictions_by_line MCP tool
-func GetArrivalPredictionsByLine(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetArrivalPredictionsByLineParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.LineCode <= 0 {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "line_code parameter must be a positive integer"}},
-		}, nil
-	}
-
-	predictions, err := GlobalClient.GetArrivalPredictionsByLine(ctx, params.Arguments.LineCode)
+func GetArrivalPredictionsByLine(ctx context.Context, call *pipeline.Call, args GetArrivalPredictionsByLineParams) (any, error) {
+	line, err := resolveLineRef(ctx, "line_code", args.LineCode, 0)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get arrival predictions by line: %v", err)}},
-		}, nil
+		return nil, err
 	}
 
-	response := types.BuildGetArrivalPredictionsByLineResponse(params.Arguments.LineCode, *predictions)
-
-	responseJSON, err := json.Marshal(response)
+	predictions, err := GlobalClient.GetArrivalPredictionsByLine(ctx, line.Code)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.get_arrival_predictions_by_line", err)
+	}
+	if accessibleOnly(call, args.AccessibleOnly) {
+		for i := range predictions.Stops {
+			keepAccessible(predictions.Stops[i].Lines)
+		}
 	}
 
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
+	return types.BuildGetArrivalPredictionsByLineResponse(line.Code, *predictions), nil
 }
 
 // GetArrivalPredictionsByStop handles the get_arrival_predictions_by_stop MCP tool
-func GetArrivalPredictionsByStop(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetArrivalPredictionsByStopParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.StopCode <= 0 {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "stop_code parameter must be a positive integer"}},
-		}, nil
+func GetArrivalPredictionsByStop(ctx context.Context, call *pipeline.Call, args GetArrivalPredictionsByStopParams) (any, error) {
+	stop, err := resolveStopRef(ctx, "stop_code", args.StopCode)
+	if err != nil {
+		return nil, err
 	}
 
-	predictions, err := GlobalClient.GetArrivalPredictionsByStop(ctx, params.Arguments.StopCode)
+	predictions, err := GlobalClient.GetArrivalPredictionsByStop(ctx, stop.Code)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get arrival predictions by stop: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.get_arrival_predictions_by_stop", err)
+	}
+	if accessibleOnly(call, args.AccessibleOnly) {
+		for i := range predictions.Stops {
+			keepAccessible(predictions.Stops[i].Lines)
+		}
 	}
 
-	response := types.BuildGetArrivalPredictionsByStopResponse(params.Arguments.StopCode, *predictions)
+	return types.BuildGetArrivalPredictionsByStopResponse(stop.Code, *predictions), nil
+}
 
-	responseJSON, err := json.Marshal(response)
-	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+// keepAccessible drops the predictions of vehicles that are not
+// wheelchair-accessible
+func keepAccessible(lines []types.LineWithPredictions) {
+	for i := range lines {
+		line := &lines[i]
+		kept := line.Predictions[:0]
+		for _, p := range line.Predictions {
+			if p.Accessible {
+				kept = append(kept, p)
+			}
+		}
+		line.Predictions = kept
+		line.VehicleQty = len(kept)
 	}
-
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
-}
\ No newline at end of file
+}
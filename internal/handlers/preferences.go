@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/session"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// maxSavedPlaces bounds the places a session can save
+const maxSavedPlaces = 20
+
+// SavePlaceParams defines a place to save under a name
+type SavePlaceParams struct {
+	Name     string            `json:"name" jsonschema:"A name for the place, e.g. home or work"`
+	Location *types.Coordinate `json:"location,omitempty" jsonschema:"The coordinate of the place"`
+	Place    string            `json:"place,omitempty" jsonschema:"A street address or stop name to locate the place"`
+}
+
+// SetPreferencesParams defines the parameters for setting session preferences
+type SetPreferencesParams struct {
+	Locale         string           `json:"locale,omitempty" jsonschema:"Language of messages and rendered text: en or pt-BR"`
+	AccessibleOnly *bool            `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles in positions, predictions and trips unless a call says otherwise"`
+	SavePlace      *SavePlaceParams `json:"save_place,omitempty" jsonschema:"Save a place under a name, replacing any place of the same name, to use as from_place in leave_advice"`
+	ForgetPlace    string           `json:"forget_place,omitempty" jsonschema:"Forget the saved place with this name"`
+	Format         string           `json:"format,omitempty"`
+}
+
+// Validate checks the set_preferences arguments
+func (p SetPreferencesParams) Validate() error {
+	if _, err := i18n.ParseLocale(p.Locale, i18n.English); err != nil {
+		return pipeline.Invalid("error.invalid_locale", err)
+	}
+	if p.SavePlace != nil {
+		if strings.TrimSpace(p.SavePlace.Name) == "" {
+			return pipeline.Invalid("error.required", "save_place.name")
+		}
+		if (p.SavePlace.Location != nil) == (p.SavePlace.Place != "") {
+			return pipeline.Invalid("error.save_place")
+		}
+		if c := p.SavePlace.Location; c != nil && !geo.ValidCoordinate(c.Latitude, c.Longitude) {
+			return pipeline.Invalid("error.coordinate")
+		}
+	}
+	return nil
+}
+
+// SetPreferences handles the set_preferences MCP tool
+func SetPreferences(ctx context.Context, call *pipeline.Call, args SetPreferencesParams) (any, error) {
+	var saved *session.Place
+	if args.SavePlace != nil {
+		place, err := savedPlace(*args.SavePlace)
+		if err != nil {
+			return nil, err
+		}
+		saved = &place
+	}
+	current := Sessions.Get(call.Session)
+	if args.ForgetPlace != "" {
+		if _, ok := current.Place(args.ForgetPlace); !ok {
+			return nil, pipeline.Missing("error.unknown_place", args.ForgetPlace)
+		}
+	}
+	if saved != nil {
+		if _, ok := current.Place(saved.Name); !ok && len(current.Places) >= maxSavedPlaces {
+			return nil, pipeline.Invalid("error.places_limit", maxSavedPlaces)
+		}
+	}
+
+	prefs := Sessions.Update(call.Session, func(prefs *session.Preferences) {
+		prefs.Locale, _ = i18n.ParseLocale(args.Locale, prefs.Locale)
+		if args.AccessibleOnly != nil {
+			prefs.AccessibleOnly = *args.AccessibleOnly
+		}
+		if args.ForgetPlace == "" && saved == nil {
+			return
+		}
+		// Build a new list, as the current one may be shared with the defaults
+		places := make([]session.Place, 0, len(prefs.Places)+1)
+		for _, place := range prefs.Places {
+			if strings.EqualFold(place.Name, strings.TrimSpace(args.ForgetPlace)) || (saved != nil && strings.EqualFold(place.Name, saved.Name)) {
+				continue
+			}
+			places = append(places, place)
+		}
+		if saved != nil {
+			places = append(places, *saved)
+		}
+		prefs.Places = places
+	})
+
+	response := types.PreferencesResponse{
+		Locale:         string(prefs.Locale),
+		AccessibleOnly: prefs.AccessibleOnly,
+		Places:         make([]types.SavedPlaceResponse, len(prefs.Places)),
+	}
+	for i, place := range prefs.Places {
+		response.Places[i] = types.SavedPlaceResponse{
+			Name:      place.Name,
+			Label:     place.Label,
+			Latitude:  place.Latitude,
+			Longitude: place.Longitude,
+		}
+	}
+	return response, nil
+}
+
+// savedPlace locates a place to save from its coordinate or address
+func savedPlace(args SavePlaceParams) (session.Place, error) {
+	place := session.Place{Name: strings.TrimSpace(args.Name)}
+	if c := args.Location; c != nil {
+		place.Latitude, place.Longitude = c.Latitude, c.Longitude
+		place.Label = fmt.Sprintf("%.6f, %.6f", c.Latitude, c.Longitude)
+		return place, nil
+	}
+	lat, lon, name, err := resolvePlace(args.Place, 0, 0)
+	if err != nil {
+		return session.Place{}, err
+	}
+	place.Latitude, place.Longitude, place.Label = lat, lon, name
+	return place, nil
+}
+
+// accessibleOnly returns whether a call only includes wheelchair-accessible
+// vehicles: the call argument when given, otherwise the session setting
+func accessibleOnly(call *pipeline.Call, arg *bool) bool {
+	if arg != nil {
+		return *arg
+	}
+	var ss *mcp.ServerSession
+	if call != nil {
+		ss = call.Session
+	}
+	return Sessions.Get(ss).AccessibleOnly
+}
+
+// GetServerMetricsParams defines the parameters for getting server metrics
+type GetServerMetricsParams struct {
+	Format string `json:"format,omitempty"`
+}
+
+// GetServerMetrics handles the get_server_metrics MCP tool
+func GetServerMetrics(ctx context.Context, call *pipeline.Call, args GetServerMetricsParams) (any, error) {
+	return types.GetServerMetricsResponse{Tools: Metrics.Snapshot()}, nil
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/route"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// offRouteMeters is the distance from the route beyond which a vehicle is
+// flagged as off route, e.g. on a detour or heading to the garage
+const offRouteMeters = 300
+
+// GetVehicleProgressParams defines the parameters for locating the vehicles of a line along its route
+type GetVehicleProgressParams struct {
+	LineCode Ref    `json:"line_code" jsonschema:"The line to locate vehicles on: a line code, a sign such as 875A-10 with an optional direction such as 875A-10/1, or a search term"`
+	StopCode Ref    `json:"stop_code,omitempty" jsonschema:"A stop of the line to measure the remaining distance and number of stops to: a stop code, a stop name or an address"`
+	Format   string `json:"format,omitempty"`
+}
+
+// Validate checks the get_vehicle_progress arguments
+func (p GetVehicleProgressParams) Validate() error {
+	if err := validLineRef("line_code", p.LineCode); err != nil {
+		return err
+	}
+	if p.StopCode != nil {
+		return validStopRef("stop_code", p.StopCode)
+	}
+	return nil
+}
+
+// GetVehicleProgress handles the get_vehicle_progress MCP tool
+func GetVehicleProgress(ctx context.Context, call *pipeline.Call, args GetVehicleProgressParams) (any, error) {
+	var stop types.Stop
+	if args.StopCode != nil {
+		resolved, err := resolveStopRef(ctx, "stop_code", args.StopCode)
+		if err != nil {
+			return nil, err
+		}
+		stop = resolved
+	}
+	line, err := resolveLineRef(ctx, "line_code", args.LineCode, stop.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	stops, err := GlobalClient.GetStopsByLine(ctx, line.Code)
+	if err != nil {
+		return nil, pipeline.Failed("error.get_vehicle_progress", err)
+	}
+	if len(stops) == 0 {
+		return nil, pipeline.Missing("error.no_stops", line.Code)
+	}
+	GlobalCatalog.SetLineStops(line.Code, stops)
+
+	var shape []geo.Point
+	if line.Number != "" {
+		shape, _ = GlobalShapes.Lookup(line)
+	}
+	r := route.New(stops, shape)
+
+	target := -1
+	if stop.Code != 0 {
+		if target = r.StopIndex(stop.Code); target < 0 {
+			return nil, pipeline.Invalid("error.stop_not_on_line", stop.Code, line.Code)
+		}
+	}
+
+	positions, err := GlobalClient.GetVehiclePositionsByLine(ctx, line.Code)
+	if err != nil {
+		return nil, pipeline.Failed("error.get_vehicle_progress", err)
+	}
+
+	response := types.GetVehicleProgressResponse{
+		Timestamp:         positions.Hour,
+		LineCode:          line.Code,
+		ShapeSource:       r.Source(),
+		RouteLengthMeters: int(math.Round(r.Length())),
+		TotalStops:        len(stops),
+		Vehicles:          []types.VehicleProgressResponse{},
+	}
+	if target >= 0 {
+		response.StopCode = stops[target].Code
+		response.StopName = stops[target].Name
+		response.StopSequence = target + 1
+	}
+
+	for _, line := range positions.Lines {
+		for _, vehicle := range line.Vehicles {
+			pos := r.Locate(vehicle.Latitude, vehicle.Longitude)
+			var previous, next *types.Stop
+			if pos.Previous >= 0 {
+				previous = &stops[pos.Previous]
+			}
+			if pos.Next >= 0 {
+				next = &stops[pos.Next]
+			}
+			progress := types.ConvertVehicleProgress(vehicle, previous, next, pos.Along, pos.Offset, pos.Offset > offRouteMeters)
+			if target >= 0 {
+				remaining := int(math.Round(r.StopAlong(target) - pos.Along))
+				stopsAway := max(target-pos.Previous, 0)
+				progress.RemainingMeters = &remaining
+				progress.StopsAway = &stopsAway
+				progress.Passed = remaining < 0
+			}
+			response.Vehicles = append(response.Vehicles, progress)
+		}
+	}
+	sortProgress(response.Vehicles, target >= 0)
+	response.TotalVehicles = len(response.Vehicles)
+
+	return response, nil
+}
+
+// sortProgress orders vehicles along the route, or when measuring to a stop,
+// those still approaching it nearest first followed by those that passed it
+func sortProgress(vehicles []types.VehicleProgressResponse, toStop bool) {
+	sort.SliceStable(vehicles, func(i, j int) bool {
+		a, b := vehicles[i], vehicles[j]
+		if !toStop {
+			return a.TravelledMeters < b.TravelledMeters
+		}
+		if a.Passed != b.Passed {
+			return !a.Passed
+		}
+		if a.Passed {
+			return *a.RemainingMeters > *b.RemainingMeters
+		}
+		return *a.RemainingMeters < *b.RemainingMeters
+	})
+}
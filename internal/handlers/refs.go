@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/direction"
+	"github.com/thunderjr/sptrans-mcp/internal/geocode"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	maxCandidates     = 10    // Candidates listed for an ambiguous line or stop
+	maxServingLookups = 4     // Itineraries fetched to tell which of several lines serve a stop
+	stopRefRadius     = 300.0 // Distance from a coordinate to the nearest stop it refers to
+)
+
+// Ref is a line or stop argument, given either as a code or as text. Being an
+// interface, its schema accepts both JSON numbers and strings.
+type Ref any
+
+// signDirection matches a line sign or number followed by a direction, e.g.
+// 875A-10/1 or 875A-10 (2)
+var signDirection = regexp.MustCompile(`^(.+?)\s*(?:/\s*([12])|\(\s*([12])\s*\))$`)
+
+// parseRef splits a reference into its code, when given as a whole number,
+// or its text, reporting whether it is either
+func parseRef(ref Ref) (int, string, bool) {
+	switch v := ref.(type) {
+	case int:
+		return v, "", v > 0
+	case float64:
+		if v > 0 && v <= math.MaxInt32 && v == math.Trunc(v) {
+			return int(v), "", true
+		}
+	case json.Number:
+		if code, err := strconv.Atoi(v.String()); err == nil && code > 0 {
+			return code, "", true
+		}
+	case string:
+		text := strings.TrimSpace(v)
+		return 0, text, text != ""
+	}
+	return 0, "", false
+}
+
+// validLineRef checks that a line argument is a code or some text
+func validLineRef(name string, ref Ref) error {
+	if _, _, ok := parseRef(ref); !ok {
+		return pipeline.Invalid("error.line_ref", name)
+	}
+	return nil
+}
+
+// validLineRefs checks that each of a list of line arguments is a code or some text
+func validLineRefs(name string, refs []Ref) error {
+	for i, ref := range refs {
+		if err := validLineRef(fmt.Sprintf("%s[%d]", name, i), ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validStopRef checks that a stop argument is a code or some text
+func validStopRef(name string, ref Ref) error {
+	if _, _, ok := parseRef(ref); !ok {
+		return pipeline.Invalid("error.stop_ref", name)
+	}
+	return nil
+}
+
+// resolveLineRef finds the line an argument refers to: a line code, a sign or
+// number such as 875A-10 with an optional direction such as 875A-10/1, or a
+// search term. When several lines match, those serving atStop are preferred,
+// if given; the remaining ones are returned as candidates of an ambiguous
+// argument error.
+func resolveLineRef(ctx context.Context, name string, ref Ref, atStop int) (types.Line, error) {
+	code, text, _ := parseRef(ref)
+	if code != 0 {
+		if line, ok := GlobalCatalog.Line(code); ok {
+			return line, nil
+		}
+		return types.Line{Code: code}, nil
+	}
+
+	term, dir := text, 0
+	if m := signDirection.FindStringSubmatch(text); m != nil {
+		term = m[1]
+		dir, _ = strconv.Atoi(m[2] + m[3])
+	}
+	lines, err := findLines(ctx, term)
+	if err != nil {
+		return types.Line{}, err
+	}
+	if dir != 0 {
+		kept := lines[:0]
+		for _, line := range lines {
+			if line.Direction == dir {
+				kept = append(kept, line)
+			}
+		}
+		lines = kept
+	}
+	if len(lines) > 1 && atStop > 0 {
+		if serving := servingStop(ctx, lines, atStop); len(serving) > 0 {
+			lines = serving
+		}
+	}
+
+	switch len(lines) {
+	case 0:
+		return types.Line{}, pipeline.Missing("error.unknown_line", text)
+	case 1:
+		return lines[0], nil
+	}
+	return types.Line{}, ambiguousLine(name, text, lines)
+}
+
+// resolveLineRefs finds the lines an argument refers to, like resolveLineRef,
+// except that a line number or sign given without a direction stands for
+// all the directions it matches. Text matching several line numbers is
+// ambiguous.
+func resolveLineRefs(ctx context.Context, name string, ref Ref) ([]types.Line, error) {
+	_, text, _ := parseRef(ref)
+	if text == "" || signDirection.MatchString(text) {
+		line, err := resolveLineRef(ctx, name, ref, 0)
+		if err != nil {
+			return nil, err
+		}
+		return []types.Line{line}, nil
+	}
+
+	lines, err := findLines(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, pipeline.Missing("error.unknown_line", text)
+	}
+	for _, line := range lines {
+		if !strings.EqualFold(line.Number, lines[0].Number) {
+			return nil, ambiguousLine(name, text, lines)
+		}
+	}
+	return lines, nil
+}
+
+// ambiguousLine lists the lines an argument may refer to in an ambiguous
+// argument error
+func ambiguousLine(name, text string, lines []types.Line) error {
+	candidates := make([]types.CandidateResponse, 0, min(len(lines), maxCandidates))
+	for _, line := range lines[:min(len(lines), maxCandidates)] {
+		candidates = append(candidates, types.CandidateResponse{
+			Code:        line.Code,
+			Label:       line.Sign() + "/" + strconv.Itoa(line.Direction),
+			Description: strings.Join(direction.Terminals(line), " / "),
+		})
+	}
+	return pipeline.Ambiguous("error.ambiguous_line", candidates, text, len(lines), name)
+}
+
+// findLines returns the lines whose sign or number is term. Failing that, a
+// number is taken as a line code, and any other term as a search returning
+// every line found.
+func findLines(ctx context.Context, term string) ([]types.Line, error) {
+	found, err := GlobalClient.SearchLines(ctx, term)
+	if err != nil {
+		return nil, pipeline.Failed("error.resolve_line", err)
+	}
+	GlobalCatalog.AddLines(found)
+
+	var lines []types.Line
+	for _, line := range found {
+		if strings.EqualFold(line.Sign(), term) || strings.EqualFold(line.Number, term) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > 0 {
+		return lines, nil
+	}
+	if code, err := strconv.Atoi(term); err == nil && code > 0 {
+		if line, ok := GlobalCatalog.Line(code); ok {
+			return []types.Line{line}, nil
+		}
+		if len(found) == 0 {
+			return []types.Line{{Code: code}}, nil
+		}
+	}
+	return found, nil
+}
+
+// servingStop keeps the lines whose itinerary includes a stop, looking them
+// up in the catalog and fetching at most maxServingLookups itineraries
+func servingStop(ctx context.Context, lines []types.Line, stopCode int) []types.Line {
+	lookups := maxServingLookups
+	var serving []types.Line
+	for _, line := range lines {
+		stops, known := GlobalCatalog.LineStops(line.Code)
+		if !known {
+			if lookups == 0 {
+				continue
+			}
+			lookups--
+			var err error
+			if stops, err = stopsOfLine(ctx, line.Code); err != nil {
+				continue
+			}
+		}
+		for _, stop := range stops {
+			if stop.Code == stopCode {
+				serving = append(serving, line)
+				break
+			}
+		}
+	}
+	return serving
+}
+
+// resolveStopRef finds the stop an argument refers to: a stop code, a
+// coordinate or street address, resolved to the nearest stop, or a stop name.
+// When several stops match a name, they are returned as candidates of an
+// ambiguous argument error.
+func resolveStopRef(ctx context.Context, name string, ref Ref) (types.Stop, error) {
+	code, text, _ := parseRef(ref)
+	if code == 0 {
+		if n, err := strconv.Atoi(text); err == nil && n > 0 {
+			code = n
+		}
+	}
+	if code != 0 {
+		if stop, ok := GlobalCatalog.Stop(code); ok {
+			return stop, nil
+		}
+		return types.Stop{Code: code}, nil
+	}
+
+	// A coordinate or an address with a number points at a single stop
+	located := GlobalCatalog.Gazetteer().Geocode(text, 1)
+	if len(located) > 0 {
+		if stop, ok := nearestStop(located[0]); ok && (located[0].Kind == geocode.KindPoint || located[0].Number != 0) {
+			return stop, nil
+		}
+	}
+
+	found, err := GlobalClient.SearchStops(ctx, text)
+	if err != nil {
+		return types.Stop{}, pipeline.Failed("error.resolve_stop", err)
+	}
+	GlobalCatalog.AddStops(found)
+	if len(found) == 1 {
+		return found[0], nil
+	}
+
+	key := strings.Join(geocode.Tokenize(text), " ")
+	var exact []types.Stop
+	for _, stop := range found {
+		if strings.Join(geocode.Tokenize(stop.Name), " ") == key {
+			exact = append(exact, stop)
+		}
+	}
+	switch {
+	case len(exact) == 1:
+		return exact[0], nil
+	case len(exact) > 1:
+		found = exact
+	case len(found) == 0:
+		if len(located) > 0 {
+			if stop, ok := nearestStop(located[0]); ok {
+				return stop, nil
+			}
+		}
+		return types.Stop{}, pipeline.Missing("error.no_stop_match", text)
+	}
+
+	candidates := make([]types.CandidateResponse, 0, min(len(found), maxCandidates))
+	for _, stop := range found[:min(len(found), maxCandidates)] {
+		candidates = append(candidates, types.CandidateResponse{
+			Code:        stop.Code,
+			Label:       stop.Name,
+			Description: stop.Address,
+		})
+	}
+	return types.Stop{}, pipeline.Ambiguous("error.ambiguous_stop", candidates, text, len(found), name)
+}
+
+// nearestStop returns the stop a geocoded location refers to: the stop it
+// matched or the nearest one on its street, or the stop closest to a
+// coordinate
+func nearestStop(r geocode.Result) (types.Stop, bool) {
+	if r.Stop.Code != 0 {
+		return r.Stop, true
+	}
+	near := GlobalCatalog.StopsNear(r.Latitude, r.Longitude, stopRefRadius, 1)
+	if len(near) == 0 {
+		return types.Stop{}, false
+	}
+	return near[0].Value, true
+}
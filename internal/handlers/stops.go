@@ -2,123 +2,84 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
-	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
 	"github.com/thunderjr/sptrans-mcp/internal/types"
 )
 
 // SearchStopsParams defines the parameters for searching stops
 type SearchStopsParams struct {
 	SearchTerm string `json:"search_term" jsonschema:"The stop name or address to search for (partial or complete)"`
+	Format     string `json:"format,omitempty"`
+}
+
+// Validate checks the search_stops arguments
+func (p SearchStopsParams) Validate() error {
+	if p.SearchTerm == "" {
+		return pipeline.Invalid("error.required", "search_term")
+	}
+	return nil
 }
 
 // GetStopsByLineParams defines the parameters for getting stops by line
 type GetStopsByLineParams struct {
-	LineCode int `json:"line_code" jsonschema:"The line code to get stops for"`
+	LineCode Ref    `json:"line_code" jsonschema:"The line to get stops for: a line code, a sign such as 875A-10 with an optional direction such as 875A-10/1, or a search term"`
+	Format   string `json:"format,omitempty"`
+}
+
+// Validate checks the get_stops_by_line arguments
+func (p GetStopsByLineParams) Validate() error {
+	return validLineRef("line_code", p.LineCode)
 }
 
 // GetStopsByCorridorParams defines the parameters for getting stops by corridor
 type GetStopsByCorridorParams struct {
-	CorridorCode int `json:"corridor_code" jsonschema:"The corridor code to get stops for"`
+	CorridorCode int    `json:"corridor_code" jsonschema:"The corridor code to get stops for"`
+	Format       string `json:"format,omitempty"`
 }
 
-// SearchStops handles the search_stops MCP tool
-func SearchStops(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SearchStopsParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.SearchTerm == "" {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "search_term parameter is required"}},
-		}, nil
+// Validate checks the get_stops_by_corridor arguments
+func (p GetStopsByCorridorParams) Validate() error {
+	if p.CorridorCode <= 0 {
+		return pipeline.Invalid("error.positive_integer", "corridor_code")
 	}
+	return nil
+}
 
-	stops, err := GlobalClient.SearchStops(ctx, params.Arguments.SearchTerm)
-	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to search stops: %v", err)}},
-		}, nil
-	}
-
-	response := types.BuildSearchStopsResponse(len(stops), params.Arguments.SearchTerm, stops)
-
-	responseJSON, err := json.Marshal(response)
+// SearchStops handles the search_stops MCP tool
+func SearchStops(ctx context.Context, call *pipeline.Call, args SearchStopsParams) (any, error) {
+	stops, err := GlobalClient.SearchStops(ctx, args.SearchTerm)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.search_stops", err)
 	}
+	GlobalCatalog.AddStops(stops)
 
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
+	return types.BuildSearchStopsResponse(len(stops), args.SearchTerm, stops), nil
 }
 
 // GetStopsByLine handles the get_stops_by_line MCP tool
-func GetStopsByLine(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetStopsByLineParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.LineCode <= 0 {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "line_code parameter must be a positive integer"}},
-		}, nil
-	}
-
-	stops, err := GlobalClient.GetStopsByLine(ctx, params.Arguments.LineCode)
+func GetStopsByLine(ctx context.Context, call *pipeline.Call, args GetStopsByLineParams) (any, error) {
+	line, err := resolveLineRef(ctx, "line_code", args.LineCode, 0)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get stops by line: %v", err)}},
-		}, nil
+		return nil, err
 	}
 
-	response := types.BuildGetStopsByLineResponse(len(stops), params.Arguments.LineCode, stops)
-
-	responseJSON, err := json.Marshal(response)
+	stops, err := GlobalClient.GetStopsByLine(ctx, line.Code)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.get_stops_by_line", err)
 	}
+	GlobalCatalog.SetLineStops(line.Code, stops)
 
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
+	return types.BuildGetStopsByLineResponse(len(stops), line.Code, stops), nil
 }
 
 // GetStopsByCorridor handles the get_stops_by_corridor MCP tool
-func GetStopsByCorridor(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[GetStopsByCorridorParams]) (*mcp.CallToolResultFor[any], error) {
-	if params.Arguments.CorridorCode <= 0 {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: "corridor_code parameter must be a positive integer"}},
-		}, nil
-	}
-
-	stops, err := GlobalClient.GetStopsByCorridor(ctx, params.Arguments.CorridorCode)
+func GetStopsByCorridor(ctx context.Context, call *pipeline.Call, args GetStopsByCorridorParams) (any, error) {
+	stops, err := GlobalClient.GetStopsByCorridor(ctx, args.CorridorCode)
 	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get stops by corridor: %v", err)}},
-		}, nil
+		return nil, pipeline.Failed("error.get_stops_by_corridor", err)
 	}
+	GlobalCatalog.AddStops(stops)
 
-	response := types.BuildGetStopsByCorridorResponse(len(stops), params.Arguments.CorridorCode, stops)
-
-	responseJSON, err := json.Marshal(response)
-	if err != nil {
-		return &mcp.CallToolResultFor[any]{
-			IsError: true,
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to marshal response: %v", err)}},
-		}, nil
-	}
-
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: string(responseJSON)}},
-		StructuredContent: response,
-	}, nil
-}
\ No newline at end of file
+	return types.BuildGetStopsByCorridorResponse(len(stops), args.CorridorCode, stops), nil
+}
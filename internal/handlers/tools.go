@@ -0,0 +1,57 @@
+package handlers
+
+import "github.com/thunderjr/sptrans-mcp/internal/registry"
+
+// RegisterTools adds every tool handler to the registry under its group
+func RegisterTools(r *registry.Registry) {
+	// Line operation tools
+	registry.Add(r, registry.Lines, "search_lines", SearchLines)
+	registry.Add(r, registry.Lines, "search_line_by_direction", SearchLineByDirection)
+	registry.Add(r, registry.Lines, "resolve_direction", ResolveDirection)
+	registry.Add(r, registry.Lines, "find_transfer_points", FindTransferPoints)
+
+	// Stop operation tools
+	registry.Add(r, registry.Stops, "search_stops", SearchStops)
+	registry.Add(r, registry.Stops, "get_stops_by_line", GetStopsByLine)
+	registry.Add(r, registry.Stops, "find_stops_near", FindStopsNear)
+	registry.Add(r, registry.Stops, "walking_distance", WalkingDistance)
+	registry.Add(r, registry.Stops, "nearby_transfers", NearbyTransfers)
+
+	// Vehicle position tools
+	registry.Add(r, registry.Positions, "get_vehicle_positions", GetVehiclePositions)
+	registry.Add(r, registry.Positions, "get_vehicle_positions_by_line", GetVehiclePositionsByLine)
+	registry.Add(r, registry.Positions, "find_vehicles_near", FindVehiclesNear)
+	registry.Add(r, registry.Positions, "query_viewport", QueryViewport)
+	registry.Add(r, registry.Positions, "render_map", RenderMap)
+	registry.Add(r, registry.Positions, "get_vehicle_progress", GetVehicleProgress)
+	registry.Add(r, registry.Positions, "create_geofence", CreateGeofence)
+	registry.Add(r, registry.Positions, "update_geofence", UpdateGeofence)
+	registry.Add(r, registry.Positions, "delete_geofence", DeleteGeofence)
+	registry.Add(r, registry.Positions, "list_geofences", ListGeofences)
+	registry.Add(r, registry.Positions, "get_geofence_events", GetGeofenceEvents)
+
+	// Arrival prediction tools (core for forecasting)
+	registry.Add(r, registry.Predictions, "get_arrival_predictions", GetArrivalPredictions)
+	registry.Add(r, registry.Predictions, "get_arrival_predictions_by_line", GetArrivalPredictionsByLine)
+	registry.Add(r, registry.Predictions, "get_arrival_predictions_by_stop", GetArrivalPredictionsByStop)
+	registry.Add(r, registry.Predictions, "departure_board", DepartureBoard)
+	registry.Add(r, registry.Predictions, "get_predictions_batch", GetPredictionsBatch)
+	registry.Add(r, registry.Predictions, "leave_advice", LeaveAdvice)
+	registry.Add(r, registry.Predictions, "plan_trip", PlanTrip)
+	registry.Add(r, registry.Predictions, "calculate_fare", CalculateFare)
+
+	// Network catalog tools
+	registry.Add(r, registry.Catalog, "get_catalog_status", GetCatalogStatus)
+	registry.Add(r, registry.Catalog, "geocode_address", GeocodeAddress)
+	registry.Add(r, registry.Catalog, "reverse_geocode", ReverseGeocode)
+
+	// Analyses derived from live data
+	registry.Add(r, registry.Analytics, "get_accessible_fleet", GetAccessibleFleet)
+	registry.Add(r, registry.Analytics, "isochrone", Isochrone)
+
+	// Session tools
+	registry.Add(r, registry.Session, "set_preferences", SetPreferences)
+
+	// Server tools
+	registry.Add(r, registry.Admin, "get_server_metrics", GetServerMetrics)
+}
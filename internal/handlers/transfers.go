@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultTransferPoints = 10
+	maxTransferPoints     = 50
+	maxLiveTransfers      = 10 // Transfer points whose predictions are fetched per call
+)
+
+// FindTransferPointsParams defines the parameters for finding where to change between two lines
+type FindTransferPointsParams struct {
+	FromLine     Ref    `json:"from_line" jsonschema:"The line ridden first: a line code, a number such as 8000 for both directions, a sign such as 8000-10 with an optional direction such as 8000-10/1, or a search term"`
+	ToLine       Ref    `json:"to_line" jsonschema:"The line to change to: a line code, a number such as 8000 for both directions, a sign such as 8000-10 with an optional direction such as 8000-10/1, or a search term"`
+	RadiusMeters int    `json:"radius_meters,omitempty" jsonschema:"Maximum straight-line distance walked between stops, defaults to 300 (max 1000)"`
+	Live         bool   `json:"live,omitempty" jsonschema:"Include the live predicted wait for to_line at each transfer point"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"Maximum number of transfer points to return, defaults to 10 (max 50)"`
+	Format       string `json:"format,omitempty"`
+}
+
+// Validate checks the find_transfer_points arguments
+func (p FindTransferPointsParams) Validate() error {
+	if err := validLineRef("from_line", p.FromLine); err != nil {
+		return err
+	}
+	if err := validLineRef("to_line", p.ToLine); err != nil {
+		return err
+	}
+	if p.RadiusMeters < 0 || p.RadiusMeters > maxTransferRadius {
+		return pipeline.Invalid("error.radius_range", maxTransferRadius)
+	}
+	if p.Limit < 0 || p.Limit > maxTransferPoints {
+		return pipeline.Invalid("error.limit_range", maxTransferPoints)
+	}
+	return nil
+}
+
+// lineStops is a line with its ordered stops
+type lineStops struct {
+	line  types.Line
+	stops []types.Stop
+}
+
+// FindTransferPoints handles the find_transfer_points MCP tool
+func FindTransferPoints(ctx context.Context, call *pipeline.Call, args FindTransferPointsParams) (any, error) {
+	radius := args.RadiusMeters
+	if radius == 0 {
+		radius = defaultTransferRadius
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultTransferPoints
+	}
+
+	first, err := resolveLineStops(ctx, "from_line", args.FromLine)
+	if err != nil {
+		return nil, err
+	}
+	second, err := resolveLineStops(ctx, "to_line", args.ToLine)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pair each stop where the first line can be left with each stop within
+	// reach where the second line can be boarded
+	type pair struct{ from, to int }
+	points := make(map[pair]*types.TransferPointResponse)
+	for _, a := range first {
+		for i := 1; i < len(a.stops); i++ {
+			from := a.stops[i]
+			for _, b := range second {
+				for j := 0; j < len(b.stops)-1; j++ {
+					to := b.stops[j]
+					distance := geo.Distance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+					if from.Code != to.Code && distance > float64(radius) {
+						continue
+					}
+					key := pair{from.Code, to.Code}
+					point, ok := points[key]
+					if !ok {
+						if from.Code == to.Code {
+							distance = 0
+						}
+						point = &types.TransferPointResponse{
+							FromStop:       types.ConvertStop(from),
+							ToStop:         types.ConvertStop(to),
+							Shared:         from.Code == to.Code,
+							DistanceMeters: int(math.Round(distance)),
+							WalkingMeters:  int(math.Round(GlobalWalker.Distance(distance))),
+							WalkingMinutes: int(math.Ceil(GlobalWalker.Time(distance) / 60)),
+						}
+						points[key] = point
+					}
+					point.Connections = append(point.Connections, types.TransferConnectionResponse{
+						FromLine: types.ConvertLine(a.line),
+						ToLine:   types.ConvertLine(b.line),
+					})
+				}
+			}
+		}
+	}
+
+	response := types.FindTransferPointsResponse{
+		FromLines:      convertLineStops(first),
+		ToLines:        convertLineStops(second),
+		RadiusMeters:   radius,
+		TransferPoints: []types.TransferPointResponse{},
+	}
+	for _, point := range points {
+		if point.Shared {
+			response.SharedStops++
+		}
+		response.TransferPoints = append(response.TransferPoints, *point)
+	}
+	sort.Slice(response.TransferPoints, func(i, j int) bool {
+		a, b := response.TransferPoints[i], response.TransferPoints[j]
+		if a.DistanceMeters != b.DistanceMeters {
+			return a.DistanceMeters < b.DistanceMeters
+		}
+		if a.FromStop.Code != b.FromStop.Code {
+			return a.FromStop.Code < b.FromStop.Code
+		}
+		return a.ToStop.Code < b.ToStop.Code
+	})
+	if len(response.TransferPoints) > limit {
+		response.TransferPoints = response.TransferPoints[:limit]
+	}
+	response.TotalResults = len(response.TransferPoints)
+
+	if args.Live {
+		boards := make(map[int]*types.ArrivalPredictionsByLine)
+		for i := range response.TransferPoints {
+			if i == maxLiveTransfers {
+				break
+			}
+			point := &response.TransferPoints[i]
+			predictions, ok := boards[point.ToStop.Code]
+			if !ok {
+				predictions, _ = GlobalClient.GetArrivalPredictionsByStop(ctx, point.ToStop.Code)
+				boards[point.ToStop.Code] = predictions
+			}
+			if predictions != nil {
+				addTransferWaits(point, predictions)
+			}
+		}
+	}
+	return response, nil
+}
+
+// addTransferWaits adds the wait for the second line of each connection at
+// the stop it is boarded, counted from the time of the predictions and left
+// out when no bus is predicted
+func addTransferWaits(point *types.TransferPointResponse, predictions *types.ArrivalPredictionsByLine) {
+	for i := range point.Connections {
+		connection := &point.Connections[i]
+		for _, stop := range predictions.Stops {
+			for _, line := range stop.Lines {
+				if line.Code != connection.ToLine.Code {
+					continue
+				}
+				for _, p := range line.Predictions {
+					wait, ok := types.MinutesUntil(predictions.Hour, p.ArrivalTime)
+					if !ok {
+						continue
+					}
+					if wait = max(wait, 0); connection.WaitMinutes == nil || wait < *connection.WaitMinutes {
+						connection.WaitMinutes = &wait
+						connection.VehicleID = p.VehicleID
+					}
+				}
+			}
+		}
+	}
+}
+
+// resolveLineStops finds the directions of a line argument together with
+// their ordered stops
+func resolveLineStops(ctx context.Context, name string, ref Ref) ([]lineStops, error) {
+	lines, err := resolveLineRefs(ctx, name, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]lineStops, 0, len(lines))
+	for _, line := range lines {
+		stops, err := stopsOfLine(ctx, line.Code)
+		if err != nil {
+			return nil, pipeline.Failed("error.find_transfer_points", err)
+		}
+		if len(stops) > 0 {
+			resolved = append(resolved, lineStops{line: line, stops: stops})
+		}
+	}
+	if len(resolved) == 0 {
+		return nil, pipeline.Missing("error.unknown_line", fmt.Sprint(ref))
+	}
+	return resolved, nil
+}
+
+// convertLineStops converts the lines of resolved line stops
+func convertLineStops(resolved []lineStops) []types.LineResponse {
+	lines := make([]types.LineResponse, len(resolved))
+	for i, r := range resolved {
+		lines[i] = types.ConvertLine(r.line)
+	}
+	return lines
+}
+
+// stopsOfLine returns the ordered stops of a line from the catalog, fetching
+// and caching them when the catalog does not have them
+func stopsOfLine(ctx context.Context, lineCode int) ([]types.Stop, error) {
+	if stops, ok := GlobalCatalog.LineStops(lineCode); ok && len(stops) > 0 {
+		return stops, nil
+	}
+	stops, err := GlobalClient.GetStopsByLine(ctx, lineCode)
+	if err != nil {
+		return nil, err
+	}
+	GlobalCatalog.SetLineStops(lineCode, stops)
+	return stops, nil
+}
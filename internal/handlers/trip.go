@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/fare"
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/journey"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultTripTransfers = 2
+	maxTripTransfers     = 2
+	defaultTripWalk      = 800
+	minTripWalk          = 100
+	maxTripWalk          = 2000
+	tripTransferWalk     = 300
+	defaultTripOptions   = 3
+	maxTripOptions       = 5
+	maxLiveStops         = 12 // Boarding stops whose predictions are fetched per trip
+)
+
+// saoPaulo is the local time of the SPTrans network, which has no daylight saving time
+var saoPaulo = time.FixedZone("BRT", -3*60*60)
+
+// PlanTripParams defines the parameters for planning a trip between two points
+type PlanTripParams struct {
+	FromStopCode   Ref               `json:"from_stop_code,omitempty" jsonschema:"Start at this stop: a stop code, a stop name or an address"`
+	From           *types.Coordinate `json:"from,omitempty" jsonschema:"Start at this coordinate"`
+	FromPlace      string            `json:"from_place,omitempty" jsonschema:"Start at this street address or stop name"`
+	ToStopCode     Ref               `json:"to_stop_code,omitempty" jsonschema:"End at this stop: a stop code, a stop name or an address"`
+	To             *types.Coordinate `json:"to,omitempty" jsonschema:"End at this coordinate"`
+	ToPlace        string            `json:"to_place,omitempty" jsonschema:"End at this street address or stop name"`
+	MaxTransfers   *int              `json:"max_transfers,omitempty" jsonschema:"Maximum number of changes between buses (0-2), defaults to 2"`
+	MaxWalkMeters  int               `json:"max_walk_meters,omitempty" jsonschema:"Maximum straight-line distance walked to the first stop and from the last stop, defaults to 800 (100-2000)"`
+	Limit          int               `json:"limit,omitempty" jsonschema:"Maximum number of options to return, defaults to 3 (max 5)"`
+	FareCategory   string            `json:"fare_category,omitempty" jsonschema:"Fare category the options are priced for: full, student or elderly, defaults to full"`
+	AccessibleOnly *bool             `json:"accessible_only,omitempty" jsonschema:"Only wait for wheelchair-accessible buses, leaving out options with a ride no accessible bus is predicted for, defaults to the session setting"`
+	Format         string            `json:"format,omitempty"`
+}
+
+// Validate checks the plan_trip arguments
+func (p PlanTripParams) Validate() error {
+	if err := validEndpoint("from", p.FromStopCode, p.From, p.FromPlace); err != nil {
+		return err
+	}
+	if err := validEndpoint("to", p.ToStopCode, p.To, p.ToPlace); err != nil {
+		return err
+	}
+	if p.MaxTransfers != nil && (*p.MaxTransfers < 0 || *p.MaxTransfers > maxTripTransfers) {
+		return pipeline.Invalid("error.transfers_range", maxTripTransfers)
+	}
+	if p.MaxWalkMeters != 0 && (p.MaxWalkMeters < minTripWalk || p.MaxWalkMeters > maxTripWalk) {
+		return pipeline.Invalid("error.walk_range", minTripWalk, maxTripWalk)
+	}
+	if p.Limit < 0 || p.Limit > maxTripOptions {
+		return pipeline.Invalid("error.limit_range", maxTripOptions)
+	}
+	return validFareCategory(p.FareCategory)
+}
+
+// PlanTrip handles the plan_trip MCP tool
+func PlanTrip(ctx context.Context, call *pipeline.Call, args PlanTripParams) (any, error) {
+	opts := journey.Options{
+		MaxTransfers: defaultTripTransfers,
+		MaxWalk:      defaultTripWalk,
+		TransferWalk: tripTransferWalk,
+		Walker:       GlobalWalker,
+	}
+	if args.MaxTransfers != nil {
+		opts.MaxTransfers = *args.MaxTransfers
+	}
+	if args.MaxWalkMeters != 0 {
+		opts.MaxWalk = float64(args.MaxWalkMeters)
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultTripOptions
+	}
+	category := args.FareCategory
+	if category == "" {
+		category = fare.Full
+	}
+
+	from, err := resolveEndpoint(ctx, "from", args.FromStopCode, args.From, args.FromPlace)
+	if err != nil {
+		return nil, err
+	}
+	to, err := resolveEndpoint(ctx, "to", args.ToStopCode, args.To, args.ToPlace)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(saoPaulo)
+	network := GlobalCatalog.Network()
+	response := types.PlanTripResponse{
+		From:           from,
+		To:             to,
+		Departure:      now.Format("15:04"),
+		NetworkLines:   network.Lines(),
+		AccessibleOnly: accessibleOnly(call, args.AccessibleOnly),
+		Options:        []types.TripOptionResponse{},
+	}
+
+	// Plan with estimated waits, keeping spare options for the live ranking
+	itineraries := network.Plan(
+		geo.Point{Latitude: from.Latitude, Longitude: from.Longitude},
+		geo.Point{Latitude: to.Latitude, Longitude: to.Longitude},
+		opts, limit+2)
+	waits := liveWaits(ctx, itineraries, now, response.AccessibleOnly)
+	timed := itineraries[:0]
+	for _, it := range itineraries {
+		it = it.Retime(waits.next)
+		// An estimated wait says nothing about whether an accessible bus comes
+		if response.AccessibleOnly && !it.Live() {
+			response.NoAccessibleBus++
+			continue
+		}
+		timed = append(timed, it)
+	}
+	itineraries = timed
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		return itineraries[i].Arrival() < itineraries[j].Arrival()
+	})
+	if len(itineraries) > limit {
+		itineraries = itineraries[:limit]
+	}
+
+	for i, it := range itineraries {
+		response.Options = append(response.Options, convertItinerary(i+1, it, from, to, network, now, category))
+	}
+	response.TotalResults = len(response.Options)
+	return response, nil
+}
+
+// predictedBus is a bus predicted at a stop
+type predictedBus struct {
+	at         time.Duration // Time from now the bus is predicted at the stop
+	vehicleID  string
+	accessible bool
+}
+
+// stopWaits holds the buses predicted at the boarding stops of a trip, by
+// stop and line code
+type stopWaits map[int]map[int][]predictedBus
+
+// liveWaits fetches the predictions for the boarding stops of the itineraries,
+// skipping the stops whose predictions cannot be fetched and, in accessible
+// mode, the buses that are not wheelchair-accessible
+func liveWaits(ctx context.Context, itineraries []journey.Itinerary, now time.Time, accessible bool) stopWaits {
+	var stops []int
+	seen := make(map[int]bool)
+	for _, it := range itineraries {
+		for _, leg := range it.Legs {
+			if leg.Kind == journey.Ride && !seen[leg.From] && len(stops) < maxLiveStops {
+				seen[leg.From] = true
+				stops = append(stops, leg.From)
+			}
+		}
+	}
+
+	waits := make(stopWaits)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, stop := range stops {
+		wg.Add(1)
+		go func(stop int) {
+			defer wg.Done()
+			predictions, err := GlobalClient.GetArrivalPredictionsByStop(ctx, stop)
+			if err != nil {
+				return
+			}
+			lines := make(map[int][]predictedBus)
+			for _, s := range predictions.Stops {
+				for _, line := range s.Lines {
+					for _, p := range line.Predictions {
+						if accessible && !p.Accessible {
+							continue
+						}
+						if at, ok := untilClock(now, p.ArrivalTime); ok {
+							lines[line.Code] = append(lines[line.Code], predictedBus{at: at, vehicleID: p.VehicleID, accessible: p.Accessible})
+						}
+					}
+				}
+			}
+			for _, buses := range lines {
+				sort.Slice(buses, func(i, j int) bool { return buses[i].at < buses[j].at })
+			}
+			mu.Lock()
+			waits[stop] = lines
+			mu.Unlock()
+		}(stop)
+	}
+	wg.Wait()
+	return waits
+}
+
+// next returns the first bus predicted for a line at a stop at or after the
+// given time from now
+func (w stopWaits) next(line types.Line, stopCode int, at time.Duration) (journey.Departure, bool) {
+	for _, bus := range w[stopCode][line.Code] {
+		if bus.at >= at {
+			return journey.Departure{Wait: bus.at - at, VehicleID: bus.vehicleID, Accessible: bus.accessible, Live: true}, true
+		}
+	}
+	return journey.Departure{}, false
+}
+
+// untilClock returns the time from now to the next "HH:MM" local time,
+// treating times up to an hour in the past as already due
+func untilClock(now time.Time, clock string) (time.Duration, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	until := at.Sub(now)
+	switch {
+	case until < -time.Hour:
+		until += 24 * time.Hour
+	case until < 0:
+		until = 0
+	}
+	return until, true
+}
+
+// snapshotTime returns the "HH:MM" time of a snapshot on the day closest to
+// clock, or clock itself when the time cannot be read
+func snapshotTime(hour string, clock time.Time) time.Time {
+	t, err := time.Parse("15:04", hour)
+	if err != nil {
+		return clock
+	}
+	at := time.Date(clock.Year(), clock.Month(), clock.Day(), t.Hour(), t.Minute(), 0, 0, clock.Location())
+	switch {
+	case at.Sub(clock) > 12*time.Hour:
+		at = at.AddDate(0, 0, -1)
+	case clock.Sub(at) > 12*time.Hour:
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// convertItinerary converts a timed itinerary to a trip option
+func convertItinerary(rank int, it journey.Itinerary, from, to types.WalkEndpointResponse, network *journey.Network, now time.Time, category string) types.TripOptionResponse {
+	endpoint := func(stopCode int, fallback types.WalkEndpointResponse) types.WalkEndpointResponse {
+		if stop, ok := network.Stop(stopCode); ok {
+			return types.WalkEndpointResponse{Name: stop.Name, StopCode: stop.Code, Latitude: stop.Latitude, Longitude: stop.Longitude}
+		}
+		return fallback
+	}
+	clock := func(d time.Duration) string {
+		return now.Add(d).Format("15:04")
+	}
+
+	option := types.TripOptionResponse{
+		Rank:            rank,
+		Arrival:         clock(it.Arrival()),
+		DurationMinutes: minutes(it.Arrival()),
+		Transfers:       it.Transfers(),
+		WalkingMeters:   int(math.Round(it.WalkMeters())),
+		LiveWaits:       true,
+		TransferStops:   []types.WalkEndpointResponse{},
+	}
+	var rides []time.Duration
+	var boardAt []string
+	for _, leg := range it.Legs {
+		converted := types.TripLegResponse{
+			Mode:      leg.Kind,
+			From:      endpoint(leg.From, from),
+			To:        endpoint(leg.To, to),
+			Departure: clock(leg.Start + leg.Departure.Wait),
+			Arrival:   clock(leg.End()),
+			Minutes:   minutes(leg.Duration),
+			Meters:    int(math.Round(leg.Meters)),
+		}
+		if leg.Kind == journey.Ride {
+			line := types.ConvertLine(leg.Line)
+			converted.Line = &line
+			converted.Stops = leg.Stops
+			converted.WaitMinutes = minutes(leg.Departure.Wait)
+			converted.LiveWait = leg.Departure.Live
+			converted.VehicleID = leg.Departure.VehicleID
+			converted.Accessible = leg.Departure.Accessible
+			option.WaitMinutes += converted.WaitMinutes
+			option.LiveWaits = option.LiveWaits && leg.Departure.Live
+			if len(rides) > 0 {
+				option.TransferStops = append(option.TransferStops, converted.From)
+			}
+			rides = append(rides, leg.Start+leg.Departure.Wait)
+			boardAt = append(boardAt, converted.Departure)
+		}
+		option.Legs = append(option.Legs, converted)
+	}
+	if len(rides) == 0 {
+		option.LiveWaits = false
+	} else {
+		option.Fare = priceTrip(rides, boardAt, category)
+	}
+	return option
+}
+
+// minutes rounds a duration to whole minutes
+func minutes(d time.Duration) int {
+	return int(math.Round(d.Minutes()))
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultViewportLimit = 500
+	maxViewportLimit     = 5000
+)
+
+// Viewport layers
+const (
+	stopsLayer    = "stops"
+	vehiclesLayer = "vehicles"
+)
+
+// viewportLayers lists the layers query_viewport can return
+var viewportLayers = []string{stopsLayer, vehiclesLayer}
+
+// QueryViewportParams defines the parameters for querying a map viewport
+type QueryViewportParams struct {
+	SouthWest      types.Coordinate `json:"south_west" jsonschema:"South-west corner of the viewport"`
+	NorthEast      types.Coordinate `json:"north_east" jsonschema:"North-east corner of the viewport"`
+	Layers         []string         `json:"layers,omitempty" jsonschema:"Layers to return: stops, vehicles (defaults to both)"`
+	LinePrefix     string           `json:"line_prefix,omitempty" jsonschema:"Only include vehicles of lines whose identifier starts with this prefix (e.g. 875A)"`
+	AccessibleOnly *bool            `json:"accessible_only,omitempty" jsonschema:"Only include wheelchair-accessible vehicles, defaults to the session setting"`
+	Cluster        bool             `json:"cluster,omitempty" jsonschema:"Aggregate stops and vehicles into clusters sized for the zoom level"`
+	Zoom           int              `json:"zoom,omitempty" jsonschema:"Web map zoom level (1-22) used to size clusters, estimated from the viewport when omitted"`
+	Limit          int              `json:"limit,omitempty" jsonschema:"Maximum number of stops and of vehicles to return without clustering, defaults to 500 (max 5000)"`
+	Format         string           `json:"format,omitempty"`
+}
+
+// Validate checks the query_viewport arguments
+func (p QueryViewportParams) Validate() error {
+	if !geo.ValidCoordinate(p.SouthWest.Latitude, p.SouthWest.Longitude) ||
+		!geo.ValidCoordinate(p.NorthEast.Latitude, p.NorthEast.Longitude) {
+		return pipeline.Invalid("error.coordinate")
+	}
+	if !p.box().Valid() {
+		return pipeline.Invalid("error.viewport")
+	}
+	for _, layer := range p.Layers {
+		if !slices.Contains(viewportLayers, layer) {
+			return pipeline.Invalid("error.unknown_layer", layer)
+		}
+	}
+	if p.Zoom < 0 || p.Zoom > geo.MaxZoom {
+		return pipeline.Invalid("error.zoom_range", geo.MaxZoom)
+	}
+	if p.Limit < 0 || p.Limit > maxViewportLimit {
+		return pipeline.Invalid("error.limit_range", maxViewportLimit)
+	}
+	return nil
+}
+
+// box returns the viewport as a bounding box
+func (p QueryViewportParams) box() types.BoundingBox {
+	return types.BoundingBox{
+		South: p.SouthWest.Latitude,
+		West:  p.SouthWest.Longitude,
+		North: p.NorthEast.Latitude,
+		East:  p.NorthEast.Longitude,
+	}
+}
+
+// layer reports whether the layer was requested
+func (p QueryViewportParams) layer(name string) bool {
+	return len(p.Layers) == 0 || slices.Contains(p.Layers, name)
+}
+
+// QueryViewport handles the query_viewport MCP tool
+func QueryViewport(ctx context.Context, call *pipeline.Call, args QueryViewportParams) (any, error) {
+	box := args.box()
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultViewportLimit
+	}
+
+	response := types.QueryViewportResponse{
+		BoundingBox: box,
+		Clustered:   args.Cluster,
+	}
+	var stopClusters, vehicleClusters *geo.Clusters
+	if args.Cluster {
+		response.Zoom = args.Zoom
+		if response.Zoom == 0 {
+			response.Zoom = geo.ZoomForSpan(box.East - box.West)
+		}
+		stopClusters = geo.NewClusters(geo.ZoomCellSize(response.Zoom))
+		vehicleClusters = geo.NewClusters(geo.ZoomCellSize(response.Zoom))
+	}
+
+	if args.layer(stopsLayer) {
+		stops := GlobalCatalog.StopsInBox(box)
+		response.TotalStops = len(stops)
+		if args.Cluster {
+			for _, stop := range stops {
+				stopClusters.Add(stop.Latitude, stop.Longitude)
+			}
+			response.StopClusters = types.ConvertClusters(stopClusters.List())
+		} else {
+			// Keep the stops nearest the center when the viewport holds too many
+			center := box.Center()
+			sort.Slice(stops, func(i, j int) bool {
+				return geo.Distance(center.Latitude, center.Longitude, stops[i].Latitude, stops[i].Longitude) <
+					geo.Distance(center.Latitude, center.Longitude, stops[j].Latitude, stops[j].Longitude)
+			})
+			if len(stops) > limit {
+				stops = stops[:limit]
+				response.Truncated = true
+			}
+			response.Stops = types.ConvertStops(stops)
+		}
+	}
+
+	if args.layer(vehiclesLayer) {
+		filter := types.VehicleFilter{
+			LinePrefix:     args.LinePrefix,
+			AccessibleOnly: accessibleOnly(call, args.AccessibleOnly),
+			BoundingBox:    &box,
+		}
+
+		if args.Cluster {
+			hour, err := GlobalClient.StreamVehiclePositions(ctx, func(line types.VehicleLine) {
+				if !filter.MatchLine(line) {
+					return
+				}
+				for _, vehicle := range line.Vehicles {
+					if filter.MatchVehicle(vehicle) {
+						vehicleClusters.Add(vehicle.Latitude, vehicle.Longitude)
+						response.TotalVehicles++
+					}
+				}
+			})
+			if err != nil {
+				return nil, pipeline.Failed("error.query_viewport", err)
+			}
+			response.Timestamp = hour
+			response.VehicleClusters = types.ConvertClusters(vehicleClusters.List())
+		} else {
+			page := types.NewVehiclePage(filter, types.VehicleCursor{}, limit, nil, false)
+			hour, err := GlobalClient.StreamVehiclePositions(ctx, page.Add)
+			if err != nil {
+				return nil, pipeline.Failed("error.query_viewport", err)
+			}
+			positions := page.Response(hour)
+			response.Timestamp = hour
+			response.TotalVehicles = positions.TotalVehicles
+			response.Lines = positions.Positions.Lines
+			if positions.NextCursor != "" {
+				response.Truncated = true
+			}
+		}
+	}
+
+	return response, nil
+}
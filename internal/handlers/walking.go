@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"math"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	defaultTransferRadius = 300
+	maxTransferRadius     = 1000
+	defaultTransferLimit  = 20
+	maxTransferLimit      = 100
+	minDetourFactor       = 1.0
+	maxDetourFactor       = 3.0
+	minWalkingSpeed       = 1.0  // km/h
+	maxWalkingSpeed       = 10.0 // km/h
+)
+
+// WalkingDistanceParams defines the parameters for estimating a walk between two points
+type WalkingDistanceParams struct {
+	FromStopCode Ref               `json:"from_stop_code,omitempty" jsonschema:"Start at this stop: a stop code, a stop name or an address"`
+	From         *types.Coordinate `json:"from,omitempty" jsonschema:"Start at this coordinate"`
+	FromPlace    string            `json:"from_place,omitempty" jsonschema:"Start at this street address or stop name"`
+	ToStopCode   Ref               `json:"to_stop_code,omitempty" jsonschema:"End at this stop: a stop code, a stop name or an address"`
+	To           *types.Coordinate `json:"to,omitempty" jsonschema:"End at this coordinate"`
+	ToPlace      string            `json:"to_place,omitempty" jsonschema:"End at this street address or stop name"`
+	DetourFactor float64           `json:"detour_factor,omitempty" jsonschema:"Ratio of the street distance to the straight-line distance (1-3), defaults to the server setting"`
+	SpeedKmh     float64           `json:"speed_kmh,omitempty" jsonschema:"Walking speed in km/h (1-10), defaults to the server setting"`
+	Format       string            `json:"format,omitempty"`
+}
+
+// Validate checks the walking_distance arguments
+func (p WalkingDistanceParams) Validate() error {
+	if err := validEndpoint("from", p.FromStopCode, p.From, p.FromPlace); err != nil {
+		return err
+	}
+	if err := validEndpoint("to", p.ToStopCode, p.To, p.ToPlace); err != nil {
+		return err
+	}
+	if p.DetourFactor != 0 && (p.DetourFactor < minDetourFactor || p.DetourFactor > maxDetourFactor) {
+		return pipeline.Invalid("error.detour_range", minDetourFactor, maxDetourFactor)
+	}
+	if p.SpeedKmh != 0 && (p.SpeedKmh < minWalkingSpeed || p.SpeedKmh > maxWalkingSpeed) {
+		return pipeline.Invalid("error.speed_range", minWalkingSpeed, maxWalkingSpeed)
+	}
+	return nil
+}
+
+// validEndpoint checks that exactly one of a stop, a coordinate or a place
+// locates an end of a walk
+func validEndpoint(name string, stop Ref, coordinate *types.Coordinate, place string) error {
+	given := 0
+	for _, ok := range []bool{stop != nil, coordinate != nil, place != ""} {
+		if ok {
+			given++
+		}
+	}
+	if given != 1 {
+		return pipeline.Invalid("error.endpoint", name)
+	}
+	if stop != nil {
+		if err := validStopRef(name+"_stop_code", stop); err != nil {
+			return err
+		}
+	}
+	if coordinate != nil && !geo.ValidCoordinate(coordinate.Latitude, coordinate.Longitude) {
+		return pipeline.Invalid("error.coordinate")
+	}
+	return nil
+}
+
+// WalkingDistance handles the walking_distance MCP tool
+func WalkingDistance(ctx context.Context, call *pipeline.Call, args WalkingDistanceParams) (any, error) {
+	walker := GlobalWalker
+	if args.DetourFactor != 0 {
+		walker.DetourFactor = args.DetourFactor
+	}
+	if args.SpeedKmh != 0 {
+		walker.Speed = args.SpeedKmh / 3.6
+	}
+
+	from, err := resolveEndpoint(ctx, "from", args.FromStopCode, args.From, args.FromPlace)
+	if err != nil {
+		return nil, err
+	}
+	to, err := resolveEndpoint(ctx, "to", args.ToStopCode, args.To, args.ToPlace)
+	if err != nil {
+		return nil, err
+	}
+
+	distance := geo.Distance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	return types.WalkingDistanceResponse{
+		From:           from,
+		To:             to,
+		DistanceMeters: int(math.Round(distance)),
+		WalkingMeters:  int(math.Round(walker.Distance(distance))),
+		WalkingSeconds: int(math.Round(walker.Time(distance))),
+		WalkingMinutes: int(math.Ceil(walker.Time(distance) / 60)),
+		DetourFactor:   walker.DetourFactor,
+		SpeedKmh:       math.Round(walker.Speed*3.6*10) / 10,
+	}, nil
+}
+
+// resolveEndpoint locates an end of a walk given by a stop, a coordinate or a place
+func resolveEndpoint(ctx context.Context, name string, ref Ref, coordinate *types.Coordinate, place string) (types.WalkEndpointResponse, error) {
+	switch {
+	case ref != nil:
+		stop, err := resolveStopRef(ctx, name+"_stop_code", ref)
+		if err != nil {
+			return types.WalkEndpointResponse{}, err
+		}
+		if stop, err = locateStop(ctx, stop); err != nil {
+			return types.WalkEndpointResponse{}, err
+		}
+		return types.WalkEndpointResponse{
+			Name:      stop.Name,
+			StopCode:  stop.Code,
+			Latitude:  stop.Latitude,
+			Longitude: stop.Longitude,
+		}, nil
+	case coordinate != nil:
+		return types.WalkEndpointResponse{Latitude: coordinate.Latitude, Longitude: coordinate.Longitude}, nil
+	}
+	lat, lon, label, err := resolvePlace(place, 0, 0)
+	if err != nil {
+		return types.WalkEndpointResponse{}, err
+	}
+	return types.WalkEndpointResponse{Name: label, Latitude: lat, Longitude: lon}, nil
+}
+
+// locateStop fills in the location of a stop missing from the catalog from
+// the predictions at the stop, which report where it is
+func locateStop(ctx context.Context, stop types.Stop) (types.Stop, error) {
+	if stop.Latitude != 0 || stop.Longitude != 0 {
+		return stop, nil
+	}
+	predictions, err := GlobalClient.GetArrivalPredictionsByStop(ctx, stop.Code)
+	if err != nil {
+		return types.Stop{}, pipeline.Failed("error.resolve_stop", err)
+	}
+	for _, s := range predictions.Stops {
+		if s.Latitude != 0 || s.Longitude != 0 {
+			stop.Latitude, stop.Longitude = s.Latitude, s.Longitude
+			if stop.Name == "" {
+				stop.Name = s.Name
+			}
+			return stop, nil
+		}
+	}
+	return types.Stop{}, pipeline.Missing("error.stop_location", stop.Code)
+}
+
+// NearbyTransfersParams defines the parameters for finding transfers around a stop
+type NearbyTransfersParams struct {
+	StopCode     Ref    `json:"stop_code" jsonschema:"The stop to transfer from: a stop code, a stop name or an address"`
+	RadiusMeters int    `json:"radius_meters,omitempty" jsonschema:"Maximum straight-line distance to the other stops, defaults to 300 (max 1000)"`
+	NewLinesOnly bool   `json:"new_lines_only,omitempty" jsonschema:"Only include stops served by a line that does not serve the given stop"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"Maximum number of stops to return, defaults to 20 (max 100)"`
+	Format       string `json:"format,omitempty"`
+}
+
+// Validate checks the nearby_transfers arguments
+func (p NearbyTransfersParams) Validate() error {
+	if err := validStopRef("stop_code", p.StopCode); err != nil {
+		return err
+	}
+	if p.RadiusMeters < 0 || p.RadiusMeters > maxTransferRadius {
+		return pipeline.Invalid("error.radius_range", maxTransferRadius)
+	}
+	if p.Limit < 0 || p.Limit > maxTransferLimit {
+		return pipeline.Invalid("error.limit_range", maxTransferLimit)
+	}
+	return nil
+}
+
+// NearbyTransfers handles the nearby_transfers MCP tool
+func NearbyTransfers(ctx context.Context, call *pipeline.Call, args NearbyTransfersParams) (any, error) {
+	radius := args.RadiusMeters
+	if radius == 0 {
+		radius = defaultTransferRadius
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultTransferLimit
+	}
+
+	ref, err := resolveStopRef(ctx, "stop_code", args.StopCode)
+	if err != nil {
+		return nil, err
+	}
+	stop, ok := GlobalCatalog.Stop(ref.Code)
+	if !ok {
+		return nil, pipeline.Missing("error.unknown_stop", ref.Code)
+	}
+	lines := GlobalCatalog.LinesServing(stop.Code)
+	serving := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		serving[line.Code] = true
+	}
+
+	response := types.NearbyTransfersResponse{
+		Stop:         types.ConvertStop(stop),
+		Lines:        types.ConvertLines(lines),
+		RadiusMeters: radius,
+		Transfers:    []types.TransferStopResponse{},
+	}
+	for _, n := range GlobalCatalog.StopsNear(stop.Latitude, stop.Longitude, float64(radius), 0) {
+		if n.Value.Code == stop.Code {
+			continue
+		}
+		other := GlobalCatalog.LinesServing(n.Value.Code)
+		newLines := 0
+		for _, line := range other {
+			if !serving[line.Code] {
+				newLines++
+			}
+		}
+		if args.NewLinesOnly && newLines == 0 {
+			continue
+		}
+		response.Transfers = append(response.Transfers, types.TransferStopResponse{
+			NearbyStopResponse: types.ConvertNearbyStop(n.Value, n.Distance, GlobalWalker.Distance(n.Distance), GlobalWalker.Time(n.Distance)),
+			Lines:              types.ConvertLines(other),
+			NewLines:           newLines,
+		})
+		if len(response.Transfers) == limit {
+			break
+		}
+	}
+	response.TotalResults = len(response.Transfers)
+	return response, nil
+}
@@ -0,0 +1,555 @@
+package i18n
+
+// catalog holds the messages of every locale, keyed by message key
+var catalog = map[Locale]map[string]string{
+	English: {
+		// Tool descriptions
+		"tool.search_lines":                    "Search for bus lines by name or number (partial or complete)",
+		"tool.search_line_by_direction":        "Search for a specific line in a specific direction, given as 1 or 2 or as the terminal or stop it heads towards",
+		"tool.resolve_direction":               "Find which direction of a line heads towards a terminal or stop, matching the terminal names and the stops of each itinerary, with circular lines handled as a single loop",
+		"tool.find_transfer_points":            "Find where to change between two lines given by number or code: the stops they share and the pairs of stops within walking distance, with the directions that connect them and optionally the live wait for the second line",
+		"tool.search_stops":                    "Search for bus stops by name or address (partial or complete)",
+		"tool.get_stops_by_line":               "Get all stops served by a specific line",
+		"tool.find_stops_near":                 "Find known stops within a radius of a coordinate, nearest first, with walking distance and time estimates",
+		"tool.walking_distance":                "Estimate the walking distance and time between two stops, coordinates or places, with an optional detour factor and walking speed",
+		"tool.nearby_transfers":                "List the other stops within walking distance of a stop and the lines serving them, to find transfers",
+		"tool.get_vehicle_positions":           "Get real-time positions of vehicles, filtered by line prefix, vehicle prefix area, accessibility or bounding box, paginated with limit/cursor, with optional field projection or per-line summary",
+		"tool.get_vehicle_positions_by_line":   "Get real-time positions of vehicles on a specific line, given by code, sign such as 875A-10/1 or search term",
+		"tool.get_accessible_fleet":            "Get the share of wheelchair-accessible vehicles running each line from live positions, lines with the smallest share first, with network totals",
+		"tool.find_vehicles_near":              "Find live vehicles within a radius of a coordinate, nearest first, with their line, heading terminal, distance, bearing and position age",
+		"tool.query_viewport":                  "Get the known stops and live vehicles inside a map viewport given by its south-west and north-east corners, optionally aggregated into zoom-dependent clusters",
+		"tool.render_map":                      "Draw a PNG map of a line's stops and live vehicles, with an optional highlighted stop, a legend and a scale bar",
+		"tool.get_vehicle_progress":            "Locate the live vehicles of a line along its route: previous and next stop, distance travelled and, for a given stop, the distance remaining and the number of stops away",
+		"tool.create_geofence":                 "Watch a circular or polygonal area for vehicles of a line, or a single vehicle, entering or leaving it; events are sent as notice log messages to clients that enabled logging with logging/setLevel, kept for get_geofence_events and optionally posted to a local webhook",
+		"tool.update_geofence":                 "Change the area, filters or webhook of a geofence; a filter or webhook given as 0 or an empty string is removed",
+		"tool.delete_geofence":                 "Stop watching a geofence",
+		"tool.list_geofences":                  "List the geofences with the vehicles inside each one",
+		"tool.get_geofence_events":             "Get the most recent enter and exit events of the geofences",
+		"tool.get_arrival_predictions":         "Get arrival predictions for vehicles at a specific stop and line. Stops may be given by code, name or address and lines by code, sign such as 875A-10/1 or search term; an ambiguous one is answered with the candidates to choose from",
+		"tool.get_arrival_predictions_by_line": "Get all arrival predictions for a specific line, given by code, sign such as 875A-10/1 or search term",
+		"tool.get_arrival_predictions_by_stop": "Get all arrival predictions for a specific stop, given by code, name or address",
+		"tool.departure_board":                 "Get the next departures from a stop as a single list, soonest first, with line, destination, minutes until arrival, accessibility and vehicle distance, optionally filtered by lines and by direction or the terminal or stop they head towards",
+		"tool.get_predictions_batch":           "Get the next departures from several stops, each with an optional line, in one call, fetching each stop once; a query that fails is reported in its item without failing the others",
+		"tool.leave_advice":                    "Tell a rider at a coordinate, address or saved place which bus they can still catch at a stop: the latest time to leave, walking there at their speed, compared with the live predictions, with a confidence from how fresh each bus position is",
+		"tool.plan_trip":                       "Plan a trip between two stops, coordinates or places leaving now, with walks, bus rides and transfers ranked by estimated arrival using live waits",
+		"tool.isochrone":                       "Find the stops that can be reached from a stop, coordinate or place leaving now within time budgets such as 10, 20 and 30 minutes, with ride times from live vehicle speeds, waits from the number of running buses, and optional GeoJSON polygons",
+		"tool.calculate_fare":                  "Calculate the Bilhete Único fare of a sequence of bus and metro or train boardings for the full, student or elderly category, applying the integration window and boarding limits",
+		"tool.geocode_address":                 "Locate a free-text place, such as a street address with a number or a stop name, using the offline gazetteer built from the stop catalog",
+		"tool.reverse_geocode":                 "Describe a coordinate by its nearest street, estimated building number, nearest stop and cross streets, using the offline gazetteer",
+		"tool.get_catalog_status":              "Get the version and size of the offline network catalog and the progress of its crawler",
+		"tool.set_preferences":                 "Set preferences for this session, such as the language of messages and rendered text, whether only wheelchair-accessible vehicles are shown and named places such as home or work",
+		"tool.get_server_metrics":              "Get call counts, error counts and durations of every tool since the server started",
+
+		// Validation and failure messages
+		"error.required":                        "%s parameter is required",
+		"error.positive_integer":                "%s parameter must be a positive integer",
+		"error.direction":                       "direction parameter must be 1 or 2",
+		"error.limit_range":                     "limit parameter must be between 1 and %d",
+		"error.prefix_area":                     "prefix_area must be an operating area between 1 and %d",
+		"error.invalid_cursor":                  "cursor parameter is invalid",
+		"error.unknown_field":                   "fields parameter contains unknown field %q",
+		"error.bounding_box":                    "bounding_box parameter must have south < north and west < east",
+		"error.coordinate":                      "latitude must be between -90 and 90 and longitude between -180 and 180",
+		"error.coordinate_or_place":             "either place or a valid latitude and longitude is required",
+		"error.geofence_shape":                  "give either center or place with radius_meters for a circle, or polygon",
+		"error.invalid_geofence":                "Invalid geofence: %v",
+		"error.unknown_geofence":                "Geofence %q does not exist",
+		"error.geofences_disabled":              "Geofences are disabled on this server",
+		"error.place_not_found":                 "No street or stop matches %q",
+		"error.endpoint":                        "exactly one of %[1]s_stop_code, %[1]s or %[1]s_place is required",
+		"error.detour_range":                    "detour_factor parameter must be between %g and %g",
+		"error.speed_range":                     "speed_kmh parameter must be between %g and %g",
+		"error.transfers_range":                 "max_transfers parameter must be between 0 and %d",
+		"error.walk_range":                      "max_walk_meters parameter must be between %d and %d",
+		"error.unknown_line":                    "No line matches %q",
+		"error.find_transfer_points":            "Failed to find transfer points: %v",
+		"error.resolve_direction":               "Failed to resolve the direction: %v",
+		"error.direction_or_towards":            "give either direction or towards, not both",
+		"error.towards_unknown":                 "No direction heads towards %q: %s",
+		"error.towards_ambiguous":               "Several directions head towards %q: %s; name a terminal or a stop closer to the destination",
+		"error.fare_legs":                       "legs must have between 1 and %d boardings",
+		"error.fare_mode":                       "Invalid mode %q: use bus or rail",
+		"error.fare_time":                       "Invalid board_at %q: use HH:MM",
+		"error.fare_category":                   "category must be one of %s",
+		"error.calculate_fare":                  "Failed to calculate the fare: %v",
+		"error.isochrone":                       "Failed to compute the isochrone: %v",
+		"error.isochrone_minutes":               "minutes must hold up to %[2]d time budgets between 1 and %[1]d",
+		"error.radius_range":                    "radius_meters parameter must be between 1 and %d",
+		"error.viewport":                        "south_west must be south and west of north_east",
+		"error.unknown_layer":                   "layers parameter contains unknown layer %q",
+		"error.zoom_range":                      "zoom parameter must be between 1 and %d",
+		"error.map_size":                        "width and height parameters must be between %d and %d",
+		"error.no_stops":                        "No stops found for line %d",
+		"error.unknown_stop":                    "Stop %d is not known",
+		"error.line_ref":                        "%s must be a line code, a sign such as 875A-10/1 or a search term",
+		"error.stop_ref":                        "%s must be a stop code, a stop name or an address",
+		"error.resolve_line":                    "Failed to look up the line: %v",
+		"error.resolve_stop":                    "Failed to look up the stop: %v",
+		"error.ambiguous_line":                  "%q matches %d lines; call again with one of these codes as %s:",
+		"error.ambiguous_stop":                  "%q matches %d stops; call again with one of these codes as %s:",
+		"error.no_stop_match":                   "No stop matches %q",
+		"error.candidate":                       "- %d: %s (%s)",
+		"error.invalid_format":                  "Invalid format parameter: %v",
+		"error.invalid_locale":                  "Invalid locale parameter: %v",
+		"error.render":                          "Failed to render response: %v",
+		"error.internal":                        "Internal error: %v",
+		"error.timeout":                         "The request took too long and was cancelled",
+		"error.search_lines":                    "Failed to search lines: %v",
+		"error.search_line_by_direction":        "Failed to search line by direction: %v",
+		"error.search_stops":                    "Failed to search stops: %v",
+		"error.get_stops_by_line":               "Failed to get stops by line: %v",
+		"error.get_stops_by_corridor":           "Failed to get stops by corridor: %v",
+		"error.get_vehicle_positions":           "Failed to get vehicle positions: %v",
+		"error.get_vehicle_positions_by_line":   "Failed to get vehicle positions by line: %v",
+		"error.get_accessible_fleet":            "Failed to get the accessible fleet: %v",
+		"error.find_vehicles_near":              "Failed to find vehicles near the point: %v",
+		"error.query_viewport":                  "Failed to query the viewport: %v",
+		"error.render_map":                      "Failed to render the map: %v",
+		"error.get_vehicle_progress":            "Failed to locate the vehicles along the route: %v",
+		"error.stop_not_on_line":                "Stop %d is not served by line %d",
+		"error.get_arrival_predictions":         "Failed to get arrival predictions: %v",
+		"error.get_arrival_predictions_by_line": "Failed to get arrival predictions by line: %v",
+		"error.get_arrival_predictions_by_stop": "Failed to get arrival predictions by stop: %v",
+		"error.departure_board":                 "Failed to get the departure board: %v",
+		"error.leave_advice":                    "Failed to get the predictions to advise when to leave: %v",
+		"error.leave_origin":                    "exactly one of from or from_place is required",
+		"error.margin_range":                    "margin_minutes parameter must be between 0 and %d",
+		"error.stop_location":                   "The location of stop %d is not known",
+		"error.leave_too_far":                   "The stop is %.1f km away, too far to walk",
+		"error.save_place":                      "save_place needs exactly one of location or place",
+		"error.unknown_place":                   "No saved place is named %q",
+		"error.places_limit":                    "At most %d places can be saved",
+		"error.batch_size":                      "queries must list between 1 and %d stops",
+
+		// Rendered text
+		"render.lines_found":          "%d lines found for %q",
+		"render.line":                 "%s (code %d) %s → %s",
+		"render.circular":             " (circular)",
+		"render.resolved":             "Take %s (code %d, direction %d) towards %s",
+		"render.unresolved":           "No single direction heads towards %s",
+		"render.direction_match":      "%s (code %d, direction %d) → %s: score %.2f",
+		"render.direction_terminal":   ", matches terminal %s",
+		"render.direction_stop":       ", passes stop %s",
+		"render.stops_found":          "%d stops found for %q",
+		"render.stops_on_line":        "%d stops on line %d",
+		"render.stops_in_corridor":    "%d stops in corridor %d",
+		"render.stop":                 "%s (code %d) — %s",
+		"render.stop_short":           "%s (code %d)",
+		"render.stops_near":           "%d stops within %d m of %.5f, %.5f",
+		"render.nearby_stop":          "%s (code %d) — %d m walking, about %d min",
+		"render.walk":                 "%s → %s: %d m walking, about %d min",
+		"render.walk_estimate":        "%d m in a straight line, detour factor %.2g at %.1f km/h",
+		"render.walk_point":           "%.5f, %.5f",
+		"render.transfers":            "%d stops within %d m of %s (code %d)",
+		"render.transfer_lines":       "Lines at this stop: %s",
+		"render.transfer_stop":        "  Lines: %s (%d new)",
+		"render.transfer_points":      "%d transfer points from %s to %s within %d m",
+		"render.no_transfer_points":   "The lines do not pass within walking distance of each other",
+		"render.transfer_shared":      "%s (code %d), served by both lines",
+		"render.transfer_walk":        "%s (code %d) → %s (code %d), %d m walking, about %d min",
+		"render.transfer_connection":  "  %s towards %s → %s towards %s",
+		"render.transfer_wait":        ", next bus in %d min",
+		"render.trip":                 "Trips from %s to %s leaving at %s",
+		"render.trip_none":            "No trip found; the stops near the origin or destination may not be in the network catalog yet",
+		"render.trip_option":          "Option %d: arrive at %s (%d min), %d transfers, %d m walking",
+		"render.trip_walk":            "  %s walk %d m to %s",
+		"render.trip_ride":            "  %s take %s towards %s at %s after %d min%s, ride %d stops to %s, arrive at %s",
+		"render.trip_live":            " (live)",
+		"render.isochrone":            "Reachable from %s leaving at %s",
+		"render.isochrone_live":       "%d lines running, %d with speeds measured from live positions at %s",
+		"render.isochrone_budget":     "Within %d min: %d stops",
+		"render.isochrone_compact":    "%d min %d stops",
+		"render.isochrone_none":       "No stop can be reached within these budgets",
+		"render.reachable_stop":       "%s (code %d) in %d min, %d buses",
+		"render.reachable_compact":    "%d %d min",
+		"render.trip_estimated":       " (estimated)",
+		"render.trip_fare":            ", fare %s",
+		"render.fare":                 "Fare %s (%s), %d fares paid",
+		"render.fare_leg":             "%d. %s: %s",
+		"render.fare_at":              " at %s",
+		"render.fare_bus":             "bus",
+		"render.fare_rail":            "metro or train",
+		"render.fare_integrated":      ", integrated",
+		"render.fare_break_window":    ", integration broken: more than %d min since the first boarding",
+		"render.fare_break_boardings": ", integration broken: limit of %d boardings reached",
+		"render.fare_break_rail":      ", integration broken: a second metro or train entry",
+		"render.money":                "R$%.2f",
+		"render.empty_catalog":        "No stops are known yet; the network catalog is still being built, or search stops or lines so the server can index them",
+		"render.vehicles_on_lines":    "%d vehicles on %d lines at %s",
+		"render.line_counts":          "%s → %s: %d vehicles (%d accessible)",
+		"render.more_results":         "More results available with cursor %s",
+		"render.vehicles_on_line":     "%d vehicles on line %d at %s",
+		"render.line_vehicles":        "%s → %s (%d vehicles)",
+		"render.vehicle":              "Vehicle %d at %.5f, %.5f",
+		"render.vehicle_compact":      "%s %d %.5f,%.5f%s",
+		"render.vehicles_near":        "%d vehicles within %d m of %.5f, %.5f at %s",
+		"render.nearby_vehicle":       "%s → %s: vehicle %d, %d m %s, updated %d s ago",
+		"render.viewport":             "%d stops and %d vehicles in the viewport at %s",
+		"render.clusters_zoom":        "Clusters at zoom %d",
+		"render.stop_cluster":         "%d stops around %.5f, %.5f",
+		"render.vehicle_cluster":      "%d vehicles around %.5f, %.5f",
+		"render.viewport_truncated":   "Some points were left out; zoom in or enable clustering to see them all",
+		"render.map_line":             "Line %d",
+		"render.map_stop":             "Stop",
+		"render.map_highlight":        "Selected stop",
+		"render.map_vehicle":          "Bus",
+		"render.map_accessible":       "Accessible bus",
+		"render.map_summary":          "Map of %s: %d stops, %d vehicles (%d accessible), scale bar %d m",
+		"render.vehicle_progress":     "%d vehicles on line %d along %d stops (%.1f km, %s route) at %s",
+		"render.vehicle_progress_to":  "%d vehicles on line %d towards stop %s (#%d of %d) at %s",
+		"render.progress_between":     "vehicle %d between %s and %s, %.1f km travelled",
+		"render.progress_remaining":   ", %d stops / %d m away",
+		"render.progress_passed":      ", already passed",
+		"render.progress_off_route":   ", off route (%d m)",
+		"render.route_start":          "the start",
+		"render.route_end":            "the end",
+		"render.geocoded_place":       "Located %s",
+		"render.geocode":              "%d locations for %q",
+		"render.geocode_result":       "%s at %.5f, %.5f (%s, score %.2f)",
+		"render.geocode_stop":         "  nearest stop: %s (code %d)",
+		"render.street_number":        "%s, %d",
+		"render.reverse_geocode":      "%s (%s)",
+		"render.nearest_stop":         "nearest stop: %s (code %d), %d m",
+		"render.cross_streets":        "nearby: %s",
+		"render.no_street":            "No known street near %.5f, %.5f",
+		"render.at_number":            "stop at this number",
+		"render.interpolated":         "interpolated between stops",
+		"render.nearest_number":       "nearest known number",
+		"render.street_only":          "street only",
+		"render.by_stop_name":         "stop name",
+		"render.coordinate":           "coordinate",
+		"render.geofences":            "%d geofences, evaluated every %d s",
+		"render.geofence":             "%s %s: %s, %d vehicles inside",
+		"render.geofence_circle":      "%d m around %.5f, %.5f",
+		"render.geofence_polygon":     "polygon of %d points",
+		"render.geofence_vehicle":     "  vehicle %d",
+		"render.geofence_lines":       "  lines %s*",
+		"render.geofence_line":        "  line %d",
+		"render.geofence_webhook":     "  webhook %s",
+		"render.geofence_error":       "Last evaluation failed: %s",
+		"render.geofence_deleted":     "Geofence %s deleted",
+		"render.geofence_events":      "%d geofence events",
+		"render.geofence_enter":       "%s vehicle %d entered %s at %s",
+		"render.geofence_exit":        "%s vehicle %d left %s at %s",
+		"render.accessible":           " (accessible)",
+		"render.predictions_for_line": "%d predictions for line %d across %d stops at %s",
+		"render.no_predictions":       "No predictions for stop %d at %s",
+		"render.stop_heading":         "Stop %s (code %d) at %s",
+		"render.arrives":              "arrives %s",
+		"render.in_minutes":           " (in %d min%s)",
+		"render.accessible_suffix":    ", accessible",
+		"render.departure_board":      "Next departures from %s (code %d) at %s",
+		"render.departure":            "%s → %s in %d min (%s)%s",
+		"render.departure_distance":   ", vehicle %.1f km away",
+		"render.departure_compact":    "%s %d min",
+		"render.next_accessible":      "Next accessible %s → %s in %d min (%s)",
+		"render.no_accessible":        "No accessible bus predicted for %s → %s",
+		"render.batch":                "%d of %d queries answered, %d stops fetched",
+		"render.batch_query":          "#%d %s",
+		"render.batch_failed":         "#%d %s: %s",
+		"render.candidate":            "%d: %s (%s)",
+		"render.trip_accessible":      "Waiting for wheelchair-accessible buses only",
+		"render.trip_no_accessible":   "%d options were left out as no accessible bus is predicted for one of their rides",
+		"render.fleet":                "%d of %d running vehicles are accessible (%.1f%%) across %d lines at %s",
+		"render.fleet_line":           "%s: %d of %d accessible (%.1f%%)",
+		"render.fleet_compact":        "%s %.1f%%",
+		"render.fleet_none":           "No running vehicles found at %s",
+		"render.stop_compact":         "Stop %s — %s %s",
+		"render.stop_arrival":         "%s → %s %s",
+		"render.catalog_status":       "Catalog version %d: %d lines, %d stops, %d corridors",
+		"render.catalog_updated":      "Last updated at %s",
+		"render.crawler_disabled":     "Crawler disabled",
+		"render.crawler_running":      "Crawling: %d lines fetched, %d pending, %d failures",
+		"render.crawler_next":         "Next crawl at %s",
+		"render.crawler_error":        "Last error: %s",
+		"render.preferences":          "Session preferences: locale %s",
+		"render.preferences_access":   "Only wheelchair-accessible vehicles are shown",
+		"render.preferences_place":    "Saved place %s: %s",
+		"render.leave":                "Walk %d m (%d min) from %s to %s (code %d) at %s, arriving %d min early",
+		"render.leave_by":             "Leave by %s (in %d min) to catch %s → %s at %s, %s confidence",
+		"render.leave_none":           "No bus you can still catch is predicted",
+		"render.leave_option":         "%s → %s at %s: leave by %s (in %d min), position %ds old",
+		"render.leave_missed":         "%d buses arrive too soon to catch",
+		"render.leave_compact":        "%s leave %s (%s)",
+		"render.confidence_high":      "high",
+		"render.confidence_medium":    "medium",
+		"render.confidence_low":       "low",
+		"render.here":                 "your position",
+		"render.tool_metrics":         "%s: %d calls, %d errors, average %d ms, max %d ms",
+	},
+	Portuguese: {
+		// Tool descriptions
+		"tool.search_lines":                    "Busca linhas de ônibus por nome ou número (parcial ou completo)",
+		"tool.search_line_by_direction":        "Busca uma linha específica em um sentido específico, informado como 1 ou 2 ou pelo terminal ou parada para onde segue",
+		"tool.resolve_direction":               "Descobre qual sentido de uma linha segue para um terminal ou parada, comparando os nomes dos terminais e as paradas de cada itinerário, tratando linhas circulares como uma única volta",
+		"tool.find_transfer_points":            "Encontra onde trocar entre duas linhas informadas por número ou código: as paradas em comum e os pares de paradas a uma distância caminhável, com os sentidos que as conectam e opcionalmente a espera em tempo real pela segunda linha",
+		"tool.search_stops":                    "Busca paradas de ônibus por nome ou endereço (parcial ou completo)",
+		"tool.get_stops_by_line":               "Obtém todas as paradas atendidas por uma linha",
+		"tool.find_stops_near":                 "Encontra as paradas conhecidas em um raio ao redor de uma coordenada, da mais próxima à mais distante, com estimativas de distância e tempo de caminhada",
+		"tool.walking_distance":                "Estima a distância e o tempo de caminhada entre duas paradas, coordenadas ou lugares, com fator de desvio e velocidade de caminhada opcionais",
+		"tool.nearby_transfers":                "Lista as outras paradas a uma distância caminhável de uma parada e as linhas que as atendem, para encontrar baldeações",
+		"tool.get_vehicle_positions":           "Obtém as posições em tempo real dos veículos, filtradas por prefixo de linha, área do prefixo do veículo, acessibilidade ou retângulo geográfico, paginadas com limit/cursor, com projeção de campos opcional ou resumo por linha",
+		"tool.get_vehicle_positions_by_line":   "Obtém as posições em tempo real dos veículos de uma linha, informada por código, letreiro como 875A-10/1 ou termo de busca",
+		"tool.get_accessible_fleet":            "Obtém a parcela de veículos acessíveis para cadeirantes em operação em cada linha a partir das posições em tempo real, das linhas com menor parcela para as de maior, com os totais da rede",
+		"tool.find_vehicles_near":              "Encontra os veículos em circulação em um raio ao redor de uma coordenada, do mais próximo ao mais distante, com linha, destino, distância, direção e idade da posição",
+		"tool.query_viewport":                  "Obtém as paradas conhecidas e os veículos em circulação dentro de uma área do mapa definida pelos cantos sudoeste e nordeste, opcionalmente agregados em grupos conforme o zoom",
+		"tool.render_map":                      "Desenha um mapa PNG das paradas e dos veículos em circulação de uma linha, com uma parada destacada opcional, legenda e barra de escala",
+		"tool.get_vehicle_progress":            "Localiza os veículos em circulação de uma linha ao longo do itinerário: parada anterior e próxima, distância percorrida e, para uma parada informada, a distância restante e quantas paradas faltam",
+		"tool.create_geofence":                 "Monitora uma área circular ou poligonal para veículos de uma linha, ou um único veículo, entrando ou saindo; os eventos são enviados como mensagens de log notice aos clientes que ativaram o log com logging/setLevel, guardados para get_geofence_events e opcionalmente enviados a um webhook local",
+		"tool.update_geofence":                 "Altera a área, os filtros ou o webhook de uma cerca virtual; um filtro ou webhook informado como 0 ou texto vazio é removido",
+		"tool.delete_geofence":                 "Deixa de monitorar uma cerca virtual",
+		"tool.list_geofences":                  "Lista as cercas virtuais com os veículos dentro de cada uma",
+		"tool.get_geofence_events":             "Obtém os eventos mais recentes de entrada e saída das cercas virtuais",
+		"tool.get_arrival_predictions":         "Obtém a previsão de chegada dos veículos de uma linha em uma parada. A parada pode ser informada por código, nome ou endereço e a linha por código, letreiro como 875A-10/1 ou termo de busca; uma referência ambígua é respondida com as opções a escolher",
+		"tool.get_arrival_predictions_by_line": "Obtém todas as previsões de chegada de uma linha, informada por código, letreiro como 875A-10/1 ou termo de busca",
+		"tool.get_arrival_predictions_by_stop": "Obtém todas as previsões de chegada em uma parada, informada por código, nome ou endereço",
+		"tool.departure_board":                 "Obtém as próximas partidas de uma parada em uma única lista, da mais próxima à mais distante, com linha, destino, minutos até a chegada, acessibilidade e distância do veículo, com filtro opcional por linhas e por sentido ou pelo terminal ou parada para onde seguem",
+		"tool.get_predictions_batch":           "Obtém em uma só chamada as próximas partidas de várias paradas, cada uma com uma linha opcional, consultando cada parada uma única vez; uma consulta que falha é informada no seu item sem afetar as demais",
+		"tool.leave_advice":                    "Informa a quem está em uma coordenada, endereço ou lugar salvo qual ônibus ainda dá para pegar em uma parada: o horário limite para sair, caminhando na sua velocidade, comparado às previsões em tempo real, com uma confiança conforme a idade da posição de cada ônibus",
+		"tool.plan_trip":                       "Planeja uma viagem entre duas paradas, coordenadas ou lugares saindo agora, com caminhadas, trechos de ônibus e baldeações ordenados pela chegada estimada usando as esperas em tempo real",
+		"tool.isochrone":                       "Encontra as paradas que podem ser alcançadas a partir de uma parada, coordenada ou lugar saindo agora dentro de limites de tempo como 10, 20 e 30 minutos, com tempos de viagem pelas velocidades dos veículos em tempo real, esperas pelo número de ônibus em operação e polígonos GeoJSON opcionais",
+		"tool.calculate_fare":                  "Calcula a tarifa do Bilhete Único de uma sequência de embarques em ônibus e metrô ou trem para as categorias inteira, estudante ou idoso, aplicando a janela de integração e o limite de embarques",
+		"tool.geocode_address":                 "Localiza um lugar em texto livre, como um endereço com número ou o nome de uma parada, usando o dicionário de ruas construído a partir do catálogo de paradas",
+		"tool.reverse_geocode":                 "Descreve uma coordenada pela rua mais próxima, o número estimado, a parada mais próxima e as ruas transversais, usando o dicionário de ruas",
+		"tool.get_catalog_status":              "Obtém a versão e o tamanho do catálogo offline da rede e o progresso do seu rastreador",
+		"tool.set_preferences":                 "Define as preferências desta sessão, como o idioma das mensagens e do texto gerado, se apenas veículos acessíveis para cadeirantes são exibidos e lugares com nome, como casa ou trabalho",
+		"tool.get_server_metrics":              "Obtém o número de chamadas, de erros e a duração de cada ferramenta desde o início do servidor",
+
+		// Validation and failure messages
+		"error.required":                        "o parâmetro %s é obrigatório",
+		"error.positive_integer":                "o parâmetro %s deve ser um número inteiro positivo",
+		"error.direction":                       "o parâmetro direction deve ser 1 ou 2",
+		"error.limit_range":                     "o parâmetro limit deve estar entre 1 e %d",
+		"error.prefix_area":                     "o parâmetro prefix_area deve ser uma área de operação entre 1 e %d",
+		"error.invalid_cursor":                  "o parâmetro cursor é inválido",
+		"error.unknown_field":                   "o parâmetro fields contém o campo desconhecido %q",
+		"error.bounding_box":                    "o parâmetro bounding_box deve ter south < north e west < east",
+		"error.coordinate":                      "a latitude deve estar entre -90 e 90 e a longitude entre -180 e 180",
+		"error.coordinate_or_place":             "informe place ou uma latitude e longitude válidas",
+		"error.geofence_shape":                  "informe center ou place com radius_meters para um círculo, ou polygon",
+		"error.invalid_geofence":                "Cerca virtual inválida: %v",
+		"error.unknown_geofence":                "A cerca virtual %q não existe",
+		"error.geofences_disabled":              "As cercas virtuais estão desativadas neste servidor",
+		"error.place_not_found":                 "Nenhuma rua ou parada corresponde a %q",
+		"error.endpoint":                        "informe exatamente um entre %[1]s_stop_code, %[1]s e %[1]s_place",
+		"error.detour_range":                    "o parâmetro detour_factor deve estar entre %g e %g",
+		"error.speed_range":                     "o parâmetro speed_kmh deve estar entre %g e %g",
+		"error.transfers_range":                 "o parâmetro max_transfers deve estar entre 0 e %d",
+		"error.walk_range":                      "o parâmetro max_walk_meters deve estar entre %d e %d",
+		"error.unknown_line":                    "Nenhuma linha corresponde a %q",
+		"error.find_transfer_points":            "Falha ao encontrar pontos de baldeação: %v",
+		"error.resolve_direction":               "Falha ao identificar o sentido: %v",
+		"error.direction_or_towards":            "informe direction ou towards, não ambos",
+		"error.towards_unknown":                 "Nenhum sentido segue para %q: %s",
+		"error.towards_ambiguous":               "Vários sentidos seguem para %q: %s; informe um terminal ou uma parada mais próxima do destino",
+		"error.fare_legs":                       "legs deve ter entre 1 e %d embarques",
+		"error.fare_mode":                       "Modo inválido %q: use bus ou rail",
+		"error.fare_time":                       "board_at inválido %q: use HH:MM",
+		"error.fare_category":                   "category deve ser uma de %s",
+		"error.calculate_fare":                  "Falha ao calcular a tarifa: %v",
+		"error.isochrone":                       "Falha ao calcular a isócrona: %v",
+		"error.isochrone_minutes":               "minutes deve conter até %[2]d limites de tempo entre 1 e %[1]d",
+		"error.radius_range":                    "o parâmetro radius_meters deve estar entre 1 e %d",
+		"error.viewport":                        "south_west deve estar ao sul e a oeste de north_east",
+		"error.unknown_layer":                   "o parâmetro layers contém a camada desconhecida %q",
+		"error.zoom_range":                      "o parâmetro zoom deve estar entre 1 e %d",
+		"error.map_size":                        "os parâmetros width e height devem estar entre %d e %d",
+		"error.no_stops":                        "Nenhuma parada encontrada para a linha %d",
+		"error.unknown_stop":                    "A parada %d não é conhecida",
+		"error.line_ref":                        "%s deve ser um código de linha, um letreiro como 875A-10/1 ou um termo de busca",
+		"error.stop_ref":                        "%s deve ser um código de parada, o nome de uma parada ou um endereço",
+		"error.resolve_line":                    "Falha ao consultar a linha: %v",
+		"error.resolve_stop":                    "Falha ao consultar a parada: %v",
+		"error.ambiguous_line":                  "%q corresponde a %d linhas; chame novamente com um destes códigos em %s:",
+		"error.ambiguous_stop":                  "%q corresponde a %d paradas; chame novamente com um destes códigos em %s:",
+		"error.no_stop_match":                   "Nenhuma parada corresponde a %q",
+		"error.candidate":                       "- %d: %s (%s)",
+		"error.invalid_format":                  "Parâmetro format inválido: %v",
+		"error.invalid_locale":                  "Parâmetro locale inválido: %v",
+		"error.render":                          "Falha ao gerar a resposta: %v",
+		"error.internal":                        "Erro interno: %v",
+		"error.timeout":                         "A requisição demorou demais e foi cancelada",
+		"error.search_lines":                    "Falha ao buscar linhas: %v",
+		"error.search_line_by_direction":        "Falha ao buscar a linha por sentido: %v",
+		"error.search_stops":                    "Falha ao buscar paradas: %v",
+		"error.get_stops_by_line":               "Falha ao obter as paradas da linha: %v",
+		"error.get_stops_by_corridor":           "Falha ao obter as paradas do corredor: %v",
+		"error.get_vehicle_positions":           "Falha ao obter as posições dos veículos: %v",
+		"error.get_vehicle_positions_by_line":   "Falha ao obter as posições dos veículos da linha: %v",
+		"error.get_accessible_fleet":            "Falha ao obter a frota acessível: %v",
+		"error.find_vehicles_near":              "Falha ao buscar veículos perto do ponto: %v",
+		"error.query_viewport":                  "Falha ao consultar a área do mapa: %v",
+		"error.render_map":                      "Falha ao desenhar o mapa: %v",
+		"error.get_vehicle_progress":            "Falha ao localizar os veículos ao longo do itinerário: %v",
+		"error.stop_not_on_line":                "A parada %d não é atendida pela linha %d",
+		"error.get_arrival_predictions":         "Falha ao obter as previsões de chegada: %v",
+		"error.get_arrival_predictions_by_line": "Falha ao obter as previsões de chegada da linha: %v",
+		"error.get_arrival_predictions_by_stop": "Falha ao obter as previsões de chegada da parada: %v",
+		"error.departure_board":                 "Falha ao obter o painel de partidas: %v",
+		"error.leave_advice":                    "Falha ao obter as previsões para indicar quando sair: %v",
+		"error.leave_origin":                    "informe exatamente um entre from e from_place",
+		"error.margin_range":                    "o parâmetro margin_minutes deve estar entre 0 e %d",
+		"error.stop_location":                   "A localização da parada %d não é conhecida",
+		"error.leave_too_far":                   "A parada está a %.1f km, longe demais para ir a pé",
+		"error.save_place":                      "save_place precisa de exatamente um entre location e place",
+		"error.unknown_place":                   "Nenhum lugar salvo se chama %q",
+		"error.places_limit":                    "No máximo %d lugares podem ser salvos",
+		"error.batch_size":                      "queries deve listar entre 1 e %d paradas",
+
+		// Rendered text
+		"render.lines_found":          "%d linhas encontradas para %q",
+		"render.line":                 "%s (código %d) %s → %s",
+		"render.circular":             " (circular)",
+		"render.resolved":             "Pegue %s (código %d, sentido %d) para %s",
+		"render.unresolved":           "Nenhum sentido único segue para %s",
+		"render.direction_match":      "%s (código %d, sentido %d) → %s: pontuação %.2f",
+		"render.direction_terminal":   ", corresponde ao terminal %s",
+		"render.direction_stop":       ", passa pela parada %s",
+		"render.stops_found":          "%d paradas encontradas para %q",
+		"render.stops_on_line":        "%d paradas na linha %d",
+		"render.stops_in_corridor":    "%d paradas no corredor %d",
+		"render.stop":                 "%s (código %d) — %s",
+		"render.stop_short":           "%s (código %d)",
+		"render.stops_near":           "%d paradas a até %d m de %.5f, %.5f",
+		"render.nearby_stop":          "%s (código %d) — %d m a pé, cerca de %d min",
+		"render.walk":                 "%s → %s: %d m a pé, cerca de %d min",
+		"render.walk_estimate":        "%d m em linha reta, fator de desvio %.2g a %.1f km/h",
+		"render.walk_point":           "%.5f, %.5f",
+		"render.transfers":            "%d paradas a até %d m de %s (código %d)",
+		"render.transfer_lines":       "Linhas nesta parada: %s",
+		"render.transfer_stop":        "  Linhas: %s (%d novas)",
+		"render.transfer_points":      "%d pontos de baldeação de %s para %s a até %d m",
+		"render.no_transfer_points":   "As linhas não passam a uma distância caminhável uma da outra",
+		"render.transfer_shared":      "%s (código %d), atendida pelas duas linhas",
+		"render.transfer_walk":        "%s (código %d) → %s (código %d), %d m a pé, cerca de %d min",
+		"render.transfer_connection":  "  %s sentido %s → %s sentido %s",
+		"render.transfer_wait":        ", próximo ônibus em %d min",
+		"render.trip":                 "Viagens de %s para %s saindo às %s",
+		"render.trip_none":            "Nenhuma viagem encontrada; as paradas perto da origem ou do destino podem ainda não estar no catálogo da rede",
+		"render.trip_option":          "Opção %d: chegada às %s (%d min), %d baldeações, %d m a pé",
+		"render.trip_walk":            "  %s caminhe %d m até %s",
+		"render.trip_ride":            "  %s pegue %s sentido %s em %s após %d min%s, percorra %d paradas até %s, chegada às %s",
+		"render.trip_live":            " (ao vivo)",
+		"render.isochrone":            "Alcançável a partir de %s saindo às %s",
+		"render.isochrone_live":       "%d linhas em operação, %d com velocidades medidas pelas posições em tempo real às %s",
+		"render.isochrone_budget":     "Em até %d min: %d paradas",
+		"render.isochrone_compact":    "%d min %d paradas",
+		"render.isochrone_none":       "Nenhuma parada pode ser alcançada dentro destes limites",
+		"render.reachable_stop":       "%s (código %d) em %d min, %d ônibus",
+		"render.reachable_compact":    "%d %d min",
+		"render.trip_estimated":       " (estimada)",
+		"render.trip_fare":            ", tarifa %s",
+		"render.fare":                 "Tarifa %s (%s), %d tarifas pagas",
+		"render.fare_leg":             "%d. %s: %s",
+		"render.fare_at":              " às %s",
+		"render.fare_bus":             "ônibus",
+		"render.fare_rail":            "metrô ou trem",
+		"render.fare_integrated":      ", integrado",
+		"render.fare_break_window":    ", integração perdida: mais de %d min desde o primeiro embarque",
+		"render.fare_break_boardings": ", integração perdida: limite de %d embarques atingido",
+		"render.fare_break_rail":      ", integração perdida: segunda entrada no metrô ou trem",
+		"render.money":                "R$ %.2f",
+		"render.empty_catalog":        "Nenhuma parada conhecida ainda; o catálogo da rede ainda está sendo montado, ou busque paradas ou linhas para que o servidor as indexe",
+		"render.vehicles_on_lines":    "%d veículos em %d linhas às %s",
+		"render.line_counts":          "%s → %s: %d veículos (%d acessíveis)",
+		"render.more_results":         "Há mais resultados com o cursor %s",
+		"render.vehicles_on_line":     "%d veículos na linha %d às %s",
+		"render.line_vehicles":        "%s → %s (%d veículos)",
+		"render.vehicle":              "Veículo %d em %.5f, %.5f",
+		"render.vehicle_compact":      "%s %d %.5f,%.5f%s",
+		"render.vehicles_near":        "%d veículos a até %d m de %.5f, %.5f às %s",
+		"render.nearby_vehicle":       "%s → %s: veículo %d, %d m a %s, atualizado há %d s",
+		"render.viewport":             "%d paradas e %d veículos na área do mapa às %s",
+		"render.clusters_zoom":        "Grupos no zoom %d",
+		"render.stop_cluster":         "%d paradas perto de %.5f, %.5f",
+		"render.vehicle_cluster":      "%d veículos perto de %.5f, %.5f",
+		"render.viewport_truncated":   "Alguns pontos ficaram de fora; aproxime o mapa ou ative o agrupamento para ver todos",
+		"render.map_line":             "Linha %d",
+		"render.map_stop":             "Parada",
+		"render.map_highlight":        "Parada selecionada",
+		"render.map_vehicle":          "Ônibus",
+		"render.map_accessible":       "Ônibus acessível",
+		"render.map_summary":          "Mapa de %s: %d paradas, %d veículos (%d acessíveis), barra de escala de %d m",
+		"render.vehicle_progress":     "%d veículos da linha %d ao longo de %d paradas (%.1f km, itinerário %s) às %s",
+		"render.vehicle_progress_to":  "%d veículos da linha %d em direção à parada %s (nº %d de %d) às %s",
+		"render.progress_between":     "veículo %d entre %s e %s, %.1f km percorridos",
+		"render.progress_remaining":   ", a %d paradas / %d m",
+		"render.progress_passed":      ", já passou",
+		"render.progress_off_route":   ", fora do itinerário (%d m)",
+		"render.route_start":          "o início",
+		"render.route_end":            "o fim",
+		"render.geocoded_place":       "Localizado %s",
+		"render.geocode":              "%d locais para %q",
+		"render.geocode_result":       "%s em %.5f, %.5f (%s, pontuação %.2f)",
+		"render.geocode_stop":         "  parada mais próxima: %s (código %d)",
+		"render.street_number":        "%s, %d",
+		"render.reverse_geocode":      "%s (%s)",
+		"render.nearest_stop":         "parada mais próxima: %s (código %d), %d m",
+		"render.cross_streets":        "próximas: %s",
+		"render.no_street":            "Nenhuma rua conhecida perto de %.5f, %.5f",
+		"render.at_number":            "parada neste número",
+		"render.interpolated":         "interpolado entre paradas",
+		"render.nearest_number":       "número conhecido mais próximo",
+		"render.street_only":          "apenas a rua",
+		"render.by_stop_name":         "nome da parada",
+		"render.coordinate":           "coordenada",
+		"render.geofences":            "%d cercas virtuais, avaliadas a cada %d s",
+		"render.geofence":             "%s %s: %s, %d veículos dentro",
+		"render.geofence_circle":      "%d m ao redor de %.5f, %.5f",
+		"render.geofence_polygon":     "polígono de %d pontos",
+		"render.geofence_vehicle":     "  veículo %d",
+		"render.geofence_lines":       "  linhas %s*",
+		"render.geofence_line":        "  linha %d",
+		"render.geofence_webhook":     "  webhook %s",
+		"render.geofence_error":       "A última avaliação falhou: %s",
+		"render.geofence_deleted":     "Cerca virtual %s removida",
+		"render.geofence_events":      "%d eventos de cercas virtuais",
+		"render.geofence_enter":       "%s veículo %d entrou em %s às %s",
+		"render.geofence_exit":        "%s veículo %d saiu de %s às %s",
+		"render.accessible":           " (acessível)",
+		"render.predictions_for_line": "%d previsões para a linha %d em %d paradas às %s",
+		"render.no_predictions":       "Nenhuma previsão para a parada %d às %s",
+		"render.stop_heading":         "Parada %s (código %d) às %s",
+		"render.arrives":              "chega às %s",
+		"render.in_minutes":           " (em %d min%s)",
+		"render.accessible_suffix":    ", acessível",
+		"render.departure_board":      "Próximas partidas de %s (código %d) às %s",
+		"render.departure":            "%s → %s em %d min (%s)%s",
+		"render.departure_distance":   ", veículo a %.1f km",
+		"render.departure_compact":    "%s %d min",
+		"render.next_accessible":      "Próximo acessível %s → %s em %d min (%s)",
+		"render.no_accessible":        "Nenhum ônibus acessível previsto para %s → %s",
+		"render.batch":                "%d de %d consultas respondidas, %d paradas consultadas",
+		"render.batch_query":          "#%d %s",
+		"render.batch_failed":         "#%d %s: %s",
+		"render.candidate":            "%d: %s (%s)",
+		"render.trip_accessible":      "Aguardando apenas ônibus acessíveis para cadeirantes",
+		"render.trip_no_accessible":   "%d opções foram omitidas porque nenhum ônibus acessível está previsto para um de seus trechos",
+		"render.fleet":                "%d de %d veículos em operação são acessíveis (%.1f%%) em %d linhas às %s",
+		"render.fleet_line":           "%s: %d de %d acessíveis (%.1f%%)",
+		"render.fleet_compact":        "%s %.1f%%",
+		"render.fleet_none":           "Nenhum veículo em operação encontrado às %s",
+		"render.stop_compact":         "Parada %s — %s %s",
+		"render.stop_arrival":         "%s → %s %s",
+		"render.catalog_status":       "Catálogo versão %d: %d linhas, %d paradas, %d corredores",
+		"render.catalog_updated":      "Última atualização às %s",
+		"render.crawler_disabled":     "Rastreador desativado",
+		"render.crawler_running":      "Rastreando: %d linhas obtidas, %d pendentes, %d falhas",
+		"render.crawler_next":         "Próximo rastreamento às %s",
+		"render.crawler_error":        "Último erro: %s",
+		"render.preferences":          "Preferências da sessão: idioma %s",
+		"render.preferences_access":   "Apenas veículos acessíveis para cadeirantes são exibidos",
+		"render.preferences_place":    "Lugar salvo %s: %s",
+		"render.leave":                "Caminhada de %d m (%d min) de %s até %s (código %d) às %s, chegando %d min antes",
+		"render.leave_by":             "Saia até %s (em %d min) para pegar %s → %s às %s, confiança %s",
+		"render.leave_none":           "Nenhum ônibus que ainda dê para pegar está previsto",
+		"render.leave_option":         "%s → %s às %s: saia até %s (em %d min), posição de %ds atrás",
+		"render.leave_missed":         "%d ônibus chegam cedo demais para pegar",
+		"render.leave_compact":        "%s saia %s (%s)",
+		"render.confidence_high":      "alta",
+		"render.confidence_medium":    "média",
+		"render.confidence_low":       "baixa",
+		"render.here":                 "sua posição",
+		"render.tool_metrics":         "%s: %d chamadas, %d erros, média de %d ms, máximo de %d ms",
+	},
+}
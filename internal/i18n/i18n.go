@@ -0,0 +1,49 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale identifies a message catalog
+type Locale string
+
+const (
+	English    Locale = "en"
+	Portuguese Locale = "pt-BR"
+)
+
+// Locales lists the supported locales
+var Locales = []Locale{English, Portuguese}
+
+// ParseLocale parses a locale name such as "pt-BR", "pt_br" or "en", returning
+// fallback for an empty name
+func ParseLocale(name string, fallback Locale) (Locale, error) {
+	if name == "" {
+		return fallback, nil
+	}
+	normalized := strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	switch {
+	case normalized == "en" || strings.HasPrefix(normalized, "en-"):
+		return English, nil
+	case normalized == "pt" || strings.HasPrefix(normalized, "pt-"):
+		return Portuguese, nil
+	}
+	return "", fmt.Errorf("unknown locale %q, expected one of en, pt-BR", name)
+}
+
+// T returns the message for key in the given locale, formatted with args.
+// Messages missing from the locale fall back to English, then to the key itself.
+func T(locale Locale, key string, args ...any) string {
+	message, ok := catalog[locale][key]
+	if !ok {
+		message, ok = catalog[English][key]
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
@@ -0,0 +1,147 @@
+package journey
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	minHeadwayWait = 1 * time.Minute  // Shortest wait expected for a line, however many buses it runs
+	maxHeadwayWait = 20 * time.Minute // Longest wait expected for a running line
+)
+
+// Conditions describe how the lines are running when a reach search starts
+type Conditions struct {
+	// Speed returns the running speed of a line in meters per second, or
+	// RideSpeed is assumed when nil
+	Speed func(line types.Line) float64
+	// Vehicles returns the number of vehicles running a line, giving an
+	// expected wait of half the time between them; lines without vehicles are
+	// not ridden, and EstimatedWait is assumed when nil
+	Vehicles func(line types.Line) int
+}
+
+// Reached is a stop reached within a time budget
+type Reached struct {
+	Stop    types.Stop
+	Arrival time.Duration // Time from the origin, walks, waits and rides included
+	Rides   int           // Buses ridden to reach the stop
+}
+
+// Reach finds the stops that can be reached from a point within the budget,
+// walking to the first stop, waiting and riding up to opts.MaxTransfers+1
+// buses under the given conditions. Stops are sorted by arrival.
+func (n *Network) Reach(from geo.Point, budget time.Duration, opts Options, live Conditions) []Reached {
+	// Time each pattern under the conditions, skipping those not running
+	scale := make([]float64, len(n.patterns))
+	waits := make([]time.Duration, len(n.patterns))
+	running := make([]bool, len(n.patterns))
+	for p, pattern := range n.patterns {
+		scale[p], waits[p], running[p] = 1, EstimatedWait, !opts.Exclude[pattern.Line.Code]
+		if live.Speed != nil {
+			if speed := live.Speed(pattern.Line); speed > 0 {
+				scale[p] = RideSpeed / speed
+			}
+		}
+		if live.Vehicles != nil && running[p] {
+			vehicles := live.Vehicles(pattern.Line)
+			running[p] = vehicles > 0
+			if running[p] {
+				run := time.Duration(float64(n.elapsed[p][len(pattern.Stops)-1]) * scale[p])
+				waits[p] = min(max(run/time.Duration(2*vehicles), minHeadwayWait), maxHeadwayWait)
+			}
+		}
+	}
+
+	best := make(map[int]Reached)
+	improve := func(round map[int]time.Duration, stop int, arrival time.Duration, rides int) {
+		if arrival > budget {
+			return
+		}
+		if b, ok := best[stop]; ok && b.Arrival <= arrival {
+			return
+		}
+		best[stop] = Reached{Stop: n.stops[stop], Arrival: arrival, Rides: rides}
+		round[stop] = arrival
+	}
+
+	marked := make(map[int]time.Duration)
+	for _, s := range n.near(from, opts.MaxWalk) {
+		improve(marked, s.Value.Code, seconds(opts.Walker.Time(s.Distance)), 0)
+	}
+	// Earliest arrival at each stop over the rounds before the current one
+	settled := make(map[int]time.Duration, len(marked))
+	for stop, at := range marked {
+		settled[stop] = at
+	}
+
+	for k := 1; k <= opts.MaxTransfers+1 && len(marked) > 0; k++ {
+		current := make(map[int]time.Duration)
+
+		// Scan each running pattern from the earliest stop reached in the last round
+		queue := make(map[int]int)
+		for stop := range marked {
+			for _, v := range n.visits[stop] {
+				if !running[v.pattern] {
+					continue
+				}
+				if p, ok := queue[v.pattern]; !ok || v.position < p {
+					queue[v.pattern] = v.position
+				}
+			}
+		}
+		rides := make(map[int]time.Duration)
+		for _, p := range sortedKeys(queue) {
+			stops, elapsed := n.patterns[p].Stops, n.elapsed[p]
+			board := -1
+			var departure time.Duration
+			ride := func(i int) time.Duration {
+				return time.Duration(float64(elapsed[i]-elapsed[board]) * scale[p])
+			}
+			for i := queue[p]; i < len(stops); i++ {
+				stop := stops[i]
+				if board >= 0 {
+					arrival := departure + ride(i)
+					if before, ok := best[stop]; arrival <= budget && (!ok || arrival < before.Arrival) {
+						improve(current, stop, arrival, k)
+						rides[stop] = arrival
+					}
+				}
+				if at, ok := settled[stop]; ok {
+					if dep := at + waits[p]; board < 0 || dep < departure+ride(i) {
+						board, departure = i, dep
+					}
+				}
+			}
+		}
+
+		// Walk to the stops around those reached by bus
+		for _, stop := range sortedKeys(rides) {
+			origin := n.stops[stop]
+			for _, s := range n.near(geo.Point{Latitude: origin.Latitude, Longitude: origin.Longitude}, opts.TransferWalk) {
+				if s.Value.Code != stop {
+					improve(current, s.Value.Code, rides[stop]+seconds(opts.Walker.Time(s.Distance)), k)
+				}
+			}
+		}
+		for stop, at := range current {
+			settled[stop] = at
+		}
+		marked = current
+	}
+
+	reached := make([]Reached, 0, len(best))
+	for _, r := range best {
+		reached = append(reached, r)
+	}
+	sort.Slice(reached, func(i, j int) bool {
+		if reached[i].Arrival != reached[j].Arrival {
+			return reached[i].Arrival < reached[j].Arrival
+		}
+		return reached[i].Stop.Code < reached[j].Stop.Code
+	})
+	return reached
+}
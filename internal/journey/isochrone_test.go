@@ -0,0 +1,73 @@
+package journey
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+func TestReach(t *testing.T) {
+	n := NewNetwork(testStops, []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}, {Line: north, Stops: []int{4, 5, 6}}})
+	running := func(codes ...int) func(types.Line) int {
+		return func(line types.Line) int {
+			for _, code := range codes {
+				if line.Code == code {
+					return 4
+				}
+			}
+			return 0
+		}
+	}
+
+	tests := []struct {
+		name   string
+		budget time.Duration
+		live   Conditions
+		rides  map[int]int // Rides to each stop reached
+	}{
+		{"walk only", 5 * time.Minute, Conditions{}, map[int]int{1: 0}},
+		{"one line", 18 * time.Minute, Conditions{}, map[int]int{1: 0, 2: 1, 3: 1}},
+		{"whole network", 45 * time.Minute, Conditions{}, map[int]int{1: 0, 2: 1, 3: 1, 4: 1, 5: 2, 6: 2}},
+		{"second line not running", 45 * time.Minute, Conditions{Vehicles: running(100)}, map[int]int{1: 0, 2: 1, 3: 1, 4: 1}},
+		{"nothing running", 45 * time.Minute, Conditions{Vehicles: running()}, map[int]int{1: 0}},
+	}
+	for _, tt := range tests {
+		reached := n.Reach(point(1), tt.budget, testOptions(2), tt.live)
+		if len(reached) != len(tt.rides) {
+			t.Errorf("%s: reached %d stops, want %d: %+v", tt.name, len(reached), len(tt.rides), reached)
+			continue
+		}
+		for i, r := range reached {
+			if rides, ok := tt.rides[r.Stop.Code]; !ok || rides != r.Rides {
+				t.Errorf("%s: reached stop %d with %d rides, want %v", tt.name, r.Stop.Code, r.Rides, tt.rides)
+			}
+			if r.Arrival > tt.budget || (i > 0 && r.Arrival < reached[i-1].Arrival) {
+				t.Errorf("%s: arrivals out of order or over budget", tt.name)
+			}
+		}
+	}
+}
+
+func TestReachFasterLines(t *testing.T) {
+	n := NewNetwork(testStops, []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}})
+	arrival := func(live Conditions) time.Duration {
+		for _, r := range n.Reach(point(1), time.Hour, testOptions(0), live) {
+			if r.Stop.Code == 4 {
+				return r.Arrival
+			}
+		}
+		t.Fatal("stop 4 not reached")
+		return 0
+	}
+
+	base := arrival(Conditions{})
+	faster := arrival(Conditions{Speed: func(types.Line) float64 { return 2 * RideSpeed }})
+	frequent := arrival(Conditions{Vehicles: func(types.Line) int { return 100 }})
+	if faster >= base {
+		t.Errorf("doubling the speed arrives at %v, not before %v", faster, base)
+	}
+	if want := base - EstimatedWait + minHeadwayWait; frequent != want {
+		t.Errorf("frequent line arrives at %v, want %v", frequent, want)
+	}
+}
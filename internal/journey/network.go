@@ -0,0 +1,103 @@
+package journey
+
+import (
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// RideSpeed is the average speed of a bus between stops in meters per
+// second, dwell times and traffic included
+const RideSpeed = 15.0 / 3.6
+
+// rideDetour is the ratio of the street distance to the straight-line
+// distance between consecutive stops
+const rideDetour = 1.2
+
+// Pattern is a line with its ordered stops
+type Pattern struct {
+	Line  types.Line
+	Stops []int // Ordered stop codes
+}
+
+// visit is the position of a stop in a pattern
+type visit struct {
+	pattern  int
+	position int
+}
+
+// Network is the graph of lines and stops a journey is planned on
+type Network struct {
+	stops    map[int]types.Stop
+	patterns []Pattern
+	elapsed  [][]time.Duration // Ride time from the first stop of each pattern to each of its stops
+	visits   map[int][]visit   // Patterns passing through each stop
+	index    *geo.Grid[types.Stop]
+}
+
+// NewNetwork creates the network of the patterns, dropping the stops whose
+// coordinates are unknown
+func NewNetwork(stops []types.Stop, patterns []Pattern) *Network {
+	n := &Network{
+		stops:  make(map[int]types.Stop, len(stops)),
+		visits: make(map[int][]visit),
+		index:  geo.NewGrid[types.Stop](geo.DefaultCellSize),
+	}
+	for _, stop := range stops {
+		n.stops[stop.Code] = stop
+	}
+
+	for _, p := range patterns {
+		codes := make([]int, 0, len(p.Stops))
+		for _, code := range p.Stops {
+			if _, ok := n.stops[code]; ok {
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) < 2 {
+			continue
+		}
+
+		elapsed := make([]time.Duration, len(codes))
+		for i := 1; i < len(codes); i++ {
+			a, b := n.stops[codes[i-1]], n.stops[codes[i]]
+			meters := geo.Distance(a.Latitude, a.Longitude, b.Latitude, b.Longitude) * rideDetour
+			elapsed[i] = elapsed[i-1] + seconds(meters/RideSpeed)
+		}
+
+		index := len(n.patterns)
+		n.patterns = append(n.patterns, Pattern{Line: p.Line, Stops: codes})
+		n.elapsed = append(n.elapsed, elapsed)
+		for i, code := range codes {
+			n.visits[code] = append(n.visits[code], visit{pattern: index, position: i})
+		}
+	}
+
+	for code := range n.visits {
+		stop := n.stops[code]
+		n.index.Insert(stop.Latitude, stop.Longitude, stop)
+	}
+	return n
+}
+
+// Lines returns the number of patterns in the network
+func (n *Network) Lines() int {
+	return len(n.patterns)
+}
+
+// Stop returns a stop of the network
+func (n *Network) Stop(code int) (types.Stop, bool) {
+	stop, ok := n.stops[code]
+	return stop, ok
+}
+
+// near returns the served stops within radius meters of the point
+func (n *Network) near(p geo.Point, radius float64) []geo.Neighbor[types.Stop] {
+	return n.index.Within(p.Latitude, p.Longitude, radius, 0)
+}
+
+// seconds converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,400 @@
+package journey
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Kinds of legs
+const (
+	Walk = "walk"
+	Ride = "ride"
+)
+
+// EstimatedWait is the wait assumed for a bus when no live prediction covers it
+const EstimatedWait = 8 * time.Minute
+
+// Departure is the bus a ride leg boards
+type Departure struct {
+	Wait       time.Duration // Wait at the stop before boarding
+	VehicleID  string        // Predicted vehicle, when live
+	Accessible bool          // Whether the predicted vehicle is accessible
+	Live       bool          // Whether the wait comes from a live prediction
+}
+
+// WaitFunc returns the first bus of a line leaving a stop at or after the
+// given time since the start of the journey, if one is known
+type WaitFunc func(line types.Line, stopCode int, at time.Duration) (Departure, bool)
+
+// Options tune a journey search
+type Options struct {
+	MaxTransfers int          // Maximum number of changes between buses
+	MaxWalk      float64      // Maximum straight-line meters to the first stop and from the last stop
+	TransferWalk float64      // Maximum straight-line meters walked between stops when changing
+	Walker       geo.Walker   // Walking estimates
+	Exclude      map[int]bool // Line codes not to ride
+}
+
+// Leg is a walk or a bus ride of an itinerary
+type Leg struct {
+	Kind      string
+	Line      types.Line    // Line ridden, for ride legs
+	From      int           // Stop code the leg starts at, zero for the origin
+	To        int           // Stop code the leg ends at, zero for the destination
+	Stops     int           // Stops ridden, for ride legs
+	Meters    float64       // Estimated distance covered
+	Start     time.Duration // Time since the start of the journey the leg starts at
+	Duration  time.Duration // Walking or riding time, excluding the wait
+	Departure Departure     // Bus boarded, for ride legs
+}
+
+// End returns the time since the start of the journey the leg ends at
+func (l Leg) End() time.Duration {
+	return l.Start + l.Departure.Wait + l.Duration
+}
+
+// Itinerary is a sequence of legs from the origin to the destination
+type Itinerary struct {
+	Legs []Leg
+}
+
+// Arrival returns the time since the start of the journey it arrives at
+func (it Itinerary) Arrival() time.Duration {
+	if len(it.Legs) == 0 {
+		return 0
+	}
+	return it.Legs[len(it.Legs)-1].End()
+}
+
+// Rides returns the number of ride legs
+func (it Itinerary) Rides() int {
+	rides := 0
+	for _, leg := range it.Legs {
+		if leg.Kind == Ride {
+			rides++
+		}
+	}
+	return rides
+}
+
+// Transfers returns the number of changes between buses
+func (it Itinerary) Transfers() int {
+	return max(it.Rides()-1, 0)
+}
+
+// WalkMeters returns the estimated distance walked
+func (it Itinerary) WalkMeters() float64 {
+	meters := 0.0
+	for _, leg := range it.Legs {
+		if leg.Kind == Walk {
+			meters += leg.Meters
+		}
+	}
+	return meters
+}
+
+// key identifies the rides of an itinerary
+func (it Itinerary) key() string {
+	var b strings.Builder
+	for _, leg := range it.Legs {
+		if leg.Kind == Ride {
+			fmt.Fprintf(&b, "%d:%d-%d;", leg.Line.Code, leg.From, leg.To)
+		}
+	}
+	return b.String()
+}
+
+// Live reports whether every ride boards a bus known from a live prediction
+func (it Itinerary) Live() bool {
+	for _, leg := range it.Legs {
+		if leg.Kind == Ride && !leg.Departure.Live {
+			return false
+		}
+	}
+	return true
+}
+
+// Retime recomputes the start of every leg, boarding the buses returned by
+// wait and assuming EstimatedWait where it knows none; such rides are not Live
+func (it Itinerary) Retime(wait WaitFunc) Itinerary {
+	legs := make([]Leg, len(it.Legs))
+	at := time.Duration(0)
+	for i, leg := range it.Legs {
+		leg.Start = at
+		if leg.Kind == Ride {
+			leg.Departure = Departure{Wait: EstimatedWait}
+			if wait != nil {
+				if departure, ok := wait(leg.Line, leg.From, at); ok {
+					leg.Departure = departure
+				}
+			}
+		}
+		legs[i] = leg
+		at = leg.End()
+	}
+	return Itinerary{Legs: legs}
+}
+
+// label is how a stop was reached in a round of the search
+type label struct {
+	arrival time.Duration
+	kind    string // Walk for the origin and transfers, Ride for buses
+	from    int    // Stop walked from, zero for the origin
+	meters  float64
+	walk    time.Duration
+	ride    ride // Ride that reached the stop, or the stop walked from
+}
+
+// ride is a bus ride between two positions of a pattern
+type ride struct {
+	pattern int
+	board   int
+	alight  int
+	round   int // Round the boarding stop was reached in
+}
+
+// Plan finds up to count itineraries from one point to another, the fastest
+// for each number of transfers and then alternatives avoiding the lines
+// already found. Itineraries are timed with EstimatedWait and sorted by arrival.
+func (n *Network) Plan(from, to geo.Point, opts Options, count int) []Itinerary {
+	var found []Itinerary
+	seen := make(map[string]bool)
+	add := func(it Itinerary) bool {
+		if seen[it.key()] {
+			return false
+		}
+		seen[it.key()] = true
+		found = append(found, it)
+		return true
+	}
+
+	if direct := geo.Distance(from.Latitude, from.Longitude, to.Latitude, to.Longitude); direct <= 2*opts.MaxWalk {
+		add(Itinerary{Legs: []Leg{{
+			Kind:     Walk,
+			Meters:   opts.Walker.Distance(direct),
+			Duration: seconds(opts.Walker.Time(direct)),
+		}}})
+	}
+
+	exclude := make(map[int]bool, len(opts.Exclude))
+	for code := range opts.Exclude {
+		exclude[code] = true
+	}
+	for pass := 0; pass < count && len(found) < 2*count; pass++ {
+		opts.Exclude = exclude
+		var next *Itinerary
+		for _, it := range n.search(from, to, opts) {
+			if add(it) && next == nil {
+				next = &it
+			}
+		}
+		if next == nil {
+			break
+		}
+		// Look for alternatives without the first bus of the fastest new itinerary
+		for _, leg := range next.Legs {
+			if leg.Kind == Ride {
+				exclude[leg.Line.Code] = true
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Arrival() < found[j].Arrival()
+	})
+	if len(found) > count {
+		found = found[:count]
+	}
+	return found
+}
+
+// search runs a RAPTOR search over the rounds of rides, assuming
+// EstimatedWait at every boarding, and returns the fastest itinerary for each
+// number of rides that arrives earlier than those with fewer rides
+func (n *Network) search(from, to geo.Point, opts Options) []Itinerary {
+	rounds := []map[int]label{make(map[int]label)}
+	best := make(map[int]time.Duration)
+	improve := func(round map[int]label, stop int, l label) bool {
+		if b, ok := best[stop]; ok && b <= l.arrival {
+			return false
+		}
+		best[stop] = l.arrival
+		round[stop] = l
+		return true
+	}
+
+	for _, s := range n.near(from, opts.MaxWalk) {
+		improve(rounds[0], s.Value.Code, label{
+			arrival: seconds(opts.Walker.Time(s.Distance)),
+			kind:    Walk,
+			meters:  opts.Walker.Distance(s.Distance),
+			walk:    seconds(opts.Walker.Time(s.Distance)),
+		})
+	}
+	egress := make(map[int]float64)
+	for _, s := range n.near(to, opts.MaxWalk) {
+		egress[s.Value.Code] = s.Distance
+	}
+
+	target := time.Duration(math.MaxInt64)
+	var itineraries []Itinerary
+	marked := rounds[0]
+	for k := 1; k <= opts.MaxTransfers+1 && len(marked) > 0; k++ {
+		current := make(map[int]label)
+		rounds = append(rounds, current)
+
+		// Scan each pattern from the earliest stop reached in the last round
+		queue := make(map[int]int)
+		for stop := range marked {
+			for _, v := range n.visits[stop] {
+				if opts.Exclude[n.patterns[v.pattern].Line.Code] {
+					continue
+				}
+				if p, ok := queue[v.pattern]; !ok || v.position < p {
+					queue[v.pattern] = v.position
+				}
+			}
+		}
+		rides := make(map[int]label)
+		for _, p := range sortedKeys(queue) {
+			stops, elapsed := n.patterns[p].Stops, n.elapsed[p]
+			board, boardRound := -1, 0
+			var departure time.Duration
+			for i := queue[p]; i < len(stops); i++ {
+				stop := stops[i]
+				if board >= 0 {
+					arrival := departure + elapsed[i] - elapsed[board]
+					l := label{arrival: arrival, kind: Ride, ride: ride{pattern: p, board: board, alight: i, round: boardRound}}
+					if arrival < target && improve(current, stop, l) {
+						rides[stop] = l
+					}
+				}
+				// Board at the earliest arrival at the stop in any earlier
+				// round, not only the last one
+				if prev, j, ok := reached(rounds[:k], stop); ok {
+					if dep := prev.arrival + EstimatedWait; board < 0 || dep < departure+elapsed[i]-elapsed[board] {
+						board, boardRound, departure = i, j, dep
+					}
+				}
+			}
+		}
+
+		// Walk to the stops around those reached by bus
+		for _, stop := range sortedKeys(rides) {
+			l := rides[stop]
+			origin := n.stops[stop]
+			for _, s := range n.near(geo.Point{Latitude: origin.Latitude, Longitude: origin.Longitude}, opts.TransferWalk) {
+				if s.Value.Code == stop {
+					continue
+				}
+				walk := seconds(opts.Walker.Time(s.Distance))
+				improve(current, s.Value.Code, label{
+					arrival: l.arrival + walk,
+					kind:    Walk,
+					from:    stop,
+					meters:  opts.Walker.Distance(s.Distance),
+					walk:    walk,
+					ride:    l.ride,
+				})
+			}
+		}
+
+		// Walk from the stops reached this round to the destination
+		bestStop, bestArrival := 0, target
+		for _, stop := range sortedKeys(current) {
+			distance, ok := egress[stop]
+			if !ok {
+				continue
+			}
+			if arrival := current[stop].arrival + seconds(opts.Walker.Time(distance)); arrival < bestArrival {
+				bestStop, bestArrival = stop, arrival
+			}
+		}
+		if bestStop != 0 {
+			target = bestArrival
+			itineraries = append(itineraries, n.itinerary(rounds, k, bestStop, egress[bestStop], opts.Walker))
+		}
+		marked = current
+	}
+	return itineraries
+}
+
+// itinerary rebuilds the legs leading to a stop reached in round k, followed
+// by the walk to the destination
+func (n *Network) itinerary(rounds []map[int]label, k, stop int, distance float64, walker geo.Walker) Itinerary {
+	legs := []Leg{{
+		Kind:     Walk,
+		From:     stop,
+		Meters:   walker.Distance(distance),
+		Duration: seconds(walker.Time(distance)),
+	}}
+	for {
+		l := rounds[k][stop]
+		if l.kind == Walk {
+			legs = append(legs, Leg{Kind: Walk, From: l.from, To: stop, Meters: l.meters, Duration: l.walk})
+			if k == 0 {
+				break
+			}
+			stop = l.from
+		}
+		p := n.patterns[l.ride.pattern]
+		board := p.Stops[l.ride.board]
+		elapsed := n.elapsed[l.ride.pattern][l.ride.alight] - n.elapsed[l.ride.pattern][l.ride.board]
+		legs = append(legs, Leg{
+			Kind:     Ride,
+			Line:     p.Line,
+			From:     board,
+			To:       stop,
+			Stops:    l.ride.alight - l.ride.board,
+			Meters:   elapsed.Seconds() * RideSpeed,
+			Duration: elapsed,
+		})
+		stop = board
+		k = l.ride.round
+	}
+
+	// Reverse the legs and merge consecutive walks
+	var ordered []Leg
+	for i := len(legs) - 1; i >= 0; i-- {
+		leg := legs[i]
+		if last := len(ordered) - 1; leg.Kind == Walk && last >= 0 && ordered[last].Kind == Walk {
+			ordered[last].To = leg.To
+			ordered[last].Meters += leg.Meters
+			ordered[last].Duration += leg.Duration
+			continue
+		}
+		if leg.Kind == Walk && leg.Meters < 1 {
+			continue
+		}
+		ordered = append(ordered, leg)
+	}
+	return Itinerary{Legs: ordered}.Retime(nil)
+}
+
+// reached returns the label of a stop in the latest of the rounds that
+// reached it, which has the earliest arrival as labels only ever improve
+func reached(rounds []map[int]label, stop int) (label, int, bool) {
+	for k := len(rounds) - 1; k >= 0; k-- {
+		if l, ok := rounds[k][stop]; ok {
+			return l, k, true
+		}
+	}
+	return label{}, 0, false
+}
+
+// sortedKeys returns the keys of a map in increasing order
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,232 @@
+package journey
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// testStops lie about 1 km apart: 1 to 4 eastwards, then 5 and 6 north of 4,
+// 7 far from all of them and 8 a short walk from 4
+var testStops = []types.Stop{
+	{Code: 1, Latitude: -23.550, Longitude: -46.700},
+	{Code: 2, Latitude: -23.550, Longitude: -46.690},
+	{Code: 3, Latitude: -23.550, Longitude: -46.680},
+	{Code: 4, Latitude: -23.550, Longitude: -46.670},
+	{Code: 5, Latitude: -23.541, Longitude: -46.670},
+	{Code: 6, Latitude: -23.532, Longitude: -46.670},
+	{Code: 7, Latitude: -23.400, Longitude: -46.500},
+	{Code: 8, Latitude: -23.5485, Longitude: -46.6700},
+}
+
+var (
+	east  = types.Line{Code: 100, Number: "1000", Type: 10, Direction: 1}
+	north = types.Line{Code: 200, Number: "2000", Type: 10, Direction: 1}
+	fast  = types.Line{Code: 300, Number: "3000", Type: 10, Direction: 1}
+)
+
+func testOptions(transfers int) Options {
+	return Options{MaxTransfers: transfers, MaxWalk: 300, TransferWalk: 300, Walker: geo.DefaultWalker}
+}
+
+func point(code int) geo.Point {
+	for _, s := range testStops {
+		if s.Code == code {
+			return geo.Point{Latitude: s.Latitude, Longitude: s.Longitude}
+		}
+	}
+	panic("unknown stop")
+}
+
+// rides describes the ride legs of an itinerary as line:from-to
+func rides(it Itinerary) []string {
+	var legs []string
+	for _, leg := range it.Legs {
+		if leg.Kind == Ride {
+			legs = append(legs, fmt.Sprintf("%s:%d-%d", leg.Line.Number, leg.From, leg.To))
+		}
+	}
+	return legs
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		patterns  []Pattern
+		from, to  int
+		transfers int
+		want      [][]string // Rides of each itinerary, fastest first
+	}{
+		{
+			name:      "one line",
+			patterns:  []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}},
+			from:      1,
+			to:        3,
+			transfers: 2,
+			want:      [][]string{{"1000:1-3"}},
+		},
+		{
+			name:      "transfer",
+			patterns:  []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}, {Line: north, Stops: []int{4, 5, 6}}},
+			from:      1,
+			to:        6,
+			transfers: 2,
+			want:      [][]string{{"1000:1-4", "2000:4-6"}},
+		},
+		{
+			name:      "walking transfer",
+			patterns:  []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}, {Line: north, Stops: []int{8, 5, 6}}},
+			from:      1,
+			to:        6,
+			transfers: 2,
+			want:      [][]string{{"1000:1-4", "2000:8-6"}},
+		},
+		{
+			name:      "transfer not allowed",
+			patterns:  []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}, {Line: north, Stops: []int{4, 5, 6}}},
+			from:      1,
+			to:        6,
+			transfers: 0,
+			want:      nil,
+		},
+		{
+			name: "direct line beats a transfer",
+			patterns: []Pattern{
+				{Line: east, Stops: []int{1, 2, 3, 4}},
+				{Line: north, Stops: []int{4, 5, 6}},
+				{Line: fast, Stops: []int{1, 5, 6}},
+			},
+			from:      1,
+			to:        6,
+			transfers: 2,
+			want:      [][]string{{"3000:1-6"}, {"1000:1-4", "2000:4-6"}},
+		},
+		{
+			name:      "wrong way",
+			patterns:  []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}},
+			from:      4,
+			to:        1,
+			transfers: 2,
+			want:      nil,
+		},
+		{
+			name:      "unreachable",
+			patterns:  []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}},
+			from:      1,
+			to:        7,
+			transfers: 2,
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		n := NewNetwork(testStops, tt.patterns)
+		found := n.Plan(point(tt.from), point(tt.to), testOptions(tt.transfers), 3)
+		if len(found) != len(tt.want) {
+			t.Errorf("%s: found %d itineraries, want %d", tt.name, len(found), len(tt.want))
+			continue
+		}
+		for i, it := range found {
+			if got := rides(it); !slices.Equal(got, tt.want[i]) {
+				t.Errorf("%s: itinerary %d rides %v, want %v", tt.name, i, got, tt.want[i])
+			}
+			if it.Transfers() != len(tt.want[i])-1 {
+				t.Errorf("%s: itinerary %d has %d transfers", tt.name, i, it.Transfers())
+			}
+			checkTimes(t, tt.name, it)
+			if i > 0 && it.Arrival() < found[i-1].Arrival() {
+				t.Errorf("%s: itineraries not sorted by arrival", tt.name)
+			}
+		}
+	}
+}
+
+func TestPlanStopsAtOrigin(t *testing.T) {
+	n := NewNetwork(testStops, []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}})
+	found := n.Plan(point(1), point(1), testOptions(2), 3)
+	if len(found) != 1 || found[0].Rides() != 0 {
+		t.Fatalf("planning to the origin found %+v, want a single walk", found)
+	}
+}
+
+// checkTimes checks that the legs of an itinerary follow each other, each
+// ride waiting EstimatedWait as planned
+func checkTimes(t *testing.T, name string, it Itinerary) {
+	t.Helper()
+	at := time.Duration(0)
+	for i, leg := range it.Legs {
+		if leg.Start != at {
+			t.Errorf("%s: leg %d starts at %v, want %v", name, i, leg.Start, at)
+		}
+		if leg.Kind == Ride && (leg.Departure.Wait != EstimatedWait || leg.Departure.Live) {
+			t.Errorf("%s: leg %d waits %v, want the estimated %v", name, i, leg.Departure.Wait, EstimatedWait)
+		}
+		at = leg.End()
+	}
+	if it.Arrival() != at {
+		t.Errorf("%s: arrival %v, want %v", name, it.Arrival(), at)
+	}
+}
+
+func TestRetime(t *testing.T) {
+	n := NewNetwork(testStops, []Pattern{{Line: east, Stops: []int{1, 2, 3, 4}}, {Line: north, Stops: []int{4, 5, 6}}})
+	found := n.Plan(point(1), point(6), testOptions(2), 1)
+	if len(found) != 1 || found[0].Rides() != 2 {
+		t.Fatalf("planned %+v, want one itinerary with two rides", found)
+	}
+	planned := found[0]
+
+	tests := []struct {
+		name  string
+		waits map[int]Departure // Departure of each line, if predicted
+		live  bool
+	}{
+		{"no predictions", nil, false},
+		{"first ride predicted", map[int]Departure{100: {Wait: 2 * time.Minute, VehicleID: "11", Accessible: true, Live: true}}, false},
+		{"every ride predicted", map[int]Departure{
+			100: {Wait: 2 * time.Minute, VehicleID: "11", Live: true},
+			200: {Wait: 30 * time.Second, VehicleID: "22", Live: true},
+		}, true},
+	}
+	for _, tt := range tests {
+		var asked []time.Duration
+		it := planned.Retime(func(line types.Line, stopCode int, at time.Duration) (Departure, bool) {
+			asked = append(asked, at)
+			d, ok := tt.waits[line.Code]
+			return d, ok
+		})
+		if it.Live() != tt.live {
+			t.Errorf("%s: Live() = %v, want %v", tt.name, it.Live(), tt.live)
+		}
+		want := time.Duration(0)
+		ride := 0
+		for i, leg := range it.Legs {
+			if leg.Start != want {
+				t.Errorf("%s: leg %d starts at %v, want %v", tt.name, i, leg.Start, want)
+			}
+			if leg.Kind == Ride {
+				if asked[ride] != leg.Start {
+					t.Errorf("%s: ride %d asked for a bus at %v, want %v", tt.name, ride, asked[ride], leg.Start)
+				}
+				expected, ok := tt.waits[leg.Line.Code]
+				if !ok {
+					expected = Departure{Wait: EstimatedWait}
+				}
+				if leg.Departure != expected {
+					t.Errorf("%s: ride %d departs %+v, want %+v", tt.name, ride, leg.Departure, expected)
+				}
+				ride++
+			}
+			if leg.Duration != planned.Legs[i].Duration {
+				t.Errorf("%s: leg %d lasts %v, want %v", tt.name, i, leg.Duration, planned.Legs[i].Duration)
+			}
+			want = leg.End()
+		}
+		if it.Arrival() != want {
+			t.Errorf("%s: arrival %v, want %v", tt.name, it.Arrival(), want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package journey
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	minSpeedInterval = 20 * time.Second // Shortest time between two fixes of a vehicle giving a speed
+	maxSpeedInterval = 10 * time.Minute // Longest time between two fixes of a vehicle giving a speed
+	minRideSpeed     = 3.0 / 3.6        // Slowest speed credited to a line, in meters per second
+	maxRideSpeed     = 50.0 / 3.6       // Fastest speed credited to a line, in meters per second
+	minLineSamples   = 3                // Speeds a line needs before its own median is used
+	minNetworkSample = 10               // Speeds the network needs before its median replaces RideSpeed
+)
+
+// fix is the last known position of a vehicle
+type fix struct {
+	line  int
+	point geo.Point
+	at    time.Time
+}
+
+// samples are the speeds measured for a line in one snapshot
+type samples struct {
+	speeds []float64
+	at     time.Time // When the snapshot was observed
+}
+
+// Speeds estimates the running speed of each line from the distance its
+// vehicles cover between consecutive position snapshots. Speeds measured
+// more than maxSpeedInterval ago are dropped, so an idle tracker falls back
+// to RideSpeed rather than to how the traffic was hours earlier.
+type Speeds struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	fixes   map[int]fix     // Last position of each vehicle
+	samples map[int]samples // Speeds measured for each line code in the latest snapshot covering it
+	network float64         // Median of all the latest speeds, zero until enough are known
+}
+
+// NewSpeeds creates an empty speed tracker
+func NewSpeeds() *Speeds {
+	return &Speeds{
+		now:     time.Now,
+		fixes:   make(map[int]fix),
+		samples: make(map[int]samples),
+	}
+}
+
+// Observe records the vehicles of a positions snapshot, measuring the speed
+// of those seen in an earlier snapshot
+func (s *Speeds) Observe(lines []types.VehicleLine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	measured := make(map[int][]float64)
+	var latest time.Time
+	for _, line := range lines {
+		for _, vehicle := range line.Vehicles {
+			if !geo.ValidCoordinate(vehicle.Latitude, vehicle.Longitude) {
+				continue
+			}
+			current := fix{line: line.Code, point: geo.Point{Latitude: vehicle.Latitude, Longitude: vehicle.Longitude}, at: vehicle.LastUpdate}
+			if current.at.After(latest) {
+				latest = current.at
+			}
+			if previous, ok := s.fixes[vehicle.ID]; ok && previous.line == line.Code {
+				if interval := current.at.Sub(previous.at); interval >= minSpeedInterval && interval <= maxSpeedInterval {
+					meters := geo.Distance(previous.point.Latitude, previous.point.Longitude, current.point.Latitude, current.point.Longitude) * rideDetour
+					speed := min(max(meters/interval.Seconds(), minRideSpeed), maxRideSpeed)
+					measured[line.Code] = append(measured[line.Code], speed)
+				}
+			}
+			s.fixes[vehicle.ID] = current
+		}
+	}
+
+	// Forget the vehicles that have stopped reporting
+	for id, f := range s.fixes {
+		if latest.Sub(f.at) > maxSpeedInterval {
+			delete(s.fixes, id)
+		}
+	}
+
+	now := s.now()
+	for code, speeds := range measured {
+		s.samples[code] = samples{speeds: speeds, at: now}
+	}
+	s.expire(now)
+}
+
+// expire drops the speeds measured more than maxSpeedInterval before now
+// and updates the network median
+func (s *Speeds) expire(now time.Time) {
+	var all []float64
+	for code, line := range s.samples {
+		if now.Sub(line.at) > maxSpeedInterval {
+			delete(s.samples, code)
+			continue
+		}
+		all = append(all, line.speeds...)
+	}
+	s.network = 0
+	if len(all) >= minNetworkSample {
+		s.network = median(all)
+	}
+}
+
+// Speed returns the running speed of a line in meters per second and whether
+// it was measured for the line itself, falling back to the network median
+// and then to RideSpeed
+func (s *Speeds) Speed(line types.Line) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(s.now())
+	if speeds := s.samples[line.Code].speeds; len(speeds) >= minLineSamples {
+		return median(speeds), true
+	}
+	if s.network > 0 {
+		return s.network, false
+	}
+	return RideSpeed, false
+}
+
+// median returns the median of the values, reordering them
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
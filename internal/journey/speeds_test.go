@@ -0,0 +1,110 @@
+package journey
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+func TestSpeeds(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// snapshot places the vehicles of a line meters north of where they start
+	snapshot := func(code, vehicles int, meters float64, at time.Time) types.VehicleLine {
+		line := types.VehicleLine{Code: code}
+		for i := range vehicles {
+			line.Vehicles = append(line.Vehicles, types.Vehicle{
+				ID:         code*100 + i,
+				Latitude:   -23.55 + meters/111195,
+				Longitude:  -46.70 + float64(i)*0.01,
+				LastUpdate: at,
+			})
+		}
+		return line
+	}
+
+	tests := []struct {
+		name     string
+		vehicles int
+		meters   float64
+		interval time.Duration
+		want     float64
+		measured bool
+	}{
+		{"measured", 3, 500, time.Minute, 500 * rideDetour / 60, true},
+		{"too few vehicles", 2, 500, time.Minute, RideSpeed, false},
+		{"too soon", 3, 500, 10 * time.Second, RideSpeed, false},
+		{"too late", 3, 500, 20 * time.Minute, RideSpeed, false},
+		{"stopped", 3, 0, time.Minute, minRideSpeed, true},
+		{"too fast", 3, 5000, time.Minute, maxRideSpeed, true},
+	}
+	for _, tt := range tests {
+		s := NewSpeeds()
+		s.Observe([]types.VehicleLine{snapshot(1, tt.vehicles, 0, start)})
+		s.Observe([]types.VehicleLine{snapshot(1, tt.vehicles, tt.meters, start.Add(tt.interval))})
+		speed, measured := s.Speed(types.Line{Code: 1})
+		if math.Abs(speed-tt.want) > 0.01 || measured != tt.measured {
+			t.Errorf("%s: speed %.2f m/s, measured %v, want %.2f m/s, %v", tt.name, speed, measured, tt.want, tt.measured)
+		}
+	}
+}
+
+func TestSpeedsNetworkMedian(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var first, second []types.VehicleLine
+	for code := 1; code <= minNetworkSample; code++ {
+		vehicle := types.Vehicle{ID: code, Latitude: -23.55, Longitude: -46.70, LastUpdate: start}
+		first = append(first, types.VehicleLine{Code: code, Vehicles: []types.Vehicle{vehicle}})
+		vehicle.Latitude += float64(code*100) / 111195
+		vehicle.LastUpdate = start.Add(time.Minute)
+		second = append(second, types.VehicleLine{Code: code, Vehicles: []types.Vehicle{vehicle}})
+	}
+	s := NewSpeeds()
+	s.Observe(first)
+	s.Observe(second)
+
+	// One vehicle per line is too few for the lines' own speeds
+	speed, measured := s.Speed(types.Line{Code: 1})
+	want := 550 * rideDetour / 60
+	if measured || math.Abs(speed-want) > 0.05 {
+		t.Errorf("speed %.2f m/s, measured %v, want the network median %.2f m/s", speed, measured, want)
+	}
+}
+
+func TestSpeedsExpire(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var first, second []types.VehicleLine
+	for i := range minNetworkSample {
+		vehicle := types.Vehicle{ID: i, Latitude: -23.55, Longitude: -46.70 + float64(i)*0.01, LastUpdate: start}
+		first = append(first, types.VehicleLine{Code: 1, Vehicles: []types.Vehicle{vehicle}})
+		vehicle.Latitude += 500.0 / 111195
+		vehicle.LastUpdate = start.Add(time.Minute)
+		second = append(second, types.VehicleLine{Code: 1, Vehicles: []types.Vehicle{vehicle}})
+	}
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		want     float64
+		measured bool
+	}{
+		{"fresh", time.Minute, 500 * rideDetour / 60, true},
+		{"at the limit", maxSpeedInterval, 500 * rideDetour / 60, true},
+		{"stale", maxSpeedInterval + time.Second, RideSpeed, false},
+	}
+	for _, tt := range tests {
+		now := start
+		s := NewSpeeds()
+		s.now = func() time.Time { return now }
+		s.Observe(first)
+		s.Observe(second)
+		now = now.Add(tt.elapsed)
+		if speed, measured := s.Speed(types.Line{Code: 1}); math.Abs(speed-tt.want) > 0.01 || measured != tt.measured {
+			t.Errorf("%s: speed %.2f m/s, measured %v, want %.2f m/s, %v", tt.name, speed, measured, tt.want, tt.measured)
+		}
+		if speed, _ := s.Speed(types.Line{Code: 2}); tt.measured == (speed == RideSpeed) {
+			t.Errorf("%s: network speed %.2f m/s, want the median only while fresh", tt.name, speed)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package mapdraw
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// canvas draws simple shapes on an RGBA image
+type canvas struct {
+	img *image.RGBA
+}
+
+// newCanvas creates a canvas filled with the background color
+func newCanvas(width, height int, background color.Color) *canvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	return &canvas{img: img}
+}
+
+// rect fills a width by height rectangle with its top-left corner at x, y
+func (c *canvas) rect(x, y, width, height int, col color.Color) {
+	r := image.Rect(x, y, x+width, y+height).Intersect(c.img.Bounds())
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+// frame draws the outline of a rectangle
+func (c *canvas) frame(x, y, width, height, thickness int, col color.Color) {
+	c.rect(x, y, width, thickness, col)
+	c.rect(x, y+height-thickness, width, thickness, col)
+	c.rect(x, y, thickness, height, col)
+	c.rect(x+width-thickness, y, thickness, height, col)
+}
+
+// disc fills a circle of the given radius centered at x, y
+func (c *canvas) disc(x, y, radius int, col color.Color) {
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx*dx+dy*dy <= radius*radius {
+				c.img.Set(x+dx, y+dy, col)
+			}
+		}
+	}
+}
+
+// square fills a square of the given half side centered at x, y
+func (c *canvas) square(x, y, half int, col color.Color) {
+	c.rect(x-half, y-half, 2*half+1, 2*half+1, col)
+}
+
+// line draws a line of the given thickness between two points
+func (c *canvas) line(x0, y0, x1, y1, thickness int, col color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	err := dx + dy
+	for {
+		c.disc(x0, y0, thickness/2, col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// sign returns -1, 0 or 1 following the sign of n
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
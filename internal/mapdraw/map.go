@@ -0,0 +1,272 @@
+package mapdraw
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+)
+
+const (
+	padding       = 24  // Margin around the drawn points, in pixels
+	minSpanMeters = 500 // Smallest area drawn, so a single point gets context
+)
+
+var (
+	backgroundColor = color.RGBA{0xF4, 0xF2, 0xEC, 0xFF}
+	routeColor      = color.RGBA{0x5A, 0x6E, 0x8C, 0xFF}
+	stopColor       = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	outlineColor    = color.RGBA{0x22, 0x22, 0x22, 0xFF}
+	highlightColor  = color.RGBA{0xD6, 0x27, 0x28, 0xFF}
+	vehicleColor    = color.RGBA{0xF0, 0x8C, 0x00, 0xFF}
+	accessibleColor = color.RGBA{0x00, 0x5A, 0xC8, 0xFF}
+	panelColor      = color.NRGBA{0xFF, 0xFF, 0xFF, 0xE6}
+	textColor       = color.RGBA{0x11, 0x11, 0x11, 0xFF}
+)
+
+// Point is a geographic coordinate
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Vehicle is a vehicle position to draw
+type Vehicle struct {
+	Point
+	Accessible bool
+}
+
+// Labels are the localized texts of the legend
+type Labels struct {
+	Title      string // Heading of the legend, e.g. the line sign
+	Stop       string
+	Highlight  string
+	Vehicle    string
+	Accessible string
+}
+
+// Map describes a line map: its ordered stops, drawn as a route, its live
+// vehicles and an optional highlighted stop
+type Map struct {
+	Width     int
+	Height    int
+	Stops     []Point
+	Vehicles  []Vehicle
+	Highlight *Point
+	Labels    Labels
+}
+
+// Info describes a drawn map
+type Info struct {
+	South, West, North, East float64 // Area shown
+	ScaleMeters              int     // Length represented by the scale bar
+}
+
+// projection maps coordinates to pixels with an equirectangular projection
+// scaled for the latitude of the center, which is accurate at city scale
+type projection struct {
+	centerLat, centerLon float64
+	cosLat               float64
+	pixelsPerMeter       float64
+	width, height        int
+}
+
+// project returns the pixel of a coordinate
+func (p projection) project(pt Point) (int, int) {
+	x := (pt.Longitude - p.centerLon) * p.cosLat * metersPerDegree * p.pixelsPerMeter
+	y := (pt.Latitude - p.centerLat) * metersPerDegree * p.pixelsPerMeter
+	return p.width/2 + int(math.Round(x)), p.height/2 - int(math.Round(y))
+}
+
+// metersPerDegree is the length of a degree of latitude in meters
+const metersPerDegree = math.Pi * geo.EarthRadius / 180
+
+// PNG draws the map and encodes it as PNG
+func (m Map) PNG() ([]byte, Info, error) {
+	img, info := m.Draw()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, Info{}, fmt.Errorf("failed to encode map: %w", err)
+	}
+	return buf.Bytes(), info, nil
+}
+
+// Draw draws the map
+func (m Map) Draw() (*image.RGBA, Info) {
+	c := newCanvas(m.Width, m.Height, backgroundColor)
+	proj, info := m.fit()
+	scale := 2
+	if m.Width < 500 {
+		scale = 1
+	}
+
+	// Route through the ordered stops
+	for i := 1; i < len(m.Stops); i++ {
+		x0, y0 := proj.project(m.Stops[i-1])
+		x1, y1 := proj.project(m.Stops[i])
+		c.line(x0, y0, x1, y1, 3, routeColor)
+	}
+	for _, stop := range m.Stops {
+		x, y := proj.project(stop)
+		drawStop(c, x, y)
+	}
+
+	// Vehicles, accessible ones as squares so they differ by shape as well as color
+	for _, vehicle := range m.Vehicles {
+		x, y := proj.project(vehicle.Point)
+		drawVehicle(c, x, y, vehicle.Accessible)
+	}
+
+	if m.Highlight != nil {
+		x, y := proj.project(*m.Highlight)
+		drawHighlight(c, x, y)
+	}
+
+	m.drawLegend(c, scale)
+	info.ScaleMeters = drawScaleBar(c, proj, scale)
+	return c.img, info
+}
+
+// fit computes the projection that shows every point with a margin
+func (m Map) fit() (projection, Info) {
+	points := append([]Point(nil), m.Stops...)
+	for _, vehicle := range m.Vehicles {
+		points = append(points, vehicle.Point)
+	}
+	if m.Highlight != nil {
+		points = append(points, *m.Highlight)
+	}
+
+	info := Info{South: 90, West: 180, North: -90, East: -180}
+	for _, pt := range points {
+		info.South = math.Min(info.South, pt.Latitude)
+		info.West = math.Min(info.West, pt.Longitude)
+		info.North = math.Max(info.North, pt.Latitude)
+		info.East = math.Max(info.East, pt.Longitude)
+	}
+	if len(points) == 0 {
+		info = Info{}
+	}
+
+	p := projection{
+		centerLat: (info.South + info.North) / 2,
+		centerLon: (info.West + info.East) / 2,
+		width:     m.Width,
+		height:    m.Height,
+	}
+	p.cosLat = math.Cos(p.centerLat * math.Pi / 180)
+	spanX := math.Max((info.East-info.West)*p.cosLat*metersPerDegree, minSpanMeters)
+	spanY := math.Max((info.North-info.South)*metersPerDegree, minSpanMeters)
+	p.pixelsPerMeter = math.Min(
+		float64(m.Width-2*padding)/spanX,
+		float64(m.Height-2*padding)/spanY,
+	)
+	return p, info
+}
+
+// drawStop draws a stop marker
+func drawStop(c *canvas, x, y int) {
+	c.disc(x, y, 5, outlineColor)
+	c.disc(x, y, 3, stopColor)
+}
+
+// drawHighlight draws the highlighted stop marker
+func drawHighlight(c *canvas, x, y int) {
+	c.disc(x, y, 10, outlineColor)
+	c.disc(x, y, 8, stopColor)
+	c.disc(x, y, 6, highlightColor)
+}
+
+// drawVehicle draws a vehicle marker
+func drawVehicle(c *canvas, x, y int, accessible bool) {
+	if accessible {
+		c.square(x, y, 7, outlineColor)
+		c.square(x, y, 5, accessibleColor)
+		c.square(x, y, 1, stopColor)
+		return
+	}
+	c.disc(x, y, 7, outlineColor)
+	c.disc(x, y, 5, vehicleColor)
+}
+
+// drawLegend draws the legend panel in the top-left corner
+func (m Map) drawLegend(c *canvas, scale int) {
+	type entry struct {
+		label  string
+		marker func(x, y int)
+	}
+	entries := []entry{
+		{m.Labels.Stop, func(x, y int) { drawStop(c, x, y) }},
+		{m.Labels.Vehicle, func(x, y int) { drawVehicle(c, x, y, false) }},
+		{m.Labels.Accessible, func(x, y int) { drawVehicle(c, x, y, true) }},
+	}
+	if m.Highlight != nil {
+		entries = append(entries, entry{m.Labels.Highlight, func(x, y int) { drawHighlight(c, x, y) }})
+	}
+
+	rowHeight := max(textHeight(scale)+6, 24)
+	markerWidth := 28
+	width := textWidth(m.Labels.Title, scale)
+	for _, e := range entries {
+		width = max(width, markerWidth+textWidth(e.label, scale))
+	}
+	height := len(entries) * rowHeight
+	if m.Labels.Title != "" {
+		height += rowHeight
+	}
+
+	x, y := 8, 8
+	c.rect(x, y, width+16, height+12, panelColor)
+	c.frame(x, y, width+16, height+12, 1, outlineColor)
+	x, y = x+8, y+6
+	if m.Labels.Title != "" {
+		c.text(x, y+(rowHeight-textHeight(scale))/2, m.Labels.Title, scale, textColor)
+		y += rowHeight
+	}
+	for _, e := range entries {
+		e.marker(x+10, y+rowHeight/2)
+		c.text(x+markerWidth, y+(rowHeight-textHeight(scale))/2, e.label, scale, textColor)
+		y += rowHeight
+	}
+}
+
+// drawScaleBar draws a scale bar with a round length in the bottom-right
+// corner and returns the length it represents in meters
+func drawScaleBar(c *canvas, p projection, scale int) int {
+	meters := niceLength(float64(p.width) / 4 / p.pixelsPerMeter)
+	length := int(math.Round(float64(meters) * p.pixelsPerMeter))
+	label := fmt.Sprintf("%d M", meters)
+	if meters >= 1000 {
+		label = fmt.Sprintf("%g KM", float64(meters)/1000)
+	}
+
+	right := p.width - 16
+	bottom := p.height - 16
+	left := right - length
+	labelWidth := textWidth(label, scale)
+	c.rect(left-8, bottom-textHeight(scale)-20, max(length, labelWidth)+16, textHeight(scale)+28, panelColor)
+	c.rect(left, bottom-4, length, 4, outlineColor)
+	c.rect(left, bottom-10, 2, 10, outlineColor)
+	c.rect(right-2, bottom-10, 2, 10, outlineColor)
+	c.text(right-labelWidth, bottom-textHeight(scale)-14, label, scale, textColor)
+	return meters
+}
+
+// niceLength returns the largest 1, 2 or 5 times a power of ten that does not
+// exceed meters, and at least one meter
+func niceLength(meters float64) int {
+	if meters < 1 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(meters)))
+	for _, step := range []float64{5, 2, 1} {
+		if step*magnitude <= meters {
+			return int(step * magnitude)
+		}
+	}
+	return int(magnitude)
+}
@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Kind classifies tool errors
+type Kind string
+
+const (
+	KindInvalidArgument Kind = "invalid_argument" // The call arguments are invalid
+	KindNotFound        Kind = "not_found"        // The requested entity does not exist
+	KindAmbiguous       Kind = "ambiguous"        // An argument matches several entities
+	KindUnauthorized    Kind = "unauthorized"     // The SPTrans API rejected the credentials
+	KindUpstream        Kind = "upstream"         // The SPTrans API failed
+	KindTimeout         Kind = "timeout"          // The call exceeded its deadline
+	KindInternal        Kind = "internal"         // An unexpected server error
+)
+
+// Error is a classified tool error whose message is a localizable catalog key
+type Error struct {
+	Kind Kind
+	Key  string // Message key in the i18n catalog
+	Args []any  // Message arguments
+	Err  error  // Underlying error, if any
+
+	Candidates []types.CandidateResponse // Entities an ambiguous argument may refer to
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Key, e.Err)
+	}
+	return e.Key
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Invalid returns an invalid argument error with the message for key
+func Invalid(key string, args ...any) *Error {
+	return &Error{Kind: KindInvalidArgument, Key: key, Args: args}
+}
+
+// Missing returns a not found error with the message for key
+func Missing(key string, args ...any) *Error {
+	return &Error{Kind: KindNotFound, Key: key, Args: args}
+}
+
+// Ambiguous returns an ambiguous argument error with the message for key,
+// listing the entities the argument may refer to
+func Ambiguous(key string, candidates []types.CandidateResponse, args ...any) *Error {
+	return &Error{Kind: KindAmbiguous, Key: key, Args: args, Candidates: candidates}
+}
+
+// Failed returns an upstream error with the message for key, formatted with err
+func Failed(key string, err error) *Error {
+	return &Error{Kind: KindUpstream, Key: key, Args: []any{err}, Err: err}
+}
+
+// Classify converts any error into a classified Error, refining upstream
+// errors caused by deadlines or rejected credentials
+func Classify(err error) *Error {
+	var toolErr *Error
+	if !errors.As(err, &toolErr) {
+		toolErr = &Error{Kind: KindInternal, Key: "error.internal", Args: []any{err}, Err: err}
+	}
+	if toolErr.Kind != KindUpstream && toolErr.Kind != KindInternal {
+		return toolErr
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: KindTimeout, Key: "error.timeout", Err: err}
+	case isUnauthorized(err):
+		return &Error{Kind: KindUnauthorized, Key: toolErr.Key, Args: toolErr.Args, Err: err}
+	}
+	return toolErr
+}
+
+// Describe classifies an error and describes it with a localized message
+func Describe(err error, locale i18n.Locale) types.ErrorResponse {
+	toolErr := Classify(err)
+	return types.ErrorResponse{
+		Kind:       string(toolErr.Kind),
+		Message:    i18n.T(locale, toolErr.Key, toolErr.Args...),
+		Code:       upstreamCode(err),
+		Candidates: toolErr.Candidates,
+	}
+}
+
+// isUnauthorized reports whether the SPTrans API rejected the credentials
+func isUnauthorized(err error) bool {
+	var apiErr *types.APIError
+	return errors.As(err, &apiErr) && (apiErr.Code == 401 || apiErr.Code == 403)
+}
+
+// upstreamCode returns the HTTP status of an SPTrans API error, or 0
+func upstreamCode(err error) int {
+	var apiErr *types.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
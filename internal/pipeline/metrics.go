@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Metrics aggregates call counts and durations per tool
+type Metrics struct {
+	mu    sync.Mutex
+	tools map[string]*toolMetrics
+}
+
+// toolMetrics holds the aggregated figures of a single tool
+type toolMetrics struct {
+	calls         int
+	errors        int
+	totalDuration time.Duration
+	maxDuration   time.Duration
+}
+
+// NewMetrics creates an empty metrics registry
+func NewMetrics() *Metrics {
+	return &Metrics{tools: make(map[string]*toolMetrics)}
+}
+
+// Record adds a call of the tool to the metrics
+func (m *Metrics) Record(tool string, elapsed time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tools[tool]
+	if !ok {
+		t = &toolMetrics{}
+		m.tools[tool] = t
+	}
+	t.calls++
+	if failed {
+		t.errors++
+	}
+	t.totalDuration += elapsed
+	if elapsed > t.maxDuration {
+		t.maxDuration = elapsed
+	}
+}
+
+// Snapshot returns the current metrics of every called tool, sorted by name
+func (m *Metrics) Snapshot() []types.ToolMetricsResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make([]types.ToolMetricsResponse, 0, len(m.tools))
+	for name, t := range m.tools {
+		snapshot = append(snapshot, types.ToolMetricsResponse{
+			Tool:          name,
+			Calls:         t.calls,
+			Errors:        t.errors,
+			AverageMillis: t.totalDuration.Milliseconds() / int64(t.calls),
+			MaxMillis:     t.maxDuration.Milliseconds(),
+		})
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return snapshot[i].Tool < snapshot[j].Tool
+	})
+	return snapshot
+}
@@ -0,0 +1,142 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/render"
+)
+
+// Validator is implemented by tool arguments that can check themselves
+type Validator interface {
+	Validate() error
+}
+
+// LocaleFunc returns the locale selected for a session
+type LocaleFunc func(ss *mcp.ServerSession) i18n.Locale
+
+// Validate rejects calls whose arguments implement Validator and fail it
+func Validate() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*mcp.CallToolResultFor[any], error) {
+			if v, ok := call.Args.(Validator); ok {
+				if err := v.Validate(); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// Timeout bounds each call by the tool's timeout, falling back to the default
+func Timeout(fallback time.Duration, overrides map[string]time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*mcp.CallToolResultFor[any], error) {
+			timeout, ok := overrides[call.Tool]
+			if !ok {
+				timeout = fallback
+			}
+			if timeout <= 0 {
+				return next(ctx, call)
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, call)
+		}
+	}
+}
+
+// Recover turns panics in the tool into internal errors
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (result *mcp.CallToolResultFor[any], err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("panic in tool %s: %v\n%s", call.Tool, r, debug.Stack())
+					result = nil
+					err = &Error{Kind: KindInternal, Key: "error.internal", Args: []any{r}, Err: fmt.Errorf("panic: %v", r)}
+				}
+			}()
+			return next(ctx, call)
+		}
+	}
+}
+
+// Log logs each call with its duration and outcome and records it in metrics
+func Log(logger *log.Logger, metrics *Metrics) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*mcp.CallToolResultFor[any], error) {
+			start := time.Now()
+			result, err := next(ctx, call)
+			elapsed := time.Since(start)
+
+			failed := err != nil || (result != nil && result.IsError)
+			metrics.Record(call.Tool, elapsed, failed)
+			if failed {
+				logger.Printf("tool %s failed in %s", call.Tool, elapsed.Round(time.Millisecond))
+			} else {
+				logger.Printf("tool %s completed in %s", call.Tool, elapsed.Round(time.Millisecond))
+			}
+			return result, err
+		}
+	}
+}
+
+// Errors classifies tool errors and reports them as error results with a
+// localized message, followed by the candidates of an ambiguous argument, and
+// the classification as structured content
+func Errors(locale LocaleFunc) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*mcp.CallToolResultFor[any], error) {
+			result, err := next(ctx, call)
+			if err == nil {
+				return result, nil
+			}
+
+			response := Describe(err, locale(call.Session))
+			text := response.Message
+			for _, c := range response.Candidates {
+				text += "\n" + i18n.T(locale(call.Session), "error.candidate", c.Code, c.Label, c.Description)
+			}
+			return &mcp.CallToolResultFor[any]{
+				IsError:           true,
+				Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+				StructuredContent: response,
+			}, nil
+		}
+	}
+}
+
+// Render renders the response object of successful calls as text content in
+// the requested format, or the default one, and the session locale, keeping
+// the full object as structured content
+func Render(format render.Format, locale LocaleFunc) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*mcp.CallToolResultFor[any], error) {
+			result, err := next(ctx, call)
+			if err != nil || result != nil {
+				return result, err
+			}
+
+			f, err := render.ParseFormat(call.Format(), format)
+			if err != nil {
+				return nil, Invalid("error.invalid_format", err)
+			}
+			text, err := render.Render(call.Response, f, locale(call.Session))
+			if err != nil {
+				return nil, &Error{Kind: KindInternal, Key: "error.render", Args: []any{err}, Err: err}
+			}
+
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+				StructuredContent: call.Response,
+			}, nil
+		}
+	}
+}
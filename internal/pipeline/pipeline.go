@@ -0,0 +1,77 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Call carries a single tool invocation through the middleware chain
+type Call struct {
+	Tool     string             // Tool name
+	Session  *mcp.ServerSession // Calling session
+	Args     any                // Typed tool arguments
+	Response any                // Response object produced by the tool function
+}
+
+// Format returns the per-call output format requested in the arguments, if any
+func (c *Call) Format() string {
+	v := reflect.ValueOf(c.Args)
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	field := v.FieldByName("Format")
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
+
+// Handler runs a tool call. A nil result with a nil error means the response
+// object in the call is waiting to be rendered.
+type Handler func(ctx context.Context, call *Call) (*mcp.CallToolResultFor[any], error)
+
+// Middleware wraps a Handler with a cross-cutting concern
+type Middleware func(next Handler) Handler
+
+// Func is a typed tool function. It returns the response object to render, or
+// a ready-made result for responses that are not rendered as text.
+type Func[In any] func(ctx context.Context, call *Call, args In) (any, error)
+
+// Pipeline applies a middleware chain to typed tool functions
+type Pipeline struct {
+	middleware []Middleware
+}
+
+// New creates a pipeline. Middleware runs in the given order, the first one
+// outermost.
+func New(middleware ...Middleware) *Pipeline {
+	return &Pipeline{middleware: middleware}
+}
+
+// Tool wraps a typed tool function into an MCP tool handler
+func Tool[In any](p *Pipeline, name string, fn Func[In]) mcp.ToolHandlerFor[In, any] {
+	var handler Handler = func(ctx context.Context, call *Call) (*mcp.CallToolResultFor[any], error) {
+		response, err := fn(ctx, call, call.Args.(In))
+		if err != nil {
+			return nil, err
+		}
+		if result, ok := response.(*mcp.CallToolResultFor[any]); ok {
+			return result, nil
+		}
+		call.Response = response
+		return nil, nil
+	}
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		handler = p.middleware[i](handler)
+	}
+
+	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[In]) (*mcp.CallToolResultFor[any], error) {
+		return handler(ctx, &Call{
+			Tool:    name,
+			Session: ss,
+			Args:    params.Arguments,
+		})
+	}
+}
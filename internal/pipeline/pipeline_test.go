@@ -0,0 +1,222 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/render"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// testArgs are valid when Value is not negative
+type testArgs struct {
+	Value  int
+	Format string
+}
+
+func (a testArgs) Validate() error {
+	if a.Value < 0 {
+		return Invalid("error.invalid_format", "negative")
+	}
+	return nil
+}
+
+func english(*mcp.ServerSession) i18n.Locale { return i18n.English }
+
+// newTestPipeline builds the middleware chain in the order the server uses
+func newTestPipeline(metrics *Metrics, timeouts map[string]time.Duration) *Pipeline {
+	return New(
+		Log(log.New(io.Discard, "", 0), metrics),
+		Errors(english),
+		Recover(),
+		Render(render.JSON, english),
+		Timeout(time.Second, timeouts),
+		Validate(),
+	)
+}
+
+func call[In any](p *Pipeline, name string, fn Func[In], args In) (*mcp.CallToolResultFor[any], error) {
+	return Tool(p, name, fn)(context.Background(), nil, &mcp.CallToolParamsFor[In]{Arguments: args})
+}
+
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     testArgs
+		fn       Func[testArgs]
+		timeouts map[string]time.Duration
+		called   bool
+		kind     Kind   // Error kind, empty for a successful call
+		text     string // Part of the text content
+	}{
+		{
+			name:   "success",
+			args:   testArgs{Value: 1},
+			fn:     func(context.Context, *Call, testArgs) (any, error) { return map[string]int{"value": 1}, nil },
+			called: true,
+			text:   `"value":1`,
+		},
+		{
+			name: "validated before the tool runs",
+			args: testArgs{Value: -1},
+			fn: func(context.Context, *Call, testArgs) (any, error) {
+				panic("the tool ran with invalid arguments")
+			},
+			kind: KindInvalidArgument,
+			text: "Invalid format parameter: negative",
+		},
+		{
+			name:   "panic recovered",
+			args:   testArgs{Value: 1},
+			fn:     func(context.Context, *Call, testArgs) (any, error) { panic("boom") },
+			called: true,
+			kind:   KindInternal,
+			text:   "Internal error: boom",
+		},
+		{
+			name: "default timeout",
+			args: testArgs{Value: 1},
+			fn: func(ctx context.Context, _ *Call, _ testArgs) (any, error) {
+				if _, ok := ctx.Deadline(); !ok {
+					return nil, errors.New("no deadline")
+				}
+				return "ok", nil
+			},
+			called: true,
+			text:   "ok",
+		},
+		{
+			name:     "tool timeout",
+			args:     testArgs{Value: 1},
+			timeouts: map[string]time.Duration{"tool timeout": 10 * time.Millisecond},
+			fn: func(ctx context.Context, _ *Call, _ testArgs) (any, error) {
+				<-ctx.Done()
+				return nil, Failed("error.internal", ctx.Err())
+			},
+			called: true,
+			kind:   KindTimeout,
+			text:   "took too long",
+		},
+		{
+			name:     "timeout disabled",
+			args:     testArgs{Value: 1},
+			timeouts: map[string]time.Duration{"timeout disabled": 0},
+			fn: func(ctx context.Context, _ *Call, _ testArgs) (any, error) {
+				if _, ok := ctx.Deadline(); ok {
+					return nil, errors.New("unexpected deadline")
+				}
+				return "ok", nil
+			},
+			called: true,
+			text:   "ok",
+		},
+		{
+			name: "ambiguous with candidates",
+			args: testArgs{Value: 1},
+			fn: func(context.Context, *Call, testArgs) (any, error) {
+				return nil, Ambiguous("error.ambiguous_stop", []types.CandidateResponse{{Code: 7, Label: "Sé", Description: "Praça da Sé"}}, "se", 2, "stop_code")
+			},
+			called: true,
+			kind:   KindAmbiguous,
+			text:   "7: Sé (Praça da Sé)",
+		},
+		{
+			name:   "invalid format",
+			args:   testArgs{Value: 1, Format: "yaml"},
+			fn:     func(context.Context, *Call, testArgs) (any, error) { return "ok", nil },
+			called: true,
+			kind:   KindInvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		metrics := NewMetrics()
+		called := false
+		fn := func(ctx context.Context, c *Call, args testArgs) (any, error) {
+			called = true
+			return tt.fn(ctx, c, args)
+		}
+		result, err := call(newTestPipeline(metrics, tt.timeouts), tt.name, fn, tt.args)
+		if err != nil {
+			t.Errorf("%s: the pipeline returned error %v instead of an error result", tt.name, err)
+			continue
+		}
+		if called != tt.called {
+			t.Errorf("%s: tool called = %v, want %v", tt.name, called, tt.called)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, tt.text) {
+			t.Errorf("%s: text %q does not contain %q", tt.name, text, tt.text)
+		}
+		if result.IsError != (tt.kind != "") {
+			t.Errorf("%s: IsError = %v, want %v", tt.name, result.IsError, tt.kind != "")
+		}
+		if tt.kind != "" {
+			if response, ok := result.StructuredContent.(types.ErrorResponse); !ok || response.Kind != string(tt.kind) {
+				t.Errorf("%s: structured content %+v, want kind %s", tt.name, result.StructuredContent, tt.kind)
+			}
+		}
+		snapshot := metrics.Snapshot()
+		if len(snapshot) != 1 || snapshot[0].Calls != 1 || (snapshot[0].Errors == 1) != (tt.kind != "") {
+			t.Errorf("%s: metrics %+v, want one call failing %v", tt.name, snapshot, tt.kind != "")
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, c *Call) (*mcp.CallToolResultFor[any], error) {
+				order = append(order, "enter "+name)
+				result, err := next(ctx, c)
+				order = append(order, "leave "+name)
+				return result, err
+			}
+		}
+	}
+	p := New(trace("outer"), trace("inner"))
+	fn := func(context.Context, *Call, testArgs) (any, error) {
+		order = append(order, "tool")
+		return nil, nil
+	}
+	if _, err := call(p, "order", fn, testArgs{}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"enter outer", "enter inner", "tool", "leave inner", "leave outer"}
+	if !slices.Equal(order, want) {
+		t.Errorf("order %v, want %v", order, want)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind Kind
+		code int
+	}{
+		{"invalid", Invalid("error.required", "x"), KindInvalidArgument, 0},
+		{"missing", Missing("error.unknown_line", "x"), KindNotFound, 0},
+		{"upstream", Failed("error.resolve_line", &types.APIError{Code: 500}), KindUpstream, 500},
+		{"unauthorized", Failed("error.resolve_line", &types.APIError{Code: 401}), KindUnauthorized, 401},
+		{"deadline", Failed("error.resolve_line", context.DeadlineExceeded), KindTimeout, 0},
+		{"plain error", errors.New("boom"), KindInternal, 0},
+		{"plain deadline", context.DeadlineExceeded, KindTimeout, 0},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err); got.Kind != tt.kind {
+			t.Errorf("%s: Classify kind %s, want %s", tt.name, got.Kind, tt.kind)
+		}
+		if got := Describe(tt.err, i18n.English); got.Code != tt.code || got.Kind != string(tt.kind) {
+			t.Errorf("%s: Describe = %+v, want kind %s code %d", tt.name, got, tt.kind, tt.code)
+		}
+	}
+}
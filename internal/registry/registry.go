@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/render"
+)
+
+// Group names a set of related tools that are enabled or disabled together
+type Group string
+
+const (
+	Lines       Group = "lines"       // Line search
+	Stops       Group = "stops"       // Stop search and stop lists
+	Positions   Group = "positions"   // Live vehicle positions
+	Predictions Group = "predictions" // Arrival predictions
+	Catalog     Group = "catalog"     // Offline network catalog
+	Analytics   Group = "analytics"   // Derived analyses over live data
+	Session     Group = "session"     // Per-session preferences
+	Admin       Group = "admin"       // Server administration
+)
+
+// Groups lists every group in registration order
+var Groups = []Group{Lines, Stops, Positions, Predictions, Catalog, Analytics, Session, Admin}
+
+// Tool describes a registered tool
+type Tool struct {
+	Name  string
+	Group Group
+
+	install func(server *mcp.Server, tool *mcp.Tool)
+}
+
+// Registry holds the tools known to the server, grouped by name
+type Registry struct {
+	pipeline *pipeline.Pipeline
+	tools    []Tool
+}
+
+// New creates an empty registry whose tools run through the given pipeline
+func New(p *pipeline.Pipeline) *Registry {
+	return &Registry{pipeline: p}
+}
+
+// Add registers a typed tool function under a group
+func Add[In any](r *Registry, group Group, name string, fn pipeline.Func[In]) {
+	handler := pipeline.Tool(r.pipeline, name, fn)
+	r.tools = append(r.tools, Tool{
+		Name:  name,
+		Group: group,
+		install: func(server *mcp.Server, tool *mcp.Tool) {
+			tool.InputSchema = inputSchema[In](name)
+			mcp.AddTool(server, tool, handler)
+		},
+	})
+}
+
+// inputSchema infers the input schema of a tool and describes its format
+// argument from the supported render formats
+func inputSchema[In any](name string) *jsonschema.Schema {
+	schema, err := jsonschema.For[In]()
+	if err != nil {
+		panic(fmt.Sprintf("inferring input schema of tool %q: %v", name, err))
+	}
+	if format, ok := schema.Properties["format"]; ok {
+		format.Description = FormatDescription
+	}
+	return schema
+}
+
+// FormatDescription describes the format argument shared by every tool
+var FormatDescription = "Output format of the text content: " + render.FormatList() + " (defaults to the server setting)"
+
+// Tools returns the registered tools in registration order
+func (r *Registry) Tools() []Tool {
+	return r.tools
+}
+
+// Install adds the tools of the enabled groups to the server, describing them
+// in the given locale, and returns the installed tools
+func (r *Registry) Install(server *mcp.Server, enabled map[Group]bool, locale i18n.Locale) []Tool {
+	var installed []Tool
+	for _, t := range r.tools {
+		if !enabled[t.Group] {
+			continue
+		}
+		t.install(server, &mcp.Tool{
+			Name:        t.Name,
+			Description: Description(t.Name, locale),
+		})
+		installed = append(installed, t)
+	}
+	return installed
+}
+
+// Description returns the localized description of a tool
+func Description(name string, locale i18n.Locale) string {
+	return i18n.T(locale, "tool."+name)
+}
+
+// ResolveGroups computes the enabled groups from comma-separated lists of
+// enabled and disabled group names. An empty enabled list enables every group.
+func ResolveGroups(enable, disable string) (map[Group]bool, error) {
+	enabled := make(map[Group]bool)
+	if strings.TrimSpace(enable) == "" {
+		for _, g := range Groups {
+			enabled[g] = true
+		}
+	} else {
+		groups, err := parseGroups(enable)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			enabled[g] = true
+		}
+	}
+
+	groups, err := parseGroups(disable)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		delete(enabled, g)
+	}
+	return enabled, nil
+}
+
+// parseGroups parses a comma-separated list of group names
+func parseGroups(list string) ([]Group, error) {
+	var groups []Group
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		group := Group(name)
+		if !isGroup(group) {
+			return nil, fmt.Errorf("unknown tool group %q", name)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// isGroup reports whether the group is known
+func isGroup(group Group) bool {
+	for _, g := range Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thunderjr/sptrans-mcp/internal/render"
+)
+
+func TestInputSchemaFormat(t *testing.T) {
+	type params struct {
+		Query  string `json:"query" jsonschema:"Search text"`
+		Format string `json:"format,omitempty"`
+	}
+	schema := inputSchema[params]("test")
+	if got := schema.Properties["query"].Description; got != "Search text" {
+		t.Errorf("query description = %q, want the tag text", got)
+	}
+	format := schema.Properties["format"].Description
+	for _, f := range render.Formats {
+		if !strings.Contains(format, string(f)) {
+			t.Errorf("format description %q does not mention %s", format, f)
+		}
+	}
+}
+
+func TestResolveGroups(t *testing.T) {
+	tests := []struct {
+		enable, disable string
+		want            []Group
+	}{
+		{"", "", Groups},
+		{"", "admin", []Group{Lines, Stops, Positions, Predictions, Catalog, Analytics, Session}},
+		{"lines, session", "", []Group{Lines, Session}},
+		{"lines,admin", "ADMIN", []Group{Lines}},
+	}
+	for _, tt := range tests {
+		enabled, err := ResolveGroups(tt.enable, tt.disable)
+		if err != nil {
+			t.Fatalf("ResolveGroups(%q, %q): %v", tt.enable, tt.disable, err)
+		}
+		if len(enabled) != len(tt.want) {
+			t.Errorf("ResolveGroups(%q, %q) = %v, want %v", tt.enable, tt.disable, enabled, tt.want)
+		}
+		for _, g := range tt.want {
+			if !enabled[g] {
+				t.Errorf("ResolveGroups(%q, %q) leaves out %s", tt.enable, tt.disable, g)
+			}
+		}
+	}
+
+	if _, err := ResolveGroups("lines,unknown", ""); err == nil {
+		t.Error("ResolveGroups accepted an unknown group")
+	}
+}
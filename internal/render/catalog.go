@@ -0,0 +1,22 @@
+package render
+
+import "github.com/thunderjr/sptrans-mcp/internal/types"
+
+// renderCatalogStatus renders the state of the network catalog and its crawler
+func renderCatalogStatus(w *writer, r types.CatalogStatusResponse) {
+	w.heading("render.catalog_status", r.Version, r.Lines, r.Stops, r.Corridors)
+	if r.UpdatedAt != "" {
+		w.item("render.catalog_updated", r.UpdatedAt)
+	}
+	switch {
+	case !r.Crawler:
+		w.item("render.crawler_disabled")
+	case r.Crawling:
+		w.item("render.crawler_running", r.LinesFetched, r.LinesPending, r.Failures)
+	case r.NextCrawl != "":
+		w.item("render.crawler_next", r.NextCrawl)
+	}
+	if r.LastError != "" {
+		w.item("render.crawler_error", r.LastError)
+	}
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// fareModeKeys maps the fare modes to their message keys
+var fareModeKeys = map[string]string{
+	"bus":  "render.fare_bus",
+	"rail": "render.fare_rail",
+}
+
+// fareBreakKeys maps the reasons an integration breaks to their message keys
+var fareBreakKeys = map[string]string{
+	"window":    "render.fare_break_window",
+	"boardings": "render.fare_break_boardings",
+	"rail":      "render.fare_break_rail",
+}
+
+// renderFare renders the response of calculate_fare
+func renderFare(w *writer, r types.FareResponse) {
+	w.heading("render.fare", w.money(r.Total), r.Category, r.FaresPaid)
+	if w.compact() {
+		return
+	}
+	for i, leg := range r.Legs {
+		charge := w.t("render.fare_leg", i+1, w.t(fareModeKeys[leg.Mode]), w.money(leg.Amount))
+		if leg.BoardAt != "" {
+			charge += w.t("render.fare_at", leg.BoardAt)
+		}
+		switch {
+		case leg.Integrated:
+			charge += w.t("render.fare_integrated")
+		case leg.Break != "":
+			charge += fareBreak(w, leg.Break, r)
+		}
+		w.itemf("%s", charge)
+	}
+}
+
+// tripFare describes the fare of a trip option, warning when the rides cannot
+// all be integrated
+func tripFare(w *writer, fare *types.FareResponse) string {
+	if fare == nil {
+		return ""
+	}
+	text := w.t("render.trip_fare", w.money(fare.Total))
+	for _, leg := range fare.Legs {
+		if leg.Break != "" {
+			text += fareBreak(w, leg.Break, *fare)
+			break
+		}
+	}
+	return text
+}
+
+// fareBreak describes why an integration was broken
+func fareBreak(w *writer, reason string, fare types.FareResponse) string {
+	switch reason {
+	case "window":
+		return w.t(fareBreakKeys[reason], fare.WindowMinutes)
+	case "boardings":
+		return w.t(fareBreakKeys[reason], fare.MaxBoardings)
+	}
+	return w.t(fareBreakKeys[reason])
+}
+
+// money formats an amount in reais
+func (w *writer) money(amount float64) string {
+	text := w.t("render.money", amount)
+	if w.locale == i18n.Portuguese {
+		text = strings.Replace(text, ".", ",", 1)
+	}
+	return text
+}
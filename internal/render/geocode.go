@@ -0,0 +1,61 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geocode"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// precisionKeys maps the precision of a geocoded location to its description
+var precisionKeys = map[string]string{
+	geocode.PrecisionExact:        "render.at_number",
+	geocode.PrecisionInterpolated: "render.interpolated",
+	geocode.PrecisionNearest:      "render.nearest_number",
+	geocode.PrecisionStreet:       "render.street_only",
+	geocode.PrecisionPlace:        "render.by_stop_name",
+	geocode.PrecisionCoordinate:   "render.coordinate",
+}
+
+// renderGeocode renders the response of geocode_address
+func renderGeocode(w *writer, r types.GeocodeAddressResponse) {
+	if r.CatalogSize == 0 {
+		w.line("render.empty_catalog")
+		return
+	}
+	if !w.compact() {
+		w.heading("render.geocode", r.TotalResults, r.Query)
+	}
+	for _, result := range r.Results {
+		name := result.Name
+		if result.Number != 0 {
+			name = w.t("render.street_number", name, result.Number)
+		}
+		w.item("render.geocode_result", name, result.Latitude, result.Longitude,
+			w.t(precisionKeys[result.Precision]), result.Score)
+		if result.StopCode != 0 && result.Kind == geocode.KindStreet && !w.compact() {
+			w.line("render.geocode_stop", result.StopName, result.StopCode)
+		}
+	}
+}
+
+// renderReverseGeocode renders the response of reverse_geocode
+func renderReverseGeocode(w *writer, r types.ReverseGeocodeResponse) {
+	if r.CatalogSize == 0 {
+		w.line("render.empty_catalog")
+		return
+	}
+	if !r.Found {
+		w.line("render.no_street", r.Latitude, r.Longitude)
+		return
+	}
+	street := r.Street
+	if r.Number != 0 {
+		street = w.t("render.street_number", street, r.Number)
+	}
+	w.heading("render.reverse_geocode", street, w.t(precisionKeys[r.Precision]))
+	w.item("render.nearest_stop", r.StopName, r.StopCode, r.StopDistanceMeters)
+	if len(r.CrossStreets) > 0 {
+		w.item("render.cross_streets", strings.Join(r.CrossStreets, ", "))
+	}
+}
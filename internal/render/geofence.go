@@ -0,0 +1,49 @@
+package render
+
+import "github.com/thunderjr/sptrans-mcp/internal/types"
+
+// renderGeofence renders a watched area
+func renderGeofence(w *writer, f types.GeofenceResponse) {
+	area := w.t("render.geofence_polygon", len(f.Polygon))
+	if f.Center != nil {
+		area = w.t("render.geofence_circle", f.RadiusMeters, f.Center.Latitude, f.Center.Longitude)
+	}
+	w.item("render.geofence", f.ID, f.Name, area, f.VehiclesInside)
+	if w.compact() {
+		return
+	}
+	switch {
+	case f.VehicleID != 0:
+		w.line("render.geofence_vehicle", f.VehicleID)
+	case f.LinePrefix != "":
+		w.line("render.geofence_lines", f.LinePrefix)
+	case f.LineCode != 0:
+		w.line("render.geofence_line", f.LineCode)
+	}
+	if f.Webhook != "" {
+		w.line("render.geofence_webhook", f.Webhook)
+	}
+}
+
+// renderGeofences renders the response of list_geofences
+func renderGeofences(w *writer, r types.ListGeofencesResponse) {
+	if !w.compact() {
+		w.heading("render.geofences", r.TotalFences, r.IntervalSeconds)
+		if r.LastError != "" {
+			w.line("render.geofence_error", r.LastError)
+		}
+	}
+	for _, f := range r.Fences {
+		renderGeofence(w, f)
+	}
+}
+
+// renderGeofenceEvents renders the response of get_geofence_events
+func renderGeofenceEvents(w *writer, r types.GeofenceEventsResponse) {
+	if !w.compact() {
+		w.heading("render.geofence_events", r.TotalEvents)
+	}
+	for _, event := range r.Events {
+		w.item("render.geofence_"+event.Event, event.LineIdentifier, event.VehicleID, event.FenceName, event.Time)
+	}
+}
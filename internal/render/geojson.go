@@ -0,0 +1,160 @@
+package render
+
+import (
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geojson"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Feature kinds, set as the "kind" property of every feature
+const (
+	stopFeature      = "stop"
+	vehicleFeature   = "vehicle"
+	itineraryFeature = "itinerary"
+	isochroneFeature = "isochrone"
+)
+
+// toGeoJSON converts a response carrying coordinates to a feature collection,
+// reporting false for responses without a GeoJSON representation
+func toGeoJSON(response any) (*geojson.FeatureCollection, bool) {
+	fc := geojson.NewFeatureCollection()
+	switch r := response.(type) {
+	case types.SearchStopsResponse:
+		addStops(fc, r.Stops, nil)
+	case types.GetStopsByLineResponse:
+		addItinerary(fc, r.LineCode, r.Stops)
+		addStops(fc, r.Stops, map[string]any{"line_code": r.LineCode})
+	case types.GetVehiclePositionsResponse:
+		if r.Positions != nil {
+			addLinesWithVehicles(fc, r.Positions.Lines)
+		}
+	case types.GetVehiclePositionsByLineResponse:
+		addLinesWithVehicles(fc, r.Positions.Lines)
+	case types.IsochroneResponse:
+		addIsochrone(fc, r)
+	case types.GetArrivalPredictionsResponse:
+		addStopPredictions(fc, r.Timestamp, r.Predictions.Stop)
+	case types.GetArrivalPredictionsByLineResponse:
+		for _, stop := range r.Predictions.Stops {
+			addStopPredictions(fc, r.Timestamp, stop)
+		}
+	case types.GetArrivalPredictionsByStopResponse:
+		for _, stop := range r.Predictions.Stops {
+			addStopPredictions(fc, r.Timestamp, stop)
+		}
+	default:
+		return nil, false
+	}
+	return fc, true
+}
+
+// addStops adds a point for each stop, numbered in order, with the extra properties
+func addStops(fc *geojson.FeatureCollection, stops []types.StopResponse, extra map[string]any) {
+	for i, stop := range stops {
+		properties := map[string]any{
+			"kind":     stopFeature,
+			"code":     stop.Code,
+			"name":     stop.Name,
+			"address":  stop.Address,
+			"sequence": i + 1,
+		}
+		for key, value := range extra {
+			properties[key] = value
+		}
+		fc.Add(geojson.Point(stop.Latitude, stop.Longitude), properties)
+	}
+}
+
+// addItinerary adds the line through the ordered stops of a line
+func addItinerary(fc *geojson.FeatureCollection, lineCode int, stops []types.StopResponse) {
+	if len(stops) < 2 {
+		return
+	}
+	positions := make([]geojson.Position, len(stops))
+	for i, stop := range stops {
+		positions[i] = geojson.At(stop.Latitude, stop.Longitude)
+	}
+	fc.Add(geojson.LineString(positions), map[string]any{
+		"kind":      itineraryFeature,
+		"line_code": lineCode,
+		"stops":     len(stops),
+	})
+}
+
+// addLinesWithVehicles adds a point for each vehicle, with its line
+func addLinesWithVehicles(fc *geojson.FeatureCollection, lines []types.LineWithVehiclesResponse) {
+	for _, line := range lines {
+		for _, vehicle := range line.Vehicles {
+			fc.Add(geojson.Point(vehicle.Latitude, vehicle.Longitude), map[string]any{
+				"kind":            vehicleFeature,
+				"vehicle_id":      vehicle.ID,
+				"accessible":      vehicle.Accessible,
+				"line_code":       line.Code,
+				"line_identifier": line.Identifier,
+				"direction":       line.Direction,
+				"destination":     headsign(line.Direction, line.Origin, line.Destination),
+				"last_update":     vehicle.LastUpdate.Format(time.RFC3339),
+			})
+		}
+	}
+}
+
+// addStopPredictions adds a point for the stop and one for each predicted
+// vehicle, carrying its arrival time and minutes until arrival
+func addStopPredictions(fc *geojson.FeatureCollection, timestamp string, stop types.StopWithPredictionsResponse) {
+	fc.Add(geojson.Point(stop.Latitude, stop.Longitude), map[string]any{
+		"kind": stopFeature,
+		"code": stop.Code,
+		"name": stop.Name,
+	})
+	for _, line := range stop.Lines {
+		for _, prediction := range line.Predictions {
+			properties := map[string]any{
+				"kind":            vehicleFeature,
+				"vehicle_id":      prediction.VehicleID,
+				"accessible":      prediction.Accessible,
+				"line_code":       line.Code,
+				"line_identifier": line.Identifier,
+				"direction":       line.Direction,
+				"destination":     headsign(line.Direction, line.Origin, line.Destination),
+				"stop_code":       stop.Code,
+				"arrival_time":    prediction.ArrivalTime,
+			}
+			if minutes, ok := types.MinutesUntil(timestamp, prediction.ArrivalTime); ok {
+				properties["eta_minutes"] = minutes
+			}
+			fc.Add(geojson.Point(prediction.Latitude, prediction.Longitude), properties)
+		}
+	}
+}
+
+// addIsochrone adds the outline of each time budget, largest first so that
+// smaller areas are drawn on top, and a point for each stop reached
+func addIsochrone(fc *geojson.FeatureCollection, r types.IsochroneResponse) {
+	for i := len(r.Budgets) - 1; i >= 0; i-- {
+		budget := r.Budgets[i]
+		if len(budget.Polygon) == 0 {
+			continue
+		}
+		ring := make([]geojson.Position, len(budget.Polygon))
+		for j, c := range budget.Polygon {
+			ring[j] = geojson.At(c.Latitude, c.Longitude)
+		}
+		fc.Add(geojson.Polygon([][]geojson.Position{ring}), map[string]any{
+			"kind":    isochroneFeature,
+			"minutes": budget.Minutes,
+			"stops":   budget.Stops,
+		})
+	}
+	for _, stop := range r.Stops {
+		fc.Add(geojson.Point(stop.Latitude, stop.Longitude), map[string]any{
+			"kind":           stopFeature,
+			"code":           stop.Code,
+			"name":           stop.Name,
+			"minutes":        stop.Minutes,
+			"within_minutes": stop.WithinMinutes,
+			"rides":          stop.Rides,
+		})
+	}
+}
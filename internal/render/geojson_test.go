@@ -0,0 +1,115 @@
+package render
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geojson"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// renderGeoJSON renders a response as GeoJSON and decodes the feature collection
+func renderGeoJSON(t *testing.T, response any) geojson.FeatureCollection {
+	t.Helper()
+	text, err := Render(response, GeoJSON, i18n.English)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	var fc geojson.FeatureCollection
+	if err := json.Unmarshal([]byte(text), &fc); err != nil {
+		t.Fatalf("decoding %s: %v", text, err)
+	}
+	if fc.Type != "FeatureCollection" {
+		t.Fatalf("type = %q, want FeatureCollection", fc.Type)
+	}
+	return fc
+}
+
+func TestGeoJSONStopsByLine(t *testing.T) {
+	fc := renderGeoJSON(t, types.GetStopsByLineResponse{
+		TotalResults: 2,
+		LineCode:     1273,
+		Stops: []types.StopResponse{
+			{Code: 10, Name: "A", Latitude: -23.5, Longitude: -46.6},
+			{Code: 20, Name: "B", Latitude: -23.6, Longitude: -46.7},
+		},
+	})
+	if len(fc.Features) != 3 {
+		t.Fatalf("got %d features, want the itinerary and 2 stops", len(fc.Features))
+	}
+
+	itinerary := fc.Features[0]
+	if itinerary.Geometry.Type != "LineString" || itinerary.Properties["kind"] != itineraryFeature {
+		t.Errorf("first feature = %s %v, want the itinerary line", itinerary.Geometry.Type, itinerary.Properties["kind"])
+	}
+	coordinates, _ := itinerary.Geometry.Coordinates.([]any)
+	if len(coordinates) != 2 {
+		t.Fatalf("itinerary has %d positions, want 2", len(coordinates))
+	}
+	if first := coordinates[0].([]any); first[0] != -46.6 || first[1] != -23.5 {
+		t.Errorf("first position = %v, want longitude first", first)
+	}
+
+	for i, feature := range fc.Features[1:] {
+		if feature.Geometry.Type != "Point" || feature.Properties["kind"] != stopFeature {
+			t.Errorf("feature %d = %s %v, want a stop point", i+1, feature.Geometry.Type, feature.Properties["kind"])
+		}
+		if feature.Properties["sequence"] != float64(i+1) || feature.Properties["line_code"] != float64(1273) {
+			t.Errorf("stop %d properties = %v", i+1, feature.Properties)
+		}
+	}
+}
+
+func TestGeoJSONPredictions(t *testing.T) {
+	fc := renderGeoJSON(t, types.GetArrivalPredictionsResponse{
+		Timestamp: "14:00",
+		Predictions: types.ArrivalPredictionResponse{
+			Timestamp: "14:00",
+			Stop: types.StopWithPredictionsResponse{
+				Code: 10,
+				Name: "Paulista",
+				Lines: []types.LineWithPredictionsResponse{{
+					Code:        1273,
+					Direction:   2,
+					Origin:      "Terminal A",
+					Destination: "Terminal B",
+					Predictions: []types.PredictionResponse{{VehicleID: "11000", ArrivalTime: "14:07", Accessible: true}},
+				}},
+			},
+		},
+	})
+	if len(fc.Features) != 2 {
+		t.Fatalf("got %d features, want the stop and 1 vehicle", len(fc.Features))
+	}
+	vehicle := fc.Features[1].Properties
+	if vehicle["kind"] != vehicleFeature || vehicle["stop_code"] != float64(10) {
+		t.Errorf("vehicle properties = %v", vehicle)
+	}
+	if vehicle["eta_minutes"] != float64(7) {
+		t.Errorf("eta_minutes = %v, want 7", vehicle["eta_minutes"])
+	}
+	if vehicle["destination"] != "Terminal A" {
+		t.Errorf("destination = %v, want the origin terminal for direction 2", vehicle["destination"])
+	}
+}
+
+func TestGeoJSONIsochrone(t *testing.T) {
+	square := []types.Coordinate{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 1}, {Latitude: 1, Longitude: 1}, {Latitude: 0, Longitude: 0}}
+	fc := renderGeoJSON(t, types.IsochroneResponse{
+		Budgets: []types.IsochroneBudgetResponse{
+			{Minutes: 10, Stops: 1, Polygon: square},
+			{Minutes: 20, Stops: 3},
+			{Minutes: 30, Stops: 5, Polygon: square},
+		},
+	})
+	var minutes []any
+	for _, feature := range fc.Features {
+		if feature.Properties["kind"] == isochroneFeature {
+			minutes = append(minutes, feature.Properties["minutes"])
+		}
+	}
+	if len(minutes) != 2 || minutes[0] != float64(30) || minutes[1] != float64(10) {
+		t.Errorf("isochrone outlines = %v, want 30 then 10 minutes", minutes)
+	}
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/direction"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// renderSearchLines renders the response of search_lines and search_line_by_direction
+func renderSearchLines(w *writer, r types.SearchLinesResponse) {
+	if !w.compact() {
+		w.heading("render.lines_found", r.TotalResults, r.SearchTerm)
+	}
+	for _, line := range r.Lines {
+		item := w.t("render.line", lineSign(line.Number, line.Type), line.Code,
+			terminalFrom(line.Direction, line.Origin, line.Destination),
+			headsign(line.Direction, line.Origin, line.Destination))
+		if line.IsCircular {
+			item += w.t("render.circular")
+		}
+		w.itemf("%s", item)
+	}
+}
+
+// lineSign returns the sign shown on buses, e.g. 8000-10
+func lineSign(number string, lineType int) string {
+	return fmt.Sprintf("%s-%d", number, lineType)
+}
+
+// terminalFrom returns the terminal a line departs from in the given direction
+func terminalFrom(direction int, origin, destination string) string {
+	if direction == 2 {
+		return destination
+	}
+	return origin
+}
+
+// renderResolveDirection renders the response of resolve_direction
+func renderResolveDirection(w *writer, r types.ResolveDirectionResponse) {
+	if r.Resolved != nil {
+		w.heading("render.resolved", lineSign(r.Resolved.Number, r.Resolved.Type), r.Resolved.Code, r.Resolved.Direction, r.Towards)
+	} else {
+		w.heading("render.unresolved", r.Towards)
+	}
+	if w.compact() {
+		return
+	}
+	for _, d := range r.Directions {
+		item := w.t("render.direction_match", lineSign(d.Line.Number, d.Line.Type), d.Line.Code, d.Line.Direction,
+			strings.Join(d.Terminals, " / "), d.Score)
+		switch d.MatchedBy {
+		case direction.ByTerminal:
+			item += w.t("render.direction_terminal", d.Matched)
+		case direction.ByStop:
+			item += w.t("render.direction_stop", d.Matched)
+		}
+		if d.Line.IsCircular {
+			item += w.t("render.circular")
+		}
+		w.itemf("%s", item)
+	}
+}
@@ -0,0 +1,119 @@
+package render
+
+import "github.com/thunderjr/sptrans-mcp/internal/types"
+
+// renderVehiclePositions renders the response of get_vehicle_positions
+func renderVehiclePositions(w *writer, r types.GetVehiclePositionsResponse) {
+	if !w.compact() {
+		w.heading("render.vehicles_on_lines", r.TotalVehicles, r.TotalLines, r.Timestamp)
+	}
+	for _, line := range r.Summary {
+		w.item("render.line_counts", line.Identifier,
+			headsign(line.Direction, line.Origin, line.Destination), line.VehicleCount, line.AccessibleCount)
+	}
+	if r.Positions != nil {
+		renderLinesWithVehicles(w, r.Positions.Lines)
+	}
+	if r.NextCursor != "" {
+		w.line("render.more_results", r.NextCursor)
+	}
+}
+
+// renderVehiclePositionsByLine renders the response of get_vehicle_positions_by_line
+func renderVehiclePositionsByLine(w *writer, r types.GetVehiclePositionsByLineResponse) {
+	if !w.compact() {
+		w.heading("render.vehicles_on_line", r.TotalVehicles, r.LineCode, r.Timestamp)
+	}
+	renderLinesWithVehicles(w, r.Positions.Lines)
+}
+
+// renderVehiclesNear renders the response of find_vehicles_near
+func renderVehiclesNear(w *writer, r types.FindVehiclesNearResponse) {
+	if !w.compact() {
+		w.heading("render.vehicles_near", r.TotalResults, r.RadiusMeters, r.Latitude, r.Longitude, r.Timestamp)
+		if r.Place != "" {
+			w.line("render.geocoded_place", r.Place)
+		}
+	}
+	for _, vehicle := range r.Vehicles {
+		w.itemf("%s%s", w.t("render.nearby_vehicle", vehicle.LineIdentifier,
+			headsign(vehicle.Direction, vehicle.Origin, vehicle.Destination), vehicle.ID,
+			vehicle.DistanceMeters, vehicle.Compass, vehicle.AgeSeconds), w.accessible(vehicle.Accessible))
+	}
+}
+
+// renderVehicleProgress renders the response of get_vehicle_progress
+func renderVehicleProgress(w *writer, r types.GetVehicleProgressResponse) {
+	if !w.compact() {
+		if r.StopCode != 0 {
+			w.heading("render.vehicle_progress_to", r.TotalVehicles, r.LineCode, r.StopName, r.StopSequence, r.TotalStops, r.Timestamp)
+		} else {
+			w.heading("render.vehicle_progress", r.TotalVehicles, r.LineCode, r.TotalStops,
+				float64(r.RouteLengthMeters)/1000, r.ShapeSource, r.Timestamp)
+		}
+	}
+	for _, vehicle := range r.Vehicles {
+		previous, next := vehicle.PreviousStopName, vehicle.NextStopName
+		if vehicle.PreviousStopCode == 0 {
+			previous = w.t("render.route_start")
+		}
+		if vehicle.NextStopCode == 0 {
+			next = w.t("render.route_end")
+		}
+		text := w.t("render.progress_between", vehicle.ID, previous, next, float64(vehicle.TravelledMeters)/1000)
+		switch {
+		case vehicle.Passed:
+			text += w.t("render.progress_passed")
+		case vehicle.StopsAway != nil:
+			text += w.t("render.progress_remaining", *vehicle.StopsAway, *vehicle.RemainingMeters)
+		}
+		if vehicle.OffRoute {
+			text += w.t("render.progress_off_route", vehicle.OffsetMeters)
+		}
+		w.itemf("%s%s", text, w.accessible(vehicle.Accessible))
+	}
+}
+
+// renderLinesWithVehicles renders vehicles grouped by line
+func renderLinesWithVehicles(w *writer, lines []types.LineWithVehiclesResponse) {
+	for _, line := range lines {
+		if !w.compact() {
+			w.line("")
+			w.heading("render.line_vehicles", line.Identifier,
+				headsign(line.Direction, line.Origin, line.Destination), line.VehicleCount)
+		}
+		for _, vehicle := range line.Vehicles {
+			if w.compact() {
+				w.item("render.vehicle_compact", line.Identifier, vehicle.ID, vehicle.Latitude, vehicle.Longitude, w.accessible(vehicle.Accessible))
+				continue
+			}
+			w.itemf("%s%s", w.t("render.vehicle", vehicle.ID, vehicle.Latitude, vehicle.Longitude), w.accessible(vehicle.Accessible))
+		}
+	}
+}
+
+// renderAccessibleFleet renders the response of get_accessible_fleet
+func renderAccessibleFleet(w *writer, r types.GetAccessibleFleetResponse) {
+	if r.Vehicles == 0 {
+		w.line("render.fleet_none", r.Timestamp)
+		return
+	}
+	if !w.compact() {
+		w.heading("render.fleet", r.Accessible, r.Vehicles, r.Percent, r.TotalLines, r.Timestamp)
+	}
+	for _, line := range r.Lines {
+		if w.compact() {
+			w.item("render.fleet_compact", line.Line, line.Percent)
+			continue
+		}
+		w.item("render.fleet_line", line.Line, line.Accessible, line.Vehicles, line.Percent)
+	}
+}
+
+// accessible returns a localized marker for accessible vehicles
+func (w *writer) accessible(isAccessible bool) string {
+	if isAccessible {
+		return w.t("render.accessible")
+	}
+	return ""
+}
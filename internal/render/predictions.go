@@ -0,0 +1,142 @@
+package render
+
+import "github.com/thunderjr/sptrans-mcp/internal/types"
+
+// renderArrivalPredictions renders the response of get_arrival_predictions
+func renderArrivalPredictions(w *writer, r types.GetArrivalPredictionsResponse) {
+	renderStopPredictions(w, r.Timestamp, r.Predictions.Stop)
+}
+
+// renderArrivalPredictionsByLine renders the response of get_arrival_predictions_by_line
+func renderArrivalPredictionsByLine(w *writer, r types.GetArrivalPredictionsByLineResponse) {
+	if !w.compact() {
+		w.heading("render.predictions_for_line", r.TotalPredictions, r.LineCode, r.TotalStops, r.Timestamp)
+	}
+	for _, stop := range r.Predictions.Stops {
+		renderStopPredictions(w, r.Timestamp, stop)
+	}
+}
+
+// renderArrivalPredictionsByStop renders the response of get_arrival_predictions_by_stop
+func renderArrivalPredictionsByStop(w *writer, r types.GetArrivalPredictionsByStopResponse) {
+	for _, stop := range r.Predictions.Stops {
+		renderStopPredictions(w, r.Timestamp, stop)
+	}
+	if len(r.Predictions.Stops) == 0 {
+		w.line("render.no_predictions", r.StopCode, r.Timestamp)
+	}
+}
+
+// renderStopPredictions renders the predictions of every line at a stop
+func renderStopPredictions(w *writer, timestamp string, stop types.StopWithPredictionsResponse) {
+	if !w.compact() {
+		w.line("")
+		w.heading("render.stop_heading", stop.Name, stop.Code, timestamp)
+	}
+	for _, line := range stop.Lines {
+		for _, prediction := range line.Predictions {
+			arrival := w.t("render.arrives", prediction.ArrivalTime)
+			if minutes, ok := types.MinutesUntil(timestamp, prediction.ArrivalTime); ok {
+				arrival += w.t("render.in_minutes", minutes, w.accessibleSuffix(prediction.Accessible))
+			}
+			if w.compact() {
+				w.item("render.stop_compact", stop.Name, line.Identifier, arrival)
+				continue
+			}
+			w.item("render.stop_arrival", line.Identifier, headsign(line.Direction, line.Origin, line.Destination), arrival)
+		}
+	}
+}
+
+// accessibleSuffix returns a localized, comma-separated marker for accessible vehicles
+func (w *writer) accessibleSuffix(isAccessible bool) string {
+	if isAccessible {
+		return w.t("render.accessible_suffix")
+	}
+	return ""
+}
+
+// renderDepartureBoard renders the response of departure_board
+func renderDepartureBoard(w *writer, r types.DepartureBoardResponse) {
+	if len(r.Departures) == 0 {
+		w.line("render.no_predictions", r.StopCode, r.Timestamp)
+		return
+	}
+	if !w.compact() {
+		w.heading("render.departure_board", r.StopName, r.StopCode, r.Timestamp)
+	}
+	for _, d := range r.Departures {
+		if w.compact() {
+			w.item("render.departure_compact", d.Line, d.MinutesUntil)
+			continue
+		}
+		departure := w.t("render.departure", d.Line, d.Destination, d.MinutesUntil, d.ArrivalTime, w.accessibleSuffix(d.Accessible))
+		if d.DistanceMeters != nil {
+			departure += w.t("render.departure_distance", float64(*d.DistanceMeters)/1000)
+		}
+		w.itemf("%s", departure)
+	}
+	if w.compact() || r.AccessibleOnly || len(r.NextAccessible) == 0 {
+		return
+	}
+	w.line("")
+	for _, wait := range r.NextAccessible {
+		if wait.MinutesUntil == nil {
+			w.item("render.no_accessible", wait.Line, wait.Destination)
+			continue
+		}
+		w.item("render.next_accessible", wait.Line, wait.Destination, *wait.MinutesUntil, wait.ArrivalTime)
+	}
+}
+
+// renderPredictionsBatch renders the response of get_predictions_batch
+func renderPredictionsBatch(w *writer, r types.GetPredictionsBatchResponse) {
+	if !w.compact() {
+		w.heading("render.batch", r.Succeeded, r.TotalQueries, r.StopsFetched)
+	}
+	for _, item := range r.Items {
+		query := item.Stop
+		if item.Line != "" {
+			query += " / " + item.Line
+		}
+		if item.Error != nil {
+			w.item("render.batch_failed", item.Index+1, query, item.Error.Message)
+			for _, c := range item.Error.Candidates {
+				w.item("render.candidate", c.Code, c.Label, c.Description)
+			}
+			continue
+		}
+		if w.compact() {
+			w.line("render.batch_query", item.Index+1, query)
+		}
+		renderDepartureBoard(w, *item.Board)
+	}
+}
+
+// renderLeaveAdvice renders the response of leave_advice
+func renderLeaveAdvice(w *writer, r types.LeaveAdviceResponse) {
+	origin := r.Origin.Name
+	if origin == "" {
+		origin = w.t("render.here")
+	}
+	if !w.compact() {
+		w.heading("render.leave", r.WalkMeters, r.WalkMinutes, origin, r.StopName, r.StopCode, r.Now, r.MarginMinutes)
+	}
+	if r.Catch == nil {
+		w.line("render.leave_none")
+	} else if !w.compact() {
+		c := r.Catch
+		w.line("render.leave_by", c.LeaveBy, c.LeaveInMinutes, c.Line, c.Destination, c.ArrivalTime, w.t("render.confidence_"+c.Confidence))
+		w.line("")
+	}
+	for _, o := range r.Options {
+		if w.compact() {
+			w.item("render.leave_compact", o.Line, o.LeaveBy, w.t("render.confidence_"+o.Confidence))
+			continue
+		}
+		w.itemf("%s", w.t("render.leave_option", o.Line, o.Destination, o.ArrivalTime, o.LeaveBy, o.LeaveInMinutes, o.PositionAgeSeconds)+w.accessibleSuffix(o.Accessible))
+	}
+	if r.Missed > 0 && !w.compact() {
+		w.line("render.leave_missed", r.Missed)
+	}
+}
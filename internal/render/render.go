@@ -0,0 +1,217 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Format selects how a tool response is rendered as text content
+type Format string
+
+const (
+	JSON     Format = "json"     // Raw JSON of the response
+	Markdown Format = "markdown" // Markdown with headings and bullet lists
+	Compact  Format = "compact"  // Plain text, one short line per item
+	GeoJSON  Format = "geojson"  // GeoJSON feature collection of the located items
+)
+
+// Formats lists the supported formats
+var Formats = []Format{JSON, Markdown, Compact, GeoJSON}
+
+// ParseFormat parses a format name, returning fallback for an empty name
+func ParseFormat(name string, fallback Format) (Format, error) {
+	if name == "" {
+		return fallback, nil
+	}
+	for _, format := range Formats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q, expected one of %s", name, FormatList())
+}
+
+// FormatList lists the supported format names, e.g. "json, markdown or compact"
+func FormatList() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	last := len(names) - 1
+	if last < 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:last], ", ") + " or " + names[last]
+}
+
+// Render renders a tool response in the given format and locale. Responses
+// without a text or GeoJSON rendering fall back to JSON.
+func Render(response any, format Format, locale i18n.Locale) (string, error) {
+	if format == GeoJSON {
+		if fc, ok := toGeoJSON(response); ok {
+			return renderJSON(fc)
+		}
+	}
+	if format == JSON || format == GeoJSON {
+		return renderJSON(response)
+	}
+
+	w := &writer{format: format, locale: locale}
+	switch r := response.(type) {
+	case types.SearchLinesResponse:
+		renderSearchLines(w, r)
+	case types.ResolveDirectionResponse:
+		renderResolveDirection(w, r)
+	case types.SearchStopsResponse:
+		renderSearchStops(w, r)
+	case types.GetStopsByLineResponse:
+		renderStopsByLine(w, r)
+	case types.GetStopsByCorridorResponse:
+		renderStopsByCorridor(w, r)
+	case types.FindStopsNearResponse:
+		renderStopsNear(w, r)
+	case types.WalkingDistanceResponse:
+		renderWalkingDistance(w, r)
+	case types.NearbyTransfersResponse:
+		renderNearbyTransfers(w, r)
+	case types.FindTransferPointsResponse:
+		renderTransferPoints(w, r)
+	case types.PlanTripResponse:
+		renderPlanTrip(w, r)
+	case types.IsochroneResponse:
+		renderIsochrone(w, r)
+	case types.FareResponse:
+		renderFare(w, r)
+	case types.GetVehiclePositionsResponse:
+		renderVehiclePositions(w, r)
+	case types.GetVehiclePositionsByLineResponse:
+		renderVehiclePositionsByLine(w, r)
+	case types.FindVehiclesNearResponse:
+		renderVehiclesNear(w, r)
+	case types.GetAccessibleFleetResponse:
+		renderAccessibleFleet(w, r)
+	case types.QueryViewportResponse:
+		renderViewport(w, r)
+	case types.GetVehicleProgressResponse:
+		renderVehicleProgress(w, r)
+	case types.GetArrivalPredictionsResponse:
+		renderArrivalPredictions(w, r)
+	case types.GetArrivalPredictionsByLineResponse:
+		renderArrivalPredictionsByLine(w, r)
+	case types.GetArrivalPredictionsByStopResponse:
+		renderArrivalPredictionsByStop(w, r)
+	case types.DepartureBoardResponse:
+		renderDepartureBoard(w, r)
+	case types.GetPredictionsBatchResponse:
+		renderPredictionsBatch(w, r)
+	case types.LeaveAdviceResponse:
+		renderLeaveAdvice(w, r)
+	case types.RenderMapResponse:
+		w.line("render.map_summary", r.Title, r.Stops, r.Vehicles, r.AccessibleVehicles, r.ScaleMeters)
+	case types.CatalogStatusResponse:
+		renderCatalogStatus(w, r)
+	case types.GeofenceResponse:
+		renderGeofence(w, r)
+	case types.ListGeofencesResponse:
+		renderGeofences(w, r)
+	case types.DeleteGeofenceResponse:
+		w.line("render.geofence_deleted", r.ID)
+	case types.GeofenceEventsResponse:
+		renderGeofenceEvents(w, r)
+	case types.GeocodeAddressResponse:
+		renderGeocode(w, r)
+	case types.ReverseGeocodeResponse:
+		renderReverseGeocode(w, r)
+	case types.PreferencesResponse:
+		w.line("render.preferences", r.Locale)
+		if r.AccessibleOnly {
+			w.line("render.preferences_access")
+		}
+		for _, place := range r.Places {
+			w.line("render.preferences_place", place.Name, place.Label)
+		}
+	case types.GetServerMetricsResponse:
+		for _, tool := range r.Tools {
+			w.item("render.tool_metrics", tool.Tool, tool.Calls, tool.Errors, tool.AverageMillis, tool.MaxMillis)
+		}
+	default:
+		return renderJSON(response)
+	}
+	return strings.Trim(w.String(), "\n"), nil
+}
+
+// renderJSON marshals the response as JSON
+func renderJSON(response any) (string, error) {
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return "", err
+	}
+	return string(responseJSON), nil
+}
+
+// writer accumulates rendered text, applying the markup of its format and
+// the messages of its locale
+type writer struct {
+	strings.Builder
+	format Format
+	locale i18n.Locale
+}
+
+// t returns the localized message for key
+func (w *writer) t(key string, args ...any) string {
+	return i18n.T(w.locale, key, args...)
+}
+
+// heading writes the localized message for key as a section heading
+func (w *writer) heading(key string, args ...any) {
+	if w.format == Markdown {
+		w.WriteString("**" + w.t(key, args...) + "**\n")
+		return
+	}
+	w.WriteString(w.t(key, args...) + "\n")
+}
+
+// item writes the localized message for key as a list item
+func (w *writer) item(key string, args ...any) {
+	w.itemf("%s", w.t(key, args...))
+}
+
+// itemf writes a list item from text that is already localized, formatting
+// args into it without looking it up in the catalog
+func (w *writer) itemf(format string, args ...any) {
+	if w.format == Markdown {
+		w.WriteString("- " + fmt.Sprintf(format, args...) + "\n")
+		return
+	}
+	w.WriteString(fmt.Sprintf(format, args...) + "\n")
+}
+
+// line writes the localized message for key as a plain line, or an empty line for an empty key
+func (w *writer) line(key string, args ...any) {
+	if key != "" {
+		w.WriteString(w.t(key, args...))
+	}
+	w.WriteString("\n")
+}
+
+// linef writes a plain line from text that is already localized
+func (w *writer) linef(format string, args ...any) {
+	w.WriteString(fmt.Sprintf(format, args...) + "\n")
+}
+
+// compact reports whether the writer renders the compact format
+func (w *writer) compact() bool {
+	return w.format == Compact
+}
+
+// headsign returns the terminal a line is heading to in the given direction
+func headsign(direction int, origin, destination string) string {
+	if direction == 2 {
+		return origin
+	}
+	return destination
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{"", Compact, false},
+		{"json", JSON, false},
+		{"Markdown", Markdown, false},
+		{"GEOJSON", GeoJSON, false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name, Compact)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatList(t *testing.T) {
+	if got, want := FormatList(), "json, markdown, compact or geojson"; got != want {
+		t.Errorf("FormatList() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderStops(t *testing.T) {
+	response := types.SearchStopsResponse{
+		TotalResults: 2,
+		SearchTerm:   "paulista",
+		Stops: []types.StopResponse{
+			{Code: 1, Name: "Paulista", Address: "Av. Paulista, 1000"},
+			{Code: 2, Name: "Consolação"},
+		},
+	}
+	tests := []struct {
+		format Format
+		locale i18n.Locale
+		want   string
+	}{
+		{Markdown, i18n.English, "**2 stops found for \"paulista\"**\n" +
+			"- Paulista (code 1) — Av. Paulista, 1000\n" +
+			"- Consolação (code 2)"},
+		{Markdown, i18n.Portuguese, "**2 paradas encontradas para \"paulista\"**\n" +
+			"- Paulista (código 1) — Av. Paulista, 1000\n" +
+			"- Consolação (código 2)"},
+		{Compact, i18n.English, "Paulista (code 1)\nConsolação (code 2)"},
+	}
+	for _, tt := range tests {
+		got, err := Render(response, tt.format, tt.locale)
+		if err != nil {
+			t.Fatalf("Render(%s, %s): %v", tt.format, tt.locale, err)
+		}
+		if got != tt.want {
+			t.Errorf("Render(%s, %s) =\n%s\nwant\n%s", tt.format, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestRenderFallsBackToJSON(t *testing.T) {
+	response := types.SearchLinesResponse{SearchTerm: "8000"}
+	for _, format := range []Format{JSON, GeoJSON} {
+		got, err := Render(response, format, i18n.English)
+		if err != nil {
+			t.Fatalf("Render(%s): %v", format, err)
+		}
+		if !strings.HasPrefix(got, "{") || !strings.Contains(got, `"8000"`) {
+			t.Errorf("Render(%s) = %s, want the response JSON", format, got)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	w := &writer{format: Markdown, locale: i18n.English}
+	w.heading("render.preferences", "en")
+	w.item("render.tool_metrics", "search_lines", 3, 1, int64(20), int64(45))
+	w.itemf("%s: %d", "already localized", 5)
+	w.line("")
+	w.linef("%d%%", 50)
+	want := "**Session preferences: locale en**\n" +
+		"- search_lines: 3 calls, 1 errors, average 20 ms, max 45 ms\n" +
+		"- already localized: 5\n" +
+		"\n" +
+		"50%\n"
+	if got := w.String(); got != want {
+		t.Errorf("markdown writer =\n%q\nwant\n%q", got, want)
+	}
+
+	w = &writer{format: Compact, locale: i18n.Portuguese}
+	w.heading("render.preferences", "pt-BR")
+	w.itemf("%s", "plain")
+	if !w.compact() {
+		t.Error("compact writer does not report compact")
+	}
+	if got, want := w.String(), "Preferências da sessão: idioma pt-BR\nplain\n"; got != want {
+		t.Errorf("compact writer = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package render
+
+import "github.com/thunderjr/sptrans-mcp/internal/types"
+
+// renderSearchStops renders the response of search_stops
+func renderSearchStops(w *writer, r types.SearchStopsResponse) {
+	if !w.compact() {
+		w.heading("render.stops_found", r.TotalResults, r.SearchTerm)
+	}
+	renderStops(w, r.Stops)
+}
+
+// renderStopsByLine renders the response of get_stops_by_line
+func renderStopsByLine(w *writer, r types.GetStopsByLineResponse) {
+	if !w.compact() {
+		w.heading("render.stops_on_line", r.TotalResults, r.LineCode)
+	}
+	renderStops(w, r.Stops)
+}
+
+// renderStopsByCorridor renders the response of get_stops_by_corridor
+func renderStopsByCorridor(w *writer, r types.GetStopsByCorridorResponse) {
+	if !w.compact() {
+		w.heading("render.stops_in_corridor", r.TotalResults, r.CorridorCode)
+	}
+	renderStops(w, r.Stops)
+}
+
+// renderStops renders a list of stops
+func renderStops(w *writer, stops []types.StopResponse) {
+	for _, stop := range stops {
+		if w.compact() || stop.Address == "" {
+			w.item("render.stop_short", stop.Name, stop.Code)
+			continue
+		}
+		w.item("render.stop", stop.Name, stop.Code, stop.Address)
+	}
+}
+
+// renderStopsNear renders the response of find_stops_near
+func renderStopsNear(w *writer, r types.FindStopsNearResponse) {
+	if r.CatalogSize == 0 {
+		w.line("render.empty_catalog")
+		return
+	}
+	if !w.compact() {
+		w.heading("render.stops_near", r.TotalResults, r.RadiusMeters, r.Latitude, r.Longitude)
+		if r.Place != "" {
+			w.line("render.geocoded_place", r.Place)
+		}
+	}
+	for _, stop := range r.Stops {
+		w.item("render.nearby_stop", stop.Name, stop.Code, stop.WalkingMeters, stop.WalkingMinutes)
+	}
+}
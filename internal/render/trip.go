@@ -0,0 +1,78 @@
+package render
+
+import "github.com/thunderjr/sptrans-mcp/internal/types"
+
+// renderPlanTrip renders the response of plan_trip
+func renderPlanTrip(w *writer, r types.PlanTripResponse) {
+	if r.NetworkLines == 0 {
+		w.line("render.empty_catalog")
+		return
+	}
+	if !w.compact() {
+		w.heading("render.trip", walkEndpoint(w, r.From), walkEndpoint(w, r.To), r.Departure)
+		if r.AccessibleOnly {
+			w.line("render.trip_accessible")
+		}
+	}
+	if r.NoAccessibleBus > 0 {
+		w.line("render.trip_no_accessible", r.NoAccessibleBus)
+	}
+	if len(r.Options) == 0 {
+		if r.NoAccessibleBus == 0 {
+			w.line("render.trip_none")
+		}
+		return
+	}
+	for _, option := range r.Options {
+		w.itemf("%s", w.t("render.trip_option", option.Rank, option.Arrival, option.DurationMinutes, option.Transfers, option.WalkingMeters)+tripFare(w, option.Fare))
+		if w.compact() {
+			continue
+		}
+		for _, leg := range option.Legs {
+			if leg.Line == nil {
+				w.line("render.trip_walk", leg.Departure, leg.Meters, walkEndpoint(w, leg.To))
+				continue
+			}
+			wait := w.t("render.trip_estimated")
+			if leg.LiveWait {
+				wait = w.t("render.trip_live")
+			}
+			w.line("render.trip_ride", leg.Departure, lineSign(leg.Line.Number, leg.Line.Type),
+				headsign(leg.Line.Direction, leg.Line.Origin, leg.Line.Destination),
+				walkEndpoint(w, leg.From), leg.WaitMinutes, wait, leg.Stops, walkEndpoint(w, leg.To), leg.Arrival)
+		}
+	}
+}
+
+// renderIsochrone renders the response of isochrone
+func renderIsochrone(w *writer, r types.IsochroneResponse) {
+	if r.NetworkLines == 0 {
+		w.line("render.empty_catalog")
+		return
+	}
+	if !w.compact() {
+		w.heading("render.isochrone", walkEndpoint(w, r.Origin), r.Departure)
+		w.line("render.isochrone_live", r.RunningLines, r.MeasuredLines, r.Timestamp)
+	}
+	for _, budget := range r.Budgets {
+		if w.compact() {
+			w.item("render.isochrone_compact", budget.Minutes, budget.Stops)
+			continue
+		}
+		w.item("render.isochrone_budget", budget.Minutes, budget.Stops)
+	}
+	if r.TotalStops == 0 {
+		w.line("render.isochrone_none")
+		return
+	}
+	if !w.compact() {
+		w.line("")
+	}
+	for _, stop := range r.Stops {
+		if w.compact() {
+			w.item("render.reachable_compact", stop.Code, stop.Minutes)
+			continue
+		}
+		w.item("render.reachable_stop", stop.Name, stop.Code, stop.Minutes, stop.Rides)
+	}
+}
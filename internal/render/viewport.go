@@ -0,0 +1,29 @@
+package render
+
+import "github.com/thunderjr/sptrans-mcp/internal/types"
+
+// renderViewport renders the response of query_viewport
+func renderViewport(w *writer, r types.QueryViewportResponse) {
+	if !w.compact() {
+		w.heading("render.viewport", r.TotalStops, r.TotalVehicles, r.Timestamp)
+	}
+	if r.Clustered {
+		if !w.compact() {
+			w.line("render.clusters_zoom", r.Zoom)
+		}
+		for _, cluster := range r.StopClusters {
+			w.item("render.stop_cluster", cluster.Count, cluster.Latitude, cluster.Longitude)
+		}
+		for _, cluster := range r.VehicleClusters {
+			w.item("render.vehicle_cluster", cluster.Count, cluster.Latitude, cluster.Longitude)
+		}
+		return
+	}
+
+	renderStops(w, r.Stops)
+	renderLinesWithVehicles(w, r.Lines)
+	if r.Truncated {
+		w.line("")
+		w.line("render.viewport_truncated")
+	}
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// renderWalkingDistance renders the response of walking_distance
+func renderWalkingDistance(w *writer, r types.WalkingDistanceResponse) {
+	w.line("render.walk", walkEndpoint(w, r.From), walkEndpoint(w, r.To), r.WalkingMeters, r.WalkingMinutes)
+	if !w.compact() {
+		w.line("render.walk_estimate", r.DistanceMeters, r.DetourFactor, r.SpeedKmh)
+	}
+}
+
+// walkEndpoint describes an end of a walk by its stop, place or coordinate
+func walkEndpoint(w *writer, e types.WalkEndpointResponse) string {
+	switch {
+	case e.StopCode != 0:
+		return w.t("render.stop_short", e.Name, e.StopCode)
+	case e.Name != "":
+		return e.Name
+	}
+	return w.t("render.walk_point", e.Latitude, e.Longitude)
+}
+
+// renderNearbyTransfers renders the response of nearby_transfers
+func renderNearbyTransfers(w *writer, r types.NearbyTransfersResponse) {
+	if !w.compact() {
+		w.heading("render.transfers", r.TotalResults, r.RadiusMeters, r.Stop.Name, r.Stop.Code)
+		w.line("render.transfer_lines", lineSigns(r.Lines))
+	}
+	for _, stop := range r.Transfers {
+		w.item("render.nearby_stop", stop.Name, stop.Code, stop.WalkingMeters, stop.WalkingMinutes)
+		w.line("render.transfer_stop", lineSigns(stop.Lines), stop.NewLines)
+	}
+}
+
+// lineSigns joins the signs of the given lines, without repeating both directions of a line
+func lineSigns(lines []types.LineResponse) string {
+	var signs []string
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		sign := lineSign(line.Number, line.Type)
+		if !seen[sign] {
+			seen[sign] = true
+			signs = append(signs, sign)
+		}
+	}
+	if len(signs) == 0 {
+		return "—"
+	}
+	return strings.Join(signs, ", ")
+}
+
+// renderTransferPoints renders the response of find_transfer_points
+func renderTransferPoints(w *writer, r types.FindTransferPointsResponse) {
+	if !w.compact() {
+		w.heading("render.transfer_points", r.TotalResults, lineSigns(r.FromLines), lineSigns(r.ToLines), r.RadiusMeters)
+	}
+	if len(r.TransferPoints) == 0 {
+		w.line("render.no_transfer_points")
+		return
+	}
+	for _, point := range r.TransferPoints {
+		if point.Shared {
+			w.item("render.transfer_shared", point.FromStop.Name, point.FromStop.Code)
+		} else {
+			w.item("render.transfer_walk", point.FromStop.Name, point.FromStop.Code,
+				point.ToStop.Name, point.ToStop.Code, point.WalkingMeters, point.WalkingMinutes)
+		}
+		if w.compact() {
+			continue
+		}
+		for _, c := range point.Connections {
+			connection := w.t("render.transfer_connection",
+				lineSign(c.FromLine.Number, c.FromLine.Type), headsign(c.FromLine.Direction, c.FromLine.Origin, c.FromLine.Destination),
+				lineSign(c.ToLine.Number, c.ToLine.Type), headsign(c.ToLine.Direction, c.ToLine.Origin, c.ToLine.Destination))
+			if c.WaitMinutes != nil {
+				connection += w.t("render.transfer_wait", *c.WaitMinutes)
+			}
+			w.linef("%s", connection)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package route
+
+import (
+	"sort"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+// Sources of the path a route follows
+const (
+	SourceShape = "kmz"   // The route shape published in the SPTrans KMZ files
+	SourceStops = "stops" // Straight segments through the ordered stops
+)
+
+// maxShapeOffset is the mean distance from the stops to a shape above which
+// the shape is taken not to belong to the line and the stops are used instead
+const maxShapeOffset = 150.0
+
+// Route is the itinerary of a line: a path with its ordered stops located on it
+type Route struct {
+	path   *geo.Polyline
+	stops  []types.Stop
+	along  []float64 // Distance of each stop along the path
+	source string
+}
+
+// Position is a point located on a route
+type Position struct {
+	Along    float64 // Distance along the path, in meters
+	Offset   float64 // Distance from the point to the path, in meters
+	Previous int     // Index of the last stop passed, -1 before the first stop
+	Next     int     // Index of the next stop, -1 after the last stop
+}
+
+// New creates the route through the ordered stops, following the shape when
+// it has one that passes by the stops
+func New(stops []types.Stop, shape []geo.Point) *Route {
+	if len(shape) >= 2 && len(stops) >= 2 {
+		path := geo.NewPolyline(shape)
+		first := path.Project(stopPoint(stops[0]))
+		last := path.Project(stopPoint(stops[len(stops)-1]))
+		if first.Along > last.Along {
+			path = path.Reverse()
+		}
+		if r := locateStops(path, stops, SourceShape); r.meanOffset() <= maxShapeOffset {
+			return r
+		}
+	}
+
+	points := make([]geo.Point, len(stops))
+	for i, stop := range stops {
+		points[i] = stopPoint(stop)
+	}
+	return locateStops(geo.NewPolyline(points), stops, SourceStops)
+}
+
+// locateStops snaps the stops to the path in order
+func locateStops(path *geo.Polyline, stops []types.Stop, source string) *Route {
+	r := &Route{path: path, stops: stops, along: make([]float64, len(stops)), source: source}
+	from := 0.0
+	for i, stop := range stops {
+		from = path.ProjectFrom(stopPoint(stop), from).Along
+		r.along[i] = from
+	}
+	return r
+}
+
+// meanOffset returns the mean distance from the stops to the path
+func (r *Route) meanOffset() float64 {
+	if len(r.stops) == 0 {
+		return 0
+	}
+	total := 0.0
+	for i, stop := range r.stops {
+		total += r.path.ProjectFrom(stopPoint(stop), r.along[i]).Offset
+	}
+	return total / float64(len(r.stops))
+}
+
+// Source returns where the path of the route comes from, SourceShape or SourceStops
+func (r *Route) Source() string {
+	return r.source
+}
+
+// Stops returns the ordered stops of the route
+func (r *Route) Stops() []types.Stop {
+	return r.stops
+}
+
+// Length returns the distance from the first to the last stop, in meters
+func (r *Route) Length() float64 {
+	if len(r.along) == 0 {
+		return 0
+	}
+	return r.along[len(r.along)-1] - r.along[0]
+}
+
+// StopAlong returns the distance of a stop from the first stop, in meters
+func (r *Route) StopAlong(index int) float64 {
+	return r.along[index] - r.along[0]
+}
+
+// StopIndex returns the index of the stop with the code, or -1
+func (r *Route) StopIndex(code int) int {
+	for i, stop := range r.stops {
+		if stop.Code == code {
+			return i
+		}
+	}
+	return -1
+}
+
+// Locate snaps a point to the route, measuring from the first stop
+func (r *Route) Locate(lat, lon float64) Position {
+	snap := r.path.Project(geo.Point{Latitude: lat, Longitude: lon})
+	pos := Position{Offset: snap.Offset, Previous: -1, Next: -1}
+	if len(r.stops) == 0 {
+		return pos
+	}
+	pos.Along = snap.Along - r.along[0]
+
+	// First stop beyond the point; the one before it was the last passed
+	next := sort.Search(len(r.along), func(i int) bool { return r.along[i] > snap.Along })
+	pos.Previous = next - 1
+	if next < len(r.stops) {
+		pos.Next = next
+	}
+	return pos
+}
+
+// stopPoint returns the coordinate of a stop
+func stopPoint(stop types.Stop) geo.Point {
+	return geo.Point{Latitude: stop.Latitude, Longitude: stop.Longitude}
+}
@@ -0,0 +1,86 @@
+package session
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+)
+
+// Preferences holds the settings a client chose for its session
+type Preferences struct {
+	Locale         i18n.Locale // Language of messages and rendered text
+	AccessibleOnly bool        // Only include wheelchair-accessible vehicles unless a call says otherwise
+	Places         []Place     // Places saved under a name, replaced rather than modified when they change
+}
+
+// Place is a location saved under a name, e.g. home or work
+type Place struct {
+	Name      string
+	Label     string // Address or coordinate the place was saved from
+	Latitude  float64
+	Longitude float64
+}
+
+// Place returns the saved place with the given name, ignoring case
+func (p Preferences) Place(name string) (Place, bool) {
+	for _, place := range p.Places {
+		if strings.EqualFold(place.Name, strings.TrimSpace(name)) {
+			return place, true
+		}
+	}
+	return Place{}, false
+}
+
+// Store keeps the preferences of each connected session
+type Store struct {
+	mu       sync.RWMutex
+	defaults Preferences
+	sessions map[*mcp.ServerSession]Preferences
+}
+
+// NewStore creates a store that hands out defaults to sessions without preferences
+func NewStore(defaults Preferences) *Store {
+	return &Store{
+		defaults: defaults,
+		sessions: make(map[*mcp.ServerSession]Preferences),
+	}
+}
+
+// SetDefaults replaces the preferences of sessions that have not set their own
+func (s *Store) SetDefaults(defaults Preferences) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults = defaults
+}
+
+// Get returns the preferences of the session
+func (s *Store) Get(ss *mcp.ServerSession) Preferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if prefs, ok := s.sessions[ss]; ok {
+		return prefs
+	}
+	return s.defaults
+}
+
+// Update applies change to the preferences of the session and returns the result
+func (s *Store) Update(ss *mcp.ServerSession, change func(prefs *Preferences)) Preferences {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefs, ok := s.sessions[ss]
+	if !ok {
+		prefs = s.defaults
+	}
+	change(&prefs)
+	s.sessions[ss] = prefs
+	return prefs
+}
+
+// Forget drops the preferences of a closed session
+func (s *Store) Forget(ss *mcp.ServerSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, ss)
+}
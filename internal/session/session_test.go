@@ -0,0 +1,68 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(Preferences{Locale: i18n.English})
+	a, b := new(mcp.ServerSession), new(mcp.ServerSession)
+
+	if got := store.Get(a).Locale; got != i18n.English {
+		t.Errorf("new session locale = %s, want the default", got)
+	}
+
+	prefs := store.Update(a, func(prefs *Preferences) {
+		prefs.Locale = i18n.Portuguese
+		prefs.AccessibleOnly = true
+	})
+	if prefs.Locale != i18n.Portuguese || !prefs.AccessibleOnly {
+		t.Errorf("Update returned %+v", prefs)
+	}
+	if got := store.Get(a); got.Locale != i18n.Portuguese || !got.AccessibleOnly {
+		t.Errorf("updated session = %+v", got)
+	}
+	if got := store.Get(b); got.Locale != i18n.English || got.AccessibleOnly {
+		t.Errorf("other session = %+v, want the defaults", got)
+	}
+
+	store.SetDefaults(Preferences{Locale: i18n.Portuguese})
+	if got := store.Get(b).Locale; got != i18n.Portuguese {
+		t.Errorf("session without preferences locale = %s, want the new default", got)
+	}
+	store.Update(b, func(prefs *Preferences) { prefs.AccessibleOnly = true })
+	if got := store.Get(b); got.Locale != i18n.Portuguese {
+		t.Errorf("Update started from %s, want the current defaults", got.Locale)
+	}
+
+	store.Forget(a)
+	if got := store.Get(a); got.AccessibleOnly {
+		t.Errorf("forgotten session = %+v, want the defaults", got)
+	}
+}
+
+func TestPreferencesPlace(t *testing.T) {
+	prefs := Preferences{Places: []Place{
+		{Name: "home", Latitude: -23.5, Longitude: -46.6},
+		{Name: "Work", Latitude: -23.6, Longitude: -46.7},
+	}}
+	tests := []struct {
+		name string
+		want float64
+		ok   bool
+	}{
+		{"home", -23.5, true},
+		{" HOME ", -23.5, true},
+		{"work", -23.6, true},
+		{"school", 0, false},
+	}
+	for _, tt := range tests {
+		place, ok := prefs.Place(tt.name)
+		if ok != tt.ok || place.Latitude != tt.want {
+			t.Errorf("Place(%q) = %+v, %v; want latitude %v, %v", tt.name, place, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package shape
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+)
+
+// placemark is a KML placemark carrying a route shape
+type placemark struct {
+	Name        string   `xml:"name"`
+	Description string   `xml:"description"`
+	Lines       []string `xml:"LineString>coordinates"`
+	MultiLines  []string `xml:"MultiGeometry>LineString>coordinates"`
+}
+
+// Shape is a named route shape read from a KMZ file
+type Shape struct {
+	Name        string
+	Description string
+	Points      []geo.Point
+}
+
+// ParseKMZ reads the route shapes of a KMZ archive: every placemark with a
+// line geometry, its line strings joined in document order
+func ParseKMZ(data []byte) ([]Shape, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open KMZ: %w", err)
+	}
+	for _, file := range archive.File {
+		if !strings.HasSuffix(strings.ToLower(file.Name), ".kml") {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+		}
+		defer r.Close()
+		return ParseKML(r)
+	}
+	return nil, errors.New("KMZ has no KML document")
+}
+
+// ParseKML reads the route shapes of a KML document
+func ParseKML(r io.Reader) ([]Shape, error) {
+	dec := xml.NewDecoder(r)
+	var shapes []Shape
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return shapes, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read KML: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+
+		var pm placemark
+		if err := dec.DecodeElement(&pm, &start); err != nil {
+			return nil, fmt.Errorf("failed to read KML placemark: %w", err)
+		}
+		var points []geo.Point
+		for _, coordinates := range append(pm.Lines, pm.MultiLines...) {
+			points = append(points, parseCoordinates(coordinates)...)
+		}
+		if len(points) >= 2 {
+			shapes = append(shapes, Shape{
+				Name:        strings.TrimSpace(pm.Name),
+				Description: strings.TrimSpace(pm.Description),
+				Points:      points,
+			})
+		}
+	}
+}
+
+// parseCoordinates parses KML coordinates, whitespace-separated
+// "longitude,latitude[,altitude]" tuples, skipping malformed ones
+func parseCoordinates(text string) []geo.Point {
+	var points []geo.Point
+	for _, tuple := range strings.Fields(text) {
+		parts := strings.Split(tuple, ",")
+		if len(parts) < 2 {
+			continue
+		}
+		lon, errLon := strconv.ParseFloat(parts[0], 64)
+		lat, errLat := strconv.ParseFloat(parts[1], 64)
+		if errLon != nil || errLat != nil || !geo.ValidCoordinate(lat, lon) {
+			continue
+		}
+		points = append(points, geo.Point{Latitude: lat, Longitude: lon})
+	}
+	return points
+}
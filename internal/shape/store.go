@@ -0,0 +1,139 @@
+package shape
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/types"
+)
+
+const (
+	// loadTimeout bounds the download and parsing of a KMZ file
+	loadTimeout = 2 * time.Minute
+	// retryAfter is how long a failed download is remembered before retrying
+	retryAfter = 10 * time.Minute
+)
+
+// Fetcher downloads the KMZ file with the route shapes of a direction
+type Fetcher func(ctx context.Context, direction int) ([]byte, error)
+
+// Store keeps the route shapes of each direction, downloading them in the
+// background on first use and again once they are older than the refresh
+// interval. Lookups never wait for a download.
+type Store struct {
+	fetch   Fetcher
+	refresh time.Duration
+
+	mu       sync.Mutex
+	shapes   map[int][]Shape   // Shapes of each direction
+	loadedAt map[int]time.Time // When each direction was last downloaded or failed
+	failed   map[int]bool      // Whether the last download of each direction failed
+	loading  map[int]bool      // Directions being downloaded
+}
+
+// NewStore creates a store downloading shapes with fetch
+func NewStore(fetch Fetcher, refresh time.Duration) *Store {
+	return &Store{
+		fetch:    fetch,
+		refresh:  refresh,
+		shapes:   make(map[int][]Shape),
+		loadedAt: make(map[int]time.Time),
+		failed:   make(map[int]bool),
+		loading:  make(map[int]bool),
+	}
+}
+
+// Lookup returns the shape of a line, matched by its sign in the placemark
+// name or description. When the shapes of the line's direction are missing
+// or stale it starts downloading them and answers from what it has.
+func (s *Store) Lookup(line types.Line) ([]geo.Point, bool) {
+	if s == nil || line.Number == "" {
+		return nil, false
+	}
+	direction := line.Direction
+	if direction != 2 {
+		direction = 1
+	}
+
+	s.mu.Lock()
+	shapes := s.shapes[direction]
+	if s.stale(direction) && !s.loading[direction] {
+		s.loading[direction] = true
+		go s.load(direction)
+	}
+	s.mu.Unlock()
+
+	sign := strings.ToUpper(line.Sign())
+	for _, shape := range shapes {
+		if containsToken(strings.ToUpper(shape.Name), sign) || containsToken(strings.ToUpper(shape.Description), sign) {
+			return shape.Points, true
+		}
+	}
+	return nil, false
+}
+
+// stale reports whether the shapes of a direction should be downloaded; the
+// caller holds the lock
+func (s *Store) stale(direction int) bool {
+	loadedAt, ok := s.loadedAt[direction]
+	if !ok {
+		return true
+	}
+	if s.failed[direction] {
+		return time.Since(loadedAt) > retryAfter
+	}
+	return time.Since(loadedAt) > s.refresh
+}
+
+// load downloads and parses the shapes of a direction
+func (s *Store) load(direction int) {
+	ctx, cancel := context.WithTimeout(context.Background(), loadTimeout)
+	defer cancel()
+
+	var shapes []Shape
+	data, err := s.fetch(ctx, direction)
+	if err == nil {
+		shapes, err = ParseKMZ(data)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loading[direction] = false
+	s.loadedAt[direction] = time.Now()
+	s.failed[direction] = err != nil
+	if err != nil {
+		log.Printf("Route shapes of direction %d unavailable: %v", direction, err)
+		return
+	}
+	s.shapes[direction] = shapes
+	log.Printf("Loaded %d route shapes of direction %d", len(shapes), direction)
+}
+
+// containsToken reports whether token occurs in text delimited by
+// characters other than letters and digits
+func containsToken(text, token string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], token)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(token)
+		before := i == 0 || !isWordByte(text[i-1])
+		after := end == len(text) || !isWordByte(text[end])
+		if before && after {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+// isWordByte reports whether an ASCII byte is a letter or digit
+func isWordByte(b byte) bool {
+	return b < unicode.MaxASCII && (unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b)))
+}
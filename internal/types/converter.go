@@ -1,5 +1,12 @@
 package types
 
+import (
+	"math"
+	"time"
+
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+)
+
 // Conversion functions to transform SPTrans structs to clean JSON response structs
 
 // ConvertLine converts a Line struct to LineResponse
@@ -44,6 +51,40 @@ func ConvertStops(stops []Stop) []StopResponse {
 	return result
 }
 
+// ConvertNearbyStop converts a Stop struct to NearbyStopResponse with its straight-line
+// and walking distances in meters and walking time in seconds
+func ConvertNearbyStop(stop Stop, distance, walkingDistance, walkingSeconds float64) NearbyStopResponse {
+	return NearbyStopResponse{
+		Code:           stop.Code,
+		Name:           stop.Name,
+		Address:        stop.Address,
+		Latitude:       stop.Latitude,
+		Longitude:      stop.Longitude,
+		DistanceMeters: int(math.Round(distance)),
+		WalkingMeters:  int(math.Round(walkingDistance)),
+		WalkingMinutes: int(math.Ceil(walkingSeconds / 60)),
+	}
+}
+
+// ConvertClusters converts map clusters to ClusterResponse structs
+func ConvertClusters(clusters []geo.Cluster) []ClusterResponse {
+	result := make([]ClusterResponse, len(clusters))
+	for i, cluster := range clusters {
+		result[i] = ClusterResponse{
+			Latitude:  cluster.Latitude,
+			Longitude: cluster.Longitude,
+			Count:     cluster.Count,
+			BoundingBox: BoundingBox{
+				South: cluster.South,
+				West:  cluster.West,
+				North: cluster.North,
+				East:  cluster.East,
+			},
+		}
+	}
+	return result
+}
+
 // ConvertCorridor converts a Corridor struct to CorridorResponse
 func ConvertCorridor(corridor Corridor) CorridorResponse {
 	return CorridorResponse{
@@ -72,6 +113,55 @@ func ConvertVehicle(vehicle Vehicle) VehicleResponse {
 	}
 }
 
+// ConvertNearbyVehicle converts a vehicle of a line to NearbyVehicleResponse,
+// with its distance and bearing from a point and its age at now
+func ConvertNearbyVehicle(line VehicleLine, vehicle Vehicle, distance, bearing float64, compass string, now time.Time) NearbyVehicleResponse {
+	age := now.Sub(vehicle.LastUpdate)
+	if age < 0 {
+		age = 0
+	}
+	return NearbyVehicleResponse{
+		ID:             vehicle.ID,
+		Accessible:     vehicle.Accessible,
+		Latitude:       vehicle.Latitude,
+		Longitude:      vehicle.Longitude,
+		LineIdentifier: line.Identifier,
+		LineCode:       line.Code,
+		Direction:      line.Direction,
+		Origin:         line.Origin,
+		Destination:    line.Destination,
+		DistanceMeters: int(math.Round(distance)),
+		Bearing:        int(math.Round(bearing)) % 360,
+		Compass:        compass,
+		LastUpdate:     vehicle.LastUpdate.Format(time.RFC3339),
+		AgeSeconds:     int(age.Seconds()),
+	}
+}
+
+// ConvertVehicleProgress converts a vehicle located along its route to
+// VehicleProgressResponse, given the stops around it, if any
+func ConvertVehicleProgress(vehicle Vehicle, previous, next *Stop, travelled, offset float64, offRoute bool) VehicleProgressResponse {
+	response := VehicleProgressResponse{
+		ID:              vehicle.ID,
+		Accessible:      vehicle.Accessible,
+		Latitude:        vehicle.Latitude,
+		Longitude:       vehicle.Longitude,
+		LastUpdate:      vehicle.LastUpdate.Format(time.RFC3339),
+		TravelledMeters: int(math.Round(travelled)),
+		OffsetMeters:    int(math.Round(offset)),
+		OffRoute:        offRoute,
+	}
+	if previous != nil {
+		response.PreviousStopCode = previous.Code
+		response.PreviousStopName = previous.Name
+	}
+	if next != nil {
+		response.NextStopCode = next.Code
+		response.NextStopName = next.Name
+	}
+	return response
+}
+
 // ConvertVehicles converts a slice of Vehicle structs to VehicleResponse structs
 func ConvertVehicles(vehicles []Vehicle) []VehicleResponse {
 	result := make([]VehicleResponse, len(vehicles))
@@ -257,7 +347,8 @@ func BuildGetVehiclePositionsResponse(positions VehiclePositions) GetVehiclePosi
 		Timestamp:     positions.Hour,
 		TotalVehicles: totalVehicles,
 		TotalLines:    len(positions.Lines),
-		Positions:     convertedPositions,
+		Returned:      totalVehicles,
+		Positions:     &convertedPositions,
 	}
 }
 
@@ -335,4 +426,22 @@ func BuildGetArrivalPredictionsByStopResponse(stopCode int, predictions ArrivalP
 		TotalStops:       len(predictions.Stops),
 		Predictions:      convertedPredictions,
 	}
+}
+
+// MinutesUntil returns the minutes from the "HH:MM" reference time to the
+// "HH:MM" arrival time, wrapping around midnight
+func MinutesUntil(reference, arrival string) (int, bool) {
+	from, err := time.Parse("15:04", reference)
+	if err != nil {
+		return 0, false
+	}
+	to, err := time.Parse("15:04", arrival)
+	if err != nil {
+		return 0, false
+	}
+	minutes := int(to.Sub(from).Minutes())
+	if minutes < -12*60 {
+		minutes += 24 * 60
+	}
+	return minutes, true
 }
\ No newline at end of file
@@ -0,0 +1,282 @@
+package types
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Coordinate represents a geographic point
+type Coordinate struct {
+	Latitude  float64 `json:"latitude" jsonschema:"Latitude of the point"`
+	Longitude float64 `json:"longitude" jsonschema:"Longitude of the point"`
+}
+
+// BoundingBox represents a geographic rectangle
+type BoundingBox struct {
+	South float64 `json:"south" jsonschema:"Southern latitude limit"`
+	West  float64 `json:"west" jsonschema:"Western longitude limit"`
+	North float64 `json:"north" jsonschema:"Northern latitude limit"`
+	East  float64 `json:"east" jsonschema:"Eastern longitude limit"`
+}
+
+// Valid reports whether the box has its corners in the right order
+func (b BoundingBox) Valid() bool {
+	return b.South < b.North && b.West < b.East
+}
+
+// Center returns the middle of the box
+func (b BoundingBox) Center() Coordinate {
+	return Coordinate{Latitude: (b.South + b.North) / 2, Longitude: (b.West + b.East) / 2}
+}
+
+// Contains reports whether the coordinate falls inside the box
+func (b BoundingBox) Contains(latitude, longitude float64) bool {
+	return latitude >= b.South && latitude <= b.North &&
+		longitude >= b.West && longitude <= b.East
+}
+
+// VehicleFilter selects vehicles from a vehicle positions snapshot
+type VehicleFilter struct {
+	LinePrefix     string       // Line identifier prefix, case-insensitive
+	PrefixArea     int          // Operating area 1-8, matched against the leading digit of the vehicle prefix
+	AccessibleOnly bool         // Only accessible vehicles
+	BoundingBox    *BoundingBox // Only vehicles inside the box
+}
+
+// MatchLine reports whether the line can contain matching vehicles
+func (f VehicleFilter) MatchLine(line VehicleLine) bool {
+	if f.LinePrefix == "" {
+		return true
+	}
+	return strings.HasPrefix(strings.ToUpper(line.Identifier), strings.ToUpper(f.LinePrefix))
+}
+
+// MatchVehicle reports whether the vehicle passes the filter
+func (f VehicleFilter) MatchVehicle(vehicle Vehicle) bool {
+	if f.AccessibleOnly && !vehicle.Accessible {
+		return false
+	}
+	if f.PrefixArea > 0 && !strings.HasPrefix(strconv.Itoa(vehicle.ID), strconv.Itoa(f.PrefixArea)) {
+		return false
+	}
+	if f.BoundingBox != nil && !f.BoundingBox.Contains(vehicle.Latitude, vehicle.Longitude) {
+		return false
+	}
+	return true
+}
+
+// VehicleCursor marks the last item of a page in the order pages are
+// returned: by line code, then by vehicle ID. The zero cursor starts at the
+// first item.
+type VehicleCursor struct {
+	Line    int // Line code of the last item
+	Vehicle int // Vehicle ID of the last item, zero in summary mode
+}
+
+// ParseVehicleCursor decodes a cursor written by VehicleCursor.String
+func ParseVehicleCursor(s string) (VehicleCursor, error) {
+	if s == "" {
+		return VehicleCursor{}, nil
+	}
+	line, vehicle, found := strings.Cut(s, ":")
+	var cursor VehicleCursor
+	var err error
+	if cursor.Line, err = strconv.Atoi(line); err != nil || cursor.Line <= 0 {
+		return VehicleCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	if found {
+		if cursor.Vehicle, err = strconv.Atoi(vehicle); err != nil || cursor.Vehicle <= 0 {
+			return VehicleCursor{}, fmt.Errorf("invalid cursor %q", s)
+		}
+	}
+	return cursor, nil
+}
+
+// String encodes the cursor as line or line:vehicle
+func (c VehicleCursor) String() string {
+	if c.Vehicle == 0 {
+		return strconv.Itoa(c.Line)
+	}
+	return fmt.Sprintf("%d:%d", c.Line, c.Vehicle)
+}
+
+// compare orders cursors by line code, then by vehicle ID
+func (c VehicleCursor) compare(other VehicleCursor) int {
+	return cmp.Or(cmp.Compare(c.Line, other.Line), cmp.Compare(c.Vehicle, other.Vehicle))
+}
+
+// VehiclePage accumulates one page of filtered vehicle positions while a
+// snapshot is decoded line by line, keeping only what the page returns.
+// Items are ordered by line code and vehicle ID and the page starts after
+// a cursor rather than at an offset, so pages read from different
+// snapshots never overlap and only miss vehicles that changed line.
+type VehiclePage struct {
+	filter      VehicleFilter
+	after       VehicleCursor
+	limit       int
+	fields      []string
+	summaryOnly bool
+
+	totalVehicles int
+	totalLines    int
+	more          bool
+	vehicles      []pageVehicle
+	lines         map[int]LineWithVehiclesResponse
+	summary       []LineVehicleCountResponse
+}
+
+// pageVehicle is a vehicle kept for the page along with its line code
+type pageVehicle struct {
+	line    int
+	vehicle Vehicle
+}
+
+// NewVehiclePage creates a page of at most limit items following the cursor.
+// Items are vehicles, or lines when summaryOnly is set.
+func NewVehiclePage(filter VehicleFilter, after VehicleCursor, limit int, fields []string, summaryOnly bool) *VehiclePage {
+	return &VehiclePage{
+		filter:      filter,
+		after:       after,
+		limit:       limit,
+		fields:      fields,
+		summaryOnly: summaryOnly,
+		lines:       make(map[int]LineWithVehiclesResponse),
+	}
+}
+
+// Add filters a decoded line and keeps the part of it that may fall inside the page
+func (p *VehiclePage) Add(line VehicleLine) {
+	if !p.filter.MatchLine(line) {
+		return
+	}
+
+	var matched []Vehicle
+	accessible := 0
+	for _, vehicle := range line.Vehicles {
+		if p.filter.MatchVehicle(vehicle) {
+			matched = append(matched, vehicle)
+			if vehicle.Accessible {
+				accessible++
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+	p.totalLines++
+	p.totalVehicles += len(matched)
+
+	if p.summaryOnly {
+		if line.Code > p.after.Line {
+			p.summary = append(p.summary, LineVehicleCountResponse{
+				Identifier:      line.Identifier,
+				Code:            line.Code,
+				Direction:       line.Direction,
+				Origin:          line.Origin,
+				Destination:     line.Destination,
+				VehicleCount:    len(matched),
+				AccessibleCount: accessible,
+			})
+			if len(p.summary) >= 2*p.limit {
+				p.trim()
+			}
+		}
+		return
+	}
+
+	kept := false
+	for _, vehicle := range matched {
+		if (VehicleCursor{Line: line.Code, Vehicle: vehicle.ID}).compare(p.after) > 0 {
+			p.vehicles = append(p.vehicles, pageVehicle{line: line.Code, vehicle: vehicle})
+			kept = true
+		}
+	}
+	if kept {
+		p.lines[line.Code] = LineWithVehiclesResponse{
+			Identifier:   line.Identifier,
+			Code:         line.Code,
+			Direction:    line.Direction,
+			Origin:       line.Origin,
+			Destination:  line.Destination,
+			VehicleCount: len(matched),
+		}
+	}
+	if len(p.vehicles) >= 2*p.limit {
+		p.trim()
+	}
+}
+
+// trim sorts the kept items and drops those past the limit, so memory
+// stays proportional to the page size while the snapshot is decoded
+func (p *VehiclePage) trim() {
+	if p.summaryOnly {
+		slices.SortFunc(p.summary, func(a, b LineVehicleCountResponse) int {
+			return cmp.Compare(a.Code, b.Code)
+		})
+		if len(p.summary) > p.limit {
+			p.summary, p.more = p.summary[:p.limit], true
+		}
+		return
+	}
+
+	slices.SortFunc(p.vehicles, func(a, b pageVehicle) int {
+		return a.cursor().compare(b.cursor())
+	})
+	if len(p.vehicles) > p.limit {
+		p.vehicles, p.more = p.vehicles[:p.limit], true
+		for code := range p.lines {
+			if code > p.vehicles[len(p.vehicles)-1].line {
+				delete(p.lines, code)
+			}
+		}
+	}
+}
+
+// cursor returns the position of the vehicle in the page order
+func (v pageVehicle) cursor() VehicleCursor {
+	return VehicleCursor{Line: v.line, Vehicle: v.vehicle.ID}
+}
+
+// Response builds the GetVehiclePositionsResponse for the accumulated page
+func (p *VehiclePage) Response(hour string) GetVehiclePositionsResponse {
+	p.trim()
+
+	response := GetVehiclePositionsResponse{
+		Timestamp:     hour,
+		TotalVehicles: p.totalVehicles,
+		TotalLines:    p.totalLines,
+	}
+
+	if p.summaryOnly {
+		response.Returned = len(p.summary)
+		if p.more {
+			response.NextCursor = VehicleCursor{Line: p.summary[len(p.summary)-1].Code}.String()
+		}
+		response.Summary = p.summary
+		if response.Summary == nil {
+			response.Summary = []LineVehicleCountResponse{}
+		}
+		return response
+	}
+
+	response.Returned = len(p.vehicles)
+	if p.more {
+		response.NextCursor = p.vehicles[len(p.vehicles)-1].cursor().String()
+	}
+	lines := []LineWithVehiclesResponse{}
+	for _, v := range p.vehicles {
+		if n := len(lines); n == 0 || lines[n-1].Code != v.line {
+			lines = append(lines, p.lines[v.line])
+		}
+		last := &lines[len(lines)-1]
+		last.Vehicles = append(last.Vehicles, ConvertVehicle(v.vehicle).Project(p.fields))
+	}
+	response.Positions = &VehiclePositionsResponse{
+		Timestamp: hour,
+		Lines:     lines,
+	}
+	return response
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVehiclePagePaging(t *testing.T) {
+	first := []VehicleLine{
+		{Code: 30, Vehicles: []Vehicle{{ID: 5}, {ID: 3}}},
+		{Code: 10, Vehicles: []Vehicle{{ID: 9}, {ID: 1}, {ID: 4, Accessible: true}}},
+		{Code: 20, Vehicles: []Vehicle{{ID: 2}}},
+	}
+	// The same network a moment later, in a different order and with a
+	// vehicle added before the cursor and one gone after it
+	later := []VehicleLine{
+		{Code: 20, Vehicles: []Vehicle{{ID: 2}}},
+		{Code: 10, Vehicles: []Vehicle{{ID: 9}, {ID: 0}, {ID: 1}, {ID: 4, Accessible: true}}},
+		{Code: 30, Vehicles: []Vehicle{{ID: 5}}},
+	}
+
+	tests := []struct {
+		name      string
+		snapshots [][]VehicleLine
+		filter    VehicleFilter
+		summary   bool
+		want      []string
+	}{
+		{
+			name:      "vehicles",
+			snapshots: [][]VehicleLine{first},
+			want:      []string{"10:1", "10:4", "10:9", "20:2", "30:3", "30:5"},
+		},
+		{
+			name:      "changing snapshots",
+			snapshots: [][]VehicleLine{first, later},
+			want:      []string{"10:1", "10:4", "10:9", "20:2", "30:5"},
+		},
+		{
+			name:      "filtered",
+			snapshots: [][]VehicleLine{first},
+			filter:    VehicleFilter{AccessibleOnly: true},
+			want:      []string{"10:4"},
+		},
+		{
+			name:      "summary",
+			snapshots: [][]VehicleLine{first},
+			summary:   true,
+			want:      []string{"10", "20", "30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			var cursor VehicleCursor
+			for page := 0; ; page++ {
+				snapshot := tt.snapshots[min(page, len(tt.snapshots)-1)]
+				p := NewVehiclePage(tt.filter, cursor, 2, nil, tt.summary)
+				for _, line := range snapshot {
+					p.Add(line)
+				}
+				response := p.Response("12:00")
+				if response.Returned > 2 {
+					t.Fatalf("page %d returned %d items, want at most 2", page, response.Returned)
+				}
+				for _, line := range response.Summary {
+					got = append(got, VehicleCursor{Line: line.Code}.String())
+				}
+				if response.Positions != nil {
+					for _, line := range response.Positions.Lines {
+						for _, vehicle := range line.Vehicles {
+							got = append(got, VehicleCursor{Line: line.Code, Vehicle: vehicle.ID}.String())
+						}
+					}
+				}
+				if response.NextCursor == "" {
+					break
+				}
+				var err error
+				if cursor, err = ParseVehicleCursor(response.NextCursor); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("pages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVehicleCursor(t *testing.T) {
+	tests := []struct {
+		cursor  string
+		want    VehicleCursor
+		wantErr bool
+	}{
+		{cursor: "", want: VehicleCursor{}},
+		{cursor: "1273", want: VehicleCursor{Line: 1273}},
+		{cursor: "1273:11020", want: VehicleCursor{Line: 1273, Vehicle: 11020}},
+		{cursor: "200", want: VehicleCursor{Line: 200}},
+		{cursor: "-1", wantErr: true},
+		{cursor: "1273:", wantErr: true},
+		{cursor: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVehicleCursor(tt.cursor)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseVehicleCursor(%q) = %v, %v, want %v, error %v", tt.cursor, got, err, tt.want, tt.wantErr)
+		}
+		if err == nil && got.String() != tt.cursor && tt.cursor != "" {
+			t.Errorf("String() = %q, want %q", got.String(), tt.cursor)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Clean JSON response structs with readable field names
 
@@ -37,6 +40,38 @@ type VehicleResponse struct {
 	LastUpdate  time.Time `json:"last_update"`  // Last update timestamp
 	Latitude    float64   `json:"latitude"`     // Latitude
 	Longitude   float64   `json:"longitude"`    // Longitude
+
+	fields []string // Projected JSON fields, nil for all
+}
+
+// VehicleFields lists the JSON fields of VehicleResponse available for projection
+var VehicleFields = []string{"id", "accessible", "last_update", "latitude", "longitude"}
+
+// Project returns a copy of the vehicle that only serializes the given JSON fields
+func (v VehicleResponse) Project(fields []string) VehicleResponse {
+	v.fields = fields
+	return v
+}
+
+// MarshalJSON serializes the vehicle, keeping only the projected fields if any
+func (v VehicleResponse) MarshalJSON() ([]byte, error) {
+	type plain VehicleResponse
+	data, err := json.Marshal(plain(v))
+	if err != nil || len(v.fields) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	projected := make(map[string]json.RawMessage, len(v.fields))
+	for _, field := range v.fields {
+		if value, ok := all[field]; ok {
+			projected[field] = value
+		}
+	}
+	return json.Marshal(projected)
 }
 
 // LineWithVehiclesResponse represents a line with its vehicles
@@ -126,12 +161,26 @@ type GetStopsByCorridorResponse struct {
 	Stops         []StopResponse `json:"stops"`          // Found stops
 }
 
+// LineVehicleCountResponse represents the vehicle counts of a single line
+type LineVehicleCountResponse struct {
+	Identifier      string `json:"identifier"`       // Line identifier
+	Code            int    `json:"code"`             // Line code
+	Direction       int    `json:"direction"`        // Direction
+	Origin          string `json:"origin"`           // Origin terminal
+	Destination     string `json:"destination"`      // Destination terminal
+	VehicleCount    int    `json:"vehicle_count"`    // Number of matching vehicles
+	AccessibleCount int    `json:"accessible_count"` // Number of matching accessible vehicles
+}
+
 // GetVehiclePositionsResponse represents the response for vehicle positions
 type GetVehiclePositionsResponse struct {
-	Timestamp     string                       `json:"timestamp"`      // Data timestamp
-	TotalVehicles int                          `json:"total_vehicles"` // Total number of vehicles
-	TotalLines    int                          `json:"total_lines"`    // Total number of lines
-	Positions     VehiclePositionsResponse     `json:"positions"`      // Vehicle positions data
+	Timestamp     string                       `json:"timestamp"`             // Data timestamp
+	TotalVehicles int                          `json:"total_vehicles"`        // Total number of matching vehicles
+	TotalLines    int                          `json:"total_lines"`           // Total number of matching lines
+	Returned      int                          `json:"returned"`              // Vehicles (or lines in summary mode) in this page
+	NextCursor    string                       `json:"next_cursor,omitempty"` // Cursor after the last item, empty on the last page
+	Summary       []LineVehicleCountResponse   `json:"summary,omitempty"`     // Per-line counts in summary mode
+	Positions     *VehiclePositionsResponse    `json:"positions,omitempty"`   // Vehicle positions data
 }
 
 // GetVehiclePositionsByLineResponse represents the response for vehicle positions by line
@@ -168,4 +217,551 @@ type GetArrivalPredictionsByStopResponse struct {
 	TotalPredictions int                              `json:"total_predictions"` // Total number of predictions
 	TotalStops       int                              `json:"total_stops"`       // Total number of stops
 	Predictions      ArrivalPredictionsByLineResponse `json:"predictions"`       // Predictions data
-}
\ No newline at end of file
+}
+// PreferencesResponse represents the preferences of the current session
+type PreferencesResponse struct {
+	Locale         string               `json:"locale"`          // Language of messages and rendered text
+	AccessibleOnly bool                 `json:"accessible_only"` // Only include wheelchair-accessible vehicles by default
+	Places         []SavedPlaceResponse `json:"places"`          // Places saved under a name
+}
+
+// SavedPlaceResponse represents a location saved under a name
+type SavedPlaceResponse struct {
+	Name      string  `json:"name"`      // Name the place is saved under, e.g. home
+	Label     string  `json:"label"`     // Address or coordinate the place was saved from
+	Latitude  float64 `json:"latitude"`  // Latitude
+	Longitude float64 `json:"longitude"` // Longitude
+}
+
+// ErrorResponse represents a classified tool error
+type ErrorResponse struct {
+	Kind       string              `json:"kind"`                 // Error classification
+	Message    string              `json:"message"`              // Localized error message
+	Code       int                 `json:"code,omitempty"`       // HTTP status of the SPTrans API, if it failed
+	Candidates []CandidateResponse `json:"candidates,omitempty"` // Entities an ambiguous argument may refer to
+}
+
+// CandidateResponse represents a line or stop an ambiguous argument may refer to
+type CandidateResponse struct {
+	Code        int    `json:"code"`                  // Line or stop code, which resolves the argument
+	Label       string `json:"label"`                 // Line sign with its direction, or stop name
+	Description string `json:"description,omitempty"` // Terminals of the line, or address of the stop
+}
+
+// ToolMetricsResponse represents the call metrics of a single tool
+type ToolMetricsResponse struct {
+	Tool          string `json:"tool"`           // Tool name
+	Calls         int    `json:"calls"`          // Number of calls
+	Errors        int    `json:"errors"`         // Number of failed calls
+	AverageMillis int64  `json:"average_millis"` // Average call duration in milliseconds
+	MaxMillis     int64  `json:"max_millis"`     // Longest call duration in milliseconds
+}
+
+// GetServerMetricsResponse represents the response for server metrics
+type GetServerMetricsResponse struct {
+	Tools []ToolMetricsResponse `json:"tools"` // Metrics per tool
+}
+
+// NearbyStopResponse represents a stop near a point, with its walking distance
+type NearbyStopResponse struct {
+	Code           int     `json:"code"`            // Stop code
+	Name           string  `json:"name"`            // Stop name
+	Address        string  `json:"address"`         // Stop address
+	Latitude       float64 `json:"latitude"`        // Latitude
+	Longitude      float64 `json:"longitude"`       // Longitude
+	DistanceMeters int     `json:"distance_meters"` // Straight-line distance from the point
+	WalkingMeters  int     `json:"walking_meters"`  // Estimated walking distance from the point
+	WalkingMinutes int     `json:"walking_minutes"` // Estimated walking time from the point
+}
+
+// FindStopsNearResponse represents the response for finding stops near a point
+type FindStopsNearResponse struct {
+	Latitude     float64              `json:"latitude"`        // Latitude of the point
+	Longitude    float64              `json:"longitude"`       // Longitude of the point
+	Place        string               `json:"place,omitempty"` // Place the point was geocoded from
+	RadiusMeters int                  `json:"radius_meters"`   // Search radius used
+	TotalResults int                  `json:"total_results"`   // Number of stops returned
+	CatalogSize  int                  `json:"catalog_size"`    // Number of stops known to the server
+	Stops        []NearbyStopResponse `json:"stops"`           // Stops sorted by distance
+}
+
+// CatalogStatusResponse represents the state of the network catalog and its crawler
+type CatalogStatusResponse struct {
+	Version      int    `json:"version"`              // Catalog version, incremented on every completed crawl
+	UpdatedAt    string `json:"updated_at,omitempty"` // When the last crawl completed (RFC 3339)
+	Lines        int    `json:"lines"`                // Number of known lines
+	Stops        int    `json:"stops"`                // Number of known stops
+	Corridors    int    `json:"corridors"`            // Number of known corridors
+	Crawler      bool   `json:"crawler"`              // Whether the background crawler is enabled
+	Crawling     bool   `json:"crawling"`             // Whether a crawl is in progress
+	LinesPending int    `json:"lines_pending"`        // Lines left to fetch in the current crawl
+	LinesFetched int    `json:"lines_fetched"`        // Lines fetched in the current or last crawl
+	Failures     int    `json:"failures"`             // Failed requests in the current or last crawl
+	LastError    string `json:"last_error,omitempty"` // Last request failure
+	NextCrawl    string `json:"next_crawl,omitempty"` // When the next crawl is due (RFC 3339)
+}
+
+// NearbyVehicleResponse represents a live vehicle near a point
+type NearbyVehicleResponse struct {
+	ID             int     `json:"id"`              // Vehicle identifier
+	Accessible     bool    `json:"accessible"`      // Is accessible vehicle
+	Latitude       float64 `json:"latitude"`        // Latitude
+	Longitude      float64 `json:"longitude"`       // Longitude
+	LineIdentifier string  `json:"line_identifier"` // Line identifier, e.g. 875A-10
+	LineCode       int     `json:"line_code"`       // Line code
+	Direction      int     `json:"direction"`       // Direction (1 or 2)
+	Origin         string  `json:"origin"`          // Origin terminal
+	Destination    string  `json:"destination"`     // Destination terminal
+	DistanceMeters int     `json:"distance_meters"` // Straight-line distance from the point
+	Bearing        int     `json:"bearing"`         // Bearing from the point to the vehicle in degrees, clockwise from north
+	Compass        string  `json:"compass"`         // Bearing as a compass direction (N, NE, E, ...)
+	LastUpdate     string  `json:"last_update"`     // When the position was reported (RFC 3339)
+	AgeSeconds     int     `json:"age_seconds"`     // Seconds since the position was reported
+}
+
+// FindVehiclesNearResponse represents the response for finding vehicles near a point
+type FindVehiclesNearResponse struct {
+	Timestamp    string                  `json:"timestamp"`       // Hour of the positions snapshot
+	Latitude     float64                 `json:"latitude"`        // Latitude of the point
+	Longitude    float64                 `json:"longitude"`       // Longitude of the point
+	Place        string                  `json:"place,omitempty"` // Place the point was geocoded from
+	RadiusMeters int                     `json:"radius_meters"`   // Search radius
+	TotalResults int                     `json:"total_results"`   // Number of vehicles returned
+	Vehicles     []NearbyVehicleResponse `json:"vehicles"`        // Vehicles sorted by distance
+}
+
+// ClusterResponse represents points aggregated into one map cluster
+type ClusterResponse struct {
+	Latitude    float64     `json:"latitude"`     // Centroid latitude
+	Longitude   float64     `json:"longitude"`    // Centroid longitude
+	Count       int         `json:"count"`        // Number of points in the cluster
+	BoundingBox BoundingBox `json:"bounding_box"` // Extent of the points
+}
+
+// QueryViewportResponse represents the stops and vehicles inside a map viewport
+type QueryViewportResponse struct {
+	Timestamp       string                     `json:"timestamp,omitempty"`        // Hour of the positions snapshot
+	BoundingBox     BoundingBox                `json:"bounding_box"`               // Viewport queried
+	Clustered       bool                       `json:"clustered"`                  // Whether points are aggregated into clusters
+	Zoom            int                        `json:"zoom,omitempty"`             // Zoom level the clusters were built for
+	TotalStops      int                        `json:"total_stops"`                // Number of stops inside the viewport
+	TotalVehicles   int                        `json:"total_vehicles"`             // Number of vehicles inside the viewport
+	Truncated       bool                       `json:"truncated"`                  // Whether points were left out by the limit
+	Stops           []StopResponse             `json:"stops,omitempty"`            // Stops, nearest to the center first
+	Lines           []LineWithVehiclesResponse `json:"lines,omitempty"`            // Vehicles grouped by line
+	StopClusters    []ClusterResponse          `json:"stop_clusters,omitempty"`    // Stop clusters, largest first
+	VehicleClusters []ClusterResponse          `json:"vehicle_clusters,omitempty"` // Vehicle clusters, largest first
+}
+
+// RenderMapResponse describes a rendered line map
+type RenderMapResponse struct {
+	LineCode           int         `json:"line_code"`                     // Line code drawn
+	Title              string      `json:"title"`                         // Title shown in the legend
+	Width              int         `json:"width"`                         // Image width in pixels
+	Height             int         `json:"height"`                        // Image height in pixels
+	Stops              int         `json:"stops"`                         // Number of stops drawn
+	Vehicles           int         `json:"vehicles"`                      // Number of vehicles drawn
+	AccessibleVehicles int         `json:"accessible_vehicles"`           // Number of accessible vehicles drawn
+	HighlightStopCode  int         `json:"highlight_stop_code,omitempty"` // Highlighted stop, if any
+	ScaleMeters        int         `json:"scale_meters"`                  // Length represented by the scale bar
+	BoundingBox        BoundingBox `json:"bounding_box"`                  // Area shown
+	Timestamp          string      `json:"timestamp,omitempty"`           // Hour of the vehicle positions
+}
+
+// VehicleProgressResponse represents a vehicle located along its line's route
+type VehicleProgressResponse struct {
+	ID               int     `json:"id"`                           // Vehicle identifier
+	Accessible       bool    `json:"accessible"`                   // Is accessible vehicle
+	Latitude         float64 `json:"latitude"`                     // Latitude
+	Longitude        float64 `json:"longitude"`                    // Longitude
+	LastUpdate       string  `json:"last_update"`                  // When the position was reported (RFC 3339)
+	PreviousStopCode int     `json:"previous_stop_code,omitempty"` // Last stop passed, if any
+	PreviousStopName string  `json:"previous_stop_name,omitempty"` // Name of the last stop passed
+	NextStopCode     int     `json:"next_stop_code,omitempty"`     // Next stop, if any
+	NextStopName     string  `json:"next_stop_name,omitempty"`     // Name of the next stop
+	TravelledMeters  int     `json:"travelled_meters"`             // Distance along the route from the first stop
+	OffsetMeters     int     `json:"offset_meters"`                // Distance from the vehicle to the route
+	OffRoute         bool    `json:"off_route"`                    // Whether the vehicle is too far from the route to be located reliably
+	RemainingMeters  *int    `json:"remaining_meters,omitempty"`   // Distance along the route to the requested stop
+	StopsAway        *int    `json:"stops_away,omitempty"`         // Number of stops until the requested stop
+	Passed           bool    `json:"passed,omitempty"`             // Whether the vehicle has already passed the requested stop
+}
+
+// GetVehicleProgressResponse represents the vehicles of a line located along its route
+type GetVehicleProgressResponse struct {
+	Timestamp         string                    `json:"timestamp"`               // Hour of the positions snapshot
+	LineCode          int                       `json:"line_code"`               // Line code
+	ShapeSource       string                    `json:"shape_source"`            // Path the vehicles were snapped to: kmz or stops
+	RouteLengthMeters int                       `json:"route_length_meters"`     // Distance from the first to the last stop
+	TotalStops        int                       `json:"total_stops"`             // Number of stops of the line
+	StopCode          int                       `json:"stop_code,omitempty"`     // Stop the remaining distances are measured to
+	StopName          string                    `json:"stop_name,omitempty"`     // Name of that stop
+	StopSequence      int                       `json:"stop_sequence,omitempty"` // Position of that stop in the route, starting at 1
+	TotalVehicles     int                       `json:"total_vehicles"`          // Number of vehicles returned
+	Vehicles          []VehicleProgressResponse `json:"vehicles"`                // Vehicles, those still approaching the stop nearest first
+}
+
+// GeocodeResultResponse represents a location matched by geocode_address
+type GeocodeResultResponse struct {
+	Kind      string  `json:"kind"`                // What matched: street, stop or point
+	Name      string  `json:"name"`                // Street or stop name as written by SPTrans
+	Number    int     `json:"number,omitempty"`    // Building number located
+	Precision string  `json:"precision"`           // exact, interpolated, nearest, street, place or coordinate
+	Latitude  float64 `json:"latitude"`            // Latitude
+	Longitude float64 `json:"longitude"`           // Longitude
+	Score     float64 `json:"score"`               // Similarity of the name to the query, from 0 to 1
+	StopCode  int     `json:"stop_code,omitempty"` // Nearest stop on the street, or the matched stop
+	StopName  string  `json:"stop_name,omitempty"` // Name of that stop
+}
+
+// GeocodeAddressResponse represents the locations matching a free-text place
+type GeocodeAddressResponse struct {
+	Query        string                  `json:"query"`         // Place searched
+	TotalResults int                     `json:"total_results"` // Number of locations returned
+	CatalogSize  int                     `json:"catalog_size"`  // Number of stops the gazetteer was built from
+	Results      []GeocodeResultResponse `json:"results"`       // Locations, best match first
+}
+
+// ReverseGeocodeResponse represents the street and number nearest to a point
+type ReverseGeocodeResponse struct {
+	Latitude           float64  `json:"latitude"`                // Latitude of the point
+	Longitude          float64  `json:"longitude"`               // Longitude of the point
+	Found              bool     `json:"found"`                   // Whether a street was found within the radius
+	Street             string   `json:"street,omitempty"`        // Nearest street
+	Number             int      `json:"number,omitempty"`        // Estimated building number
+	Precision          string   `json:"precision,omitempty"`     // interpolated, nearest or street
+	StopCode           int      `json:"stop_code,omitempty"`     // Nearest stop with a known address
+	StopName           string   `json:"stop_name,omitempty"`     // Name of that stop
+	StopDistanceMeters int      `json:"stop_distance_meters"`    // Distance to that stop
+	CrossStreets       []string `json:"cross_streets,omitempty"` // Other streets nearby, nearest first
+	CatalogSize        int      `json:"catalog_size"`            // Number of stops the gazetteer was built from
+}
+
+// GeofenceResponse represents a watched area
+type GeofenceResponse struct {
+	ID               string       `json:"id"`                      // Fence identifier
+	Name             string       `json:"name"`                    // Fence name
+	Shape            string       `json:"shape"`                   // circle or polygon
+	Center           *Coordinate  `json:"center,omitempty"`        // Center of a circle
+	RadiusMeters     int          `json:"radius_meters,omitempty"` // Radius of a circle
+	Polygon          []Coordinate `json:"polygon,omitempty"`       // Vertices of a polygon
+	LineCode         int          `json:"line_code,omitempty"`     // Only vehicles of this line code
+	LinePrefix       string       `json:"line_prefix,omitempty"`   // Only vehicles of lines starting with this identifier
+	VehicleID        int          `json:"vehicle_id,omitempty"`    // Only this vehicle
+	HysteresisMeters int          `json:"hysteresis_meters"`       // Distance past the boundary an event needs
+	Webhook          string       `json:"webhook,omitempty"`       // Local URL events are posted to
+	CreatedAt        string       `json:"created_at"`              // When the fence was created (RFC 3339)
+	VehiclesInside   int          `json:"vehicles_inside"`         // Watched vehicles inside the fence at the last snapshot
+}
+
+// ListGeofencesResponse represents the watched areas and the state of their evaluator
+type ListGeofencesResponse struct {
+	IntervalSeconds int                `json:"interval_seconds"`        // Time between evaluated snapshots
+	LastSnapshot    string             `json:"last_snapshot,omitempty"` // Hour of the last evaluated snapshot
+	LastError       string             `json:"last_error,omitempty"`    // Error of the last evaluation, if it failed
+	TotalFences     int                `json:"total_fences"`            // Number of fences
+	Fences          []GeofenceResponse `json:"fences"`                  // Fences in creation order
+}
+
+// DeleteGeofenceResponse represents a removed watched area
+type DeleteGeofenceResponse struct {
+	ID      string `json:"id"`      // Fence identifier
+	Deleted bool   `json:"deleted"` // Whether the fence was removed
+}
+
+// GeofenceEventResponse represents a vehicle entering or leaving a watched area
+type GeofenceEventResponse struct {
+	FenceID        string  `json:"fence_id"`        // Fence identifier
+	FenceName      string  `json:"fence_name"`      // Fence name
+	Event          string  `json:"event"`           // enter or exit
+	VehicleID      int     `json:"vehicle_id"`      // Vehicle identifier
+	LineIdentifier string  `json:"line_identifier"` // Line identifier, e.g. 6450-10
+	LineCode       int     `json:"line_code"`       // Line code
+	Latitude       float64 `json:"latitude"`        // Position past the boundary
+	Longitude      float64 `json:"longitude"`       // Position past the boundary
+	Time           string  `json:"time"`            // When the position was reported (RFC 3339)
+}
+
+// GeofenceEventsResponse represents the recent enter and exit events
+type GeofenceEventsResponse struct {
+	FenceID     string                  `json:"fence_id,omitempty"` // Fence the events belong to, empty for all
+	TotalEvents int                     `json:"total_events"`       // Number of events returned
+	Events      []GeofenceEventResponse `json:"events"`             // Events, newest first
+}
+
+// WalkEndpointResponse represents an end of a walk
+type WalkEndpointResponse struct {
+	Name      string  `json:"name,omitempty"`      // Stop name or geocoded place
+	StopCode  int     `json:"stop_code,omitempty"` // Stop code, when the end is a stop
+	Latitude  float64 `json:"latitude"`            // Latitude
+	Longitude float64 `json:"longitude"`           // Longitude
+}
+
+// WalkingDistanceResponse represents the estimated walk between two points
+type WalkingDistanceResponse struct {
+	From           WalkEndpointResponse `json:"from"`            // Start of the walk
+	To             WalkEndpointResponse `json:"to"`              // End of the walk
+	DistanceMeters int                  `json:"distance_meters"` // Straight-line distance
+	WalkingMeters  int                  `json:"walking_meters"`  // Estimated street distance
+	WalkingSeconds int                  `json:"walking_seconds"` // Estimated walking time
+	WalkingMinutes int                  `json:"walking_minutes"` // Estimated walking time, rounded up
+	DetourFactor   float64              `json:"detour_factor"`   // Ratio of street to straight-line distance used
+	SpeedKmh       float64              `json:"speed_kmh"`       // Walking speed used
+}
+
+// TransferStopResponse represents a stop within walking distance and the lines serving it
+type TransferStopResponse struct {
+	NearbyStopResponse
+	Lines    []LineResponse `json:"lines"`     // Lines known to serve the stop
+	NewLines int            `json:"new_lines"` // Lines that do not serve the origin stop
+}
+
+// NearbyTransfersResponse represents the stops a rider can walk to from a stop
+type NearbyTransfersResponse struct {
+	Stop         StopResponse           `json:"stop"`          // Stop transferred from
+	Lines        []LineResponse         `json:"lines"`         // Lines known to serve that stop
+	RadiusMeters int                    `json:"radius_meters"` // Search radius used
+	TotalResults int                    `json:"total_results"` // Number of stops returned
+	Transfers    []TransferStopResponse `json:"transfers"`     // Stops sorted by distance
+}
+
+// TripLegResponse represents a walk or a bus ride of a trip option
+type TripLegResponse struct {
+	Mode        string               `json:"mode"`                   // walk or ride
+	From        WalkEndpointResponse `json:"from"`                   // Where the leg starts
+	To          WalkEndpointResponse `json:"to"`                     // Where the leg ends
+	Departure   string               `json:"departure"`              // Local time the walk starts or the bus leaves (HH:MM)
+	Arrival     string               `json:"arrival"`                // Local time the leg ends (HH:MM)
+	Minutes     int                  `json:"minutes"`                // Walking or riding minutes, excluding the wait
+	Meters      int                  `json:"meters"`                 // Estimated distance covered
+	Line        *LineResponse        `json:"line,omitempty"`         // Line ridden
+	Stops       int                  `json:"stops,omitempty"`        // Stops ridden
+	WaitMinutes int                  `json:"wait_minutes,omitempty"` // Wait for the bus at the first stop
+	LiveWait    bool                 `json:"live_wait,omitempty"`    // Whether the wait comes from a live prediction
+	VehicleID   string               `json:"vehicle_id,omitempty"`   // Predicted vehicle
+	Accessible  bool                 `json:"accessible,omitempty"`   // Whether the predicted vehicle is accessible
+}
+
+// TripOptionResponse represents one way of making a trip
+type TripOptionResponse struct {
+	Rank            int                    `json:"rank"`             // Position by arrival time
+	Arrival         string                 `json:"arrival"`          // Estimated local arrival time (HH:MM)
+	DurationMinutes int                    `json:"duration_minutes"` // Minutes from now to the arrival
+	Transfers       int                    `json:"transfers"`        // Changes between buses
+	WalkingMeters   int                    `json:"walking_meters"`   // Estimated distance walked
+	WaitMinutes     int                    `json:"wait_minutes"`     // Minutes spent waiting for buses
+	LiveWaits       bool                   `json:"live_waits"`       // Whether every wait comes from a live prediction
+	Fare            *FareResponse          `json:"fare,omitempty"`   // Bilhete Único fare of the rides
+	TransferStops   []WalkEndpointResponse `json:"transfer_stops"`   // Stops where a later bus is boarded
+	Legs            []TripLegResponse      `json:"legs"`             // Walks and rides in order
+}
+
+// PlanTripResponse represents the options for a trip between two points
+type PlanTripResponse struct {
+	From            WalkEndpointResponse `json:"from"`                        // Origin of the trip
+	To              WalkEndpointResponse `json:"to"`                          // Destination of the trip
+	Departure       string               `json:"departure"`                   // Local time the trip was planned from (HH:MM)
+	NetworkLines    int                  `json:"network_lines"`               // Lines with known stops available for planning
+	AccessibleOnly  bool                 `json:"accessible_only"`             // Whether only wheelchair-accessible buses are boarded
+	NoAccessibleBus int                  `json:"no_accessible_bus,omitempty"` // Options left out as no accessible bus is predicted for one of their rides
+	TotalResults    int                  `json:"total_results"`               // Number of options returned
+	Options         []TripOptionResponse `json:"options"`                     // Options sorted by arrival time
+}
+
+// TransferConnectionResponse represents a direction of the first line connecting to a direction of the second
+type TransferConnectionResponse struct {
+	FromLine    LineResponse `json:"from_line"`              // Direction of the first line, left at from_stop
+	ToLine      LineResponse `json:"to_line"`                // Direction of the second line, boarded at to_stop
+	WaitMinutes *int         `json:"wait_minutes,omitempty"` // Live predicted wait for the second line at to_stop
+	VehicleID   string       `json:"vehicle_id,omitempty"`   // Predicted vehicle of the second line
+}
+
+// TransferPointResponse represents a place to change from one line to another
+type TransferPointResponse struct {
+	FromStop       StopResponse                 `json:"from_stop"`       // Stop where the first line is left
+	ToStop         StopResponse                 `json:"to_stop"`         // Stop where the second line is boarded
+	Shared         bool                         `json:"shared"`          // Whether both lines serve the same stop
+	DistanceMeters int                          `json:"distance_meters"` // Straight-line distance between the stops
+	WalkingMeters  int                          `json:"walking_meters"`  // Estimated walking distance between the stops
+	WalkingMinutes int                          `json:"walking_minutes"` // Estimated walking time between the stops
+	Connections    []TransferConnectionResponse `json:"connections"`     // Directions connected at this point
+}
+
+// FindTransferPointsResponse represents where to change between two lines
+type FindTransferPointsResponse struct {
+	FromLines      []LineResponse          `json:"from_lines"`      // Directions of the first line
+	ToLines        []LineResponse          `json:"to_lines"`        // Directions of the second line
+	RadiusMeters   int                     `json:"radius_meters"`   // Maximum distance between paired stops
+	SharedStops    int                     `json:"shared_stops"`    // Stops served by both lines
+	TotalResults   int                     `json:"total_results"`   // Number of transfer points returned
+	TransferPoints []TransferPointResponse `json:"transfer_points"` // Shared stops first, then by distance
+}
+
+// DepartureResponse represents a predicted departure from a stop
+type DepartureResponse struct {
+	LineCode       int       `json:"line_code"`                 // Line code
+	Line           string    `json:"line"`                      // Line identifier, e.g. 8000-10
+	Direction      int       `json:"direction"`                 // Direction (1 or 2)
+	Destination    string    `json:"destination"`               // Terminal the bus is heading to
+	ArrivalTime    string    `json:"arrival_time"`              // Predicted arrival time (HH:MM)
+	MinutesUntil   int       `json:"minutes_until"`             // Minutes from the prediction time to the arrival
+	VehicleID      string    `json:"vehicle_id"`                // Vehicle identifier
+	Accessible     bool      `json:"accessible"`                // Is accessible vehicle
+	DistanceMeters *int      `json:"distance_meters,omitempty"` // Straight-line distance from the vehicle to the stop
+	LastUpdate     time.Time `json:"last_update"`               // Last position update
+}
+
+// AccessibleWaitResponse represents the wait for the next wheelchair-accessible bus of a line at a stop
+type AccessibleWaitResponse struct {
+	LineCode     int    `json:"line_code"`               // Line code
+	Line         string `json:"line"`                    // Line identifier, e.g. 8000-10
+	Destination  string `json:"destination"`             // Terminal the bus is heading to
+	MinutesUntil *int   `json:"minutes_until,omitempty"` // Minutes until the next accessible bus, absent when none is predicted
+	ArrivalTime  string `json:"arrival_time,omitempty"`  // Predicted arrival time of the accessible bus (HH:MM)
+	VehicleID    string `json:"vehicle_id,omitempty"`    // Accessible vehicle identifier
+}
+
+// DepartureBoardResponse represents the next departures from a stop in time order
+type DepartureBoardResponse struct {
+	Timestamp      string                   `json:"timestamp"`       // Prediction time (HH:MM)
+	StopCode       int                      `json:"stop_code"`       // Stop code used
+	StopName       string                   `json:"stop_name"`       // Stop name
+	AccessibleOnly bool                     `json:"accessible_only"` // Whether only wheelchair-accessible vehicles are listed
+	TotalResults   int                      `json:"total_results"`   // Number of departures returned
+	Departures     []DepartureResponse      `json:"departures"`      // Departures, soonest first
+	NextAccessible []AccessibleWaitResponse `json:"next_accessible"` // Wait for the next accessible bus of each line, soonest first
+}
+
+// FareLegResponse represents what a boarding costs
+type FareLegResponse struct {
+	Mode       string  `json:"mode"`               // bus or rail
+	BoardAt    string  `json:"board_at,omitempty"` // Local boarding time (HH:MM)
+	Amount     float64 `json:"amount"`             // Amount charged at this boarding
+	Integrated bool    `json:"integrated"`         // Whether the boarding joins an earlier integration
+	Break      string  `json:"break,omitempty"`    // Why the integration was broken: window, boardings or rail
+}
+
+// FareResponse represents the Bilhete Único fare of a sequence of boardings
+type FareResponse struct {
+	Category      string            `json:"category"`       // Fare category
+	Currency      string            `json:"currency"`       // Currency of the amounts
+	Total         float64           `json:"total"`          // Amount paid
+	FaresPaid     int               `json:"fares_paid"`     // Integrations started
+	Integrated    bool              `json:"integrated"`     // Whether every boarding after the first was integrated
+	WindowMinutes int               `json:"window_minutes"` // Integration window
+	MaxBoardings  int               `json:"max_boardings"`  // Boardings allowed per integration
+	Legs          []FareLegResponse `json:"legs"`           // Boardings in order
+}
+
+// AccessibleFleetLineResponse represents the wheelchair-accessible share of the vehicles running a line
+type AccessibleFleetLineResponse struct {
+	Line       string  `json:"line"`       // Line identifier, e.g. 8000-10
+	Vehicles   int     `json:"vehicles"`   // Vehicles running the line in both directions
+	Accessible int     `json:"accessible"` // Accessible vehicles among them
+	Percent    float64 `json:"percent"`    // Accessible share of the vehicles
+}
+
+// GetAccessibleFleetResponse represents the accessible share of the running fleet, by line
+type GetAccessibleFleetResponse struct {
+	Timestamp    string                        `json:"timestamp"`             // Data timestamp (HH:MM)
+	LinePrefix   string                        `json:"line_prefix,omitempty"` // Line prefix filter used
+	Vehicles     int                           `json:"vehicles"`              // Running vehicles of the matching lines
+	Accessible   int                           `json:"accessible"`            // Accessible vehicles among them
+	Percent      float64                       `json:"percent"`               // Accessible share of the vehicles
+	TotalLines   int                           `json:"total_lines"`           // Lines with running vehicles
+	TotalResults int                           `json:"total_results"`         // Number of lines returned
+	Lines        []AccessibleFleetLineResponse `json:"lines"`                 // Lines, smallest accessible share first
+}
+
+// ReachableStopResponse represents a stop reached from the origin of an isochrone
+type ReachableStopResponse struct {
+	Code          int     `json:"code"`           // Stop code
+	Name          string  `json:"name"`           // Stop name
+	Latitude      float64 `json:"latitude"`       // Latitude
+	Longitude     float64 `json:"longitude"`      // Longitude
+	Minutes       int     `json:"minutes"`        // Estimated minutes from the origin, walks and waits included
+	WithinMinutes int     `json:"within_minutes"` // Smallest time budget the stop is reached within
+	Rides         int     `json:"rides"`          // Buses ridden to reach the stop
+}
+
+// IsochroneBudgetResponse represents the area reached within a time budget
+type IsochroneBudgetResponse struct {
+	Minutes int          `json:"minutes"`           // Time budget
+	Stops   int          `json:"stops"`             // Stops reached within the budget
+	Polygon []Coordinate `json:"polygon,omitempty"` // Closed outline of the stops and the walk beyond them
+}
+
+// IsochroneResponse represents what can be reached from a point within time budgets
+type IsochroneResponse struct {
+	Origin        WalkEndpointResponse      `json:"origin"`         // Point the search starts at
+	Departure     string                    `json:"departure"`      // Local time the search starts at (HH:MM)
+	Timestamp     string                    `json:"timestamp"`      // Time of the vehicle positions used (HH:MM)
+	NetworkLines  int                       `json:"network_lines"`  // Lines with known stops available for the search
+	RunningLines  int                       `json:"running_lines"`  // Lines with running vehicles
+	MeasuredLines int                       `json:"measured_lines"` // Running lines whose speed was measured from live positions
+	Budgets       []IsochroneBudgetResponse `json:"budgets"`        // Areas reached, smallest budget first
+	TotalStops    int                       `json:"total_stops"`    // Stops reached within the largest budget
+	TotalResults  int                       `json:"total_results"`  // Number of stops returned
+	Stops         []ReachableStopResponse   `json:"stops"`          // Stops reached, soonest first
+}
+
+// DirectionMatchResponse represents how well a direction of a line matches where the rider is heading
+type DirectionMatchResponse struct {
+	Line      LineResponse `json:"line"`                 // Direction of the line
+	Terminals []string     `json:"terminals"`            // Terminals the direction heads to, both ends for a circular line
+	Score     float64      `json:"score"`                // Match score from 0 to 1
+	MatchedBy string       `json:"matched_by,omitempty"` // terminal or stop, absent when nothing matched
+	Matched   string       `json:"matched,omitempty"`    // Terminal or stop name matched
+}
+
+// ResolveDirectionResponse represents the direction of a line heading towards a place
+type ResolveDirectionResponse struct {
+	Line       string                   `json:"line"`               // Line searched for
+	Towards    string                   `json:"towards"`            // Where the rider is heading
+	Resolved   *LineResponse            `json:"resolved,omitempty"` // Direction chosen, absent when none matches or the match is ambiguous
+	Directions []DirectionMatchResponse `json:"directions"`         // Every direction, best match first
+}
+
+// PredictionsBatchItemResponse represents the departures for one query of a batch
+type PredictionsBatchItemResponse struct {
+	Index    int                     `json:"index"`               // Position of the query in the batch
+	Stop     string                  `json:"stop"`                // Stop as given
+	Line     string                  `json:"line,omitempty"`      // Line as given, if any
+	LineCode int                     `json:"line_code,omitempty"` // Line code the line resolved to
+	Board    *DepartureBoardResponse `json:"board,omitempty"`     // Departures, when the query succeeded
+	Error    *ErrorResponse          `json:"error,omitempty"`     // Why the query failed
+}
+
+// GetPredictionsBatchResponse represents the predictions for several stops and lines
+type GetPredictionsBatchResponse struct {
+	TotalQueries int                            `json:"total_queries"` // Number of queries in the batch
+	Succeeded    int                            `json:"succeeded"`     // Queries answered
+	Failed       int                            `json:"failed"`        // Queries that failed
+	StopsFetched int                            `json:"stops_fetched"` // Distinct stops whose predictions were fetched
+	Items        []PredictionsBatchItemResponse `json:"items"`         // Result of each query, in order
+}
+
+// LeaveOptionResponse represents a predicted bus the rider can still catch
+type LeaveOptionResponse struct {
+	DepartureResponse
+	LeaveBy            string `json:"leave_by"`             // Latest local time to leave and reach the stop in time (HH:MM)
+	LeaveInMinutes     int    `json:"leave_in_minutes"`     // Minutes from now until LeaveBy
+	PositionAgeSeconds int    `json:"position_age_seconds"` // Age of the vehicle position the prediction is based on
+	Confidence         string `json:"confidence"`           // high, medium or low, from the age of the position
+}
+
+// LeaveAdviceResponse represents when to leave to catch a bus at a stop
+type LeaveAdviceResponse struct {
+	Timestamp     string                `json:"timestamp"`       // Prediction time (HH:MM)
+	Now           string                `json:"now"`             // Time the advice counts from, the prediction time (HH:MM)
+	Origin        WalkEndpointResponse  `json:"origin"`          // Where the rider is
+	StopCode      int                   `json:"stop_code"`       // Stop to catch the bus at
+	StopName      string                `json:"stop_name"`       // Stop name
+	WalkMeters    int                   `json:"walk_meters"`     // Estimated walking distance to the stop
+	WalkMinutes   int                   `json:"walk_minutes"`    // Estimated walking time to the stop
+	MarginMinutes int                   `json:"margin_minutes"`  // Minutes to be at the stop before the bus
+	Catch         *LeaveOptionResponse  `json:"catch,omitempty"` // The next bus the rider can catch, with the latest time to leave
+	Missed        int                   `json:"missed"`          // Buses predicted too soon to be caught
+	TotalResults  int                   `json:"total_results"`   // Number of options returned
+	Options       []LeaveOptionResponse `json:"options"`         // Buses that can be caught, soonest first
+}
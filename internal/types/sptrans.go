@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Line represents a bus line in the SPTrans system
 type Line struct {
@@ -13,6 +16,19 @@ type Line struct {
 	Destination string `json:"ts"`        // Destination terminal
 }
 
+// Sign returns the sign shown on buses, e.g. 875A-10
+func (l Line) Sign() string {
+	return fmt.Sprintf("%s-%d", l.Number, l.Type)
+}
+
+// Headsign returns the terminal the line is heading to in its direction
+func (l Line) Headsign() string {
+	if l.Direction == 2 {
+		return l.Origin
+	}
+	return l.Destination
+}
+
 // Stop represents a bus stop in the SPTrans system
 type Stop struct {
 	Code      int     `json:"cp"`  // Stop code (unique identifier)
@@ -50,44 +66,53 @@ type Vehicle struct {
 	Longitude   float64   `json:"px"`  // Longitude
 }
 
+// VehicleLine represents a line and its vehicles within a vehicle positions snapshot
+type VehicleLine struct {
+	Identifier  string    `json:"c"`   // Line identifier
+	Code        int       `json:"cl"`  // Line code
+	Direction   int       `json:"sl"`  // Direction
+	Origin      string    `json:"lt0"` // Origin terminal
+	Destination string    `json:"lt1"` // Destination terminal
+	VehicleQty  int       `json:"qv"`  // Number of vehicles
+	Vehicles    []Vehicle `json:"vs"`  // Vehicles data
+}
+
 // VehiclePositions represents the response from vehicle position endpoints
 type VehiclePositions struct {
-	Hour  string `json:"hr"` // Data timestamp
-	Lines []struct {
-		Identifier  string    `json:"c"`   // Line identifier
-		Code        int       `json:"cl"`  // Line code
-		Direction   int       `json:"sl"`  // Direction
-		Origin      string    `json:"lt0"` // Origin terminal
-		Destination string    `json:"lt1"` // Destination terminal
-		VehicleQty  int       `json:"qv"`  // Number of vehicles
-		Vehicles    []Vehicle `json:"vs"`  // Vehicles data
-	} `json:"l"`
+	Hour  string        `json:"hr"` // Data timestamp
+	Lines []VehicleLine `json:"l"`  // Lines with vehicles
+}
+
+// Prediction represents the predicted arrival of a vehicle at a stop
+type Prediction struct {
+	VehicleID   string    `json:"p"`  // Vehicle identifier
+	ArrivalTime string    `json:"t"`  // Predicted arrival time
+	Accessible  bool      `json:"a"`  // Is accessible vehicle
+	LastUpdate  time.Time `json:"ta"` // Last position update
+	Latitude    float64   `json:"py"` // Current vehicle latitude
+	Longitude   float64   `json:"px"` // Current vehicle longitude
+}
+
+// LineWithPredictions represents a line and its predicted arrivals at a stop
+type LineWithPredictions struct {
+	Identifier  string       `json:"c"`   // Line identifier
+	Code        int          `json:"cl"`  // Line code
+	Direction   int          `json:"sl"`  // Direction
+	Origin      string       `json:"lt0"` // Origin terminal
+	Destination string       `json:"lt1"` // Destination terminal
+	VehicleQty  int          `json:"qv"`  // Number of vehicles
+	Predictions []Prediction `json:"vs"`  // Predicted arrivals
 }
 
 // ArrivalPrediction represents arrival prediction data
 type ArrivalPrediction struct {
 	Hour string `json:"hr"` // Current time
 	Stop struct {
-		Code      int     `json:"cp"` // Stop code
-		Name      string  `json:"np"` // Stop name
-		Latitude  float64 `json:"py"` // Stop latitude
-		Longitude float64 `json:"px"` // Stop longitude
-		Lines     []struct {
-			Identifier    string `json:"c"`   // Line identifier
-			Code          int    `json:"cl"`  // Line code
-			Direction     int    `json:"sl"`  // Direction
-			Origin        string `json:"lt0"` // Origin terminal
-			Destination   string `json:"lt1"` // Destination terminal
-			VehicleQty    int    `json:"qv"`  // Number of vehicles
-			Predictions   []struct {
-				VehicleID   string    `json:"p"`  // Vehicle identifier
-				ArrivalTime string    `json:"t"`  // Predicted arrival time
-				Accessible  bool      `json:"a"`  // Is accessible vehicle
-				LastUpdate  time.Time `json:"ta"` // Last position update
-				Latitude    float64   `json:"py"` // Current vehicle latitude
-				Longitude   float64   `json:"px"` // Current vehicle longitude
-			} `json:"vs"`
-		} `json:"l"`
+		Code      int                   `json:"cp"` // Stop code
+		Name      string                `json:"np"` // Stop name
+		Latitude  float64               `json:"py"` // Stop latitude
+		Longitude float64               `json:"px"` // Stop longitude
+		Lines     []LineWithPredictions `json:"l"`  // Lines with predictions
 	} `json:"p"`
 }
 
@@ -95,26 +120,11 @@ type ArrivalPrediction struct {
 type ArrivalPredictionsByLine struct {
 	Hour  string `json:"hr"` // Current time
 	Stops []struct {
-		Code      int     `json:"cp"` // Stop code
-		Name      string  `json:"np"` // Stop name
-		Latitude  float64 `json:"py"` // Stop latitude
-		Longitude float64 `json:"px"` // Stop longitude
-		Lines     []struct {
-			Identifier    string `json:"c"`   // Line identifier
-			Code          int    `json:"cl"`  // Line code
-			Direction     int    `json:"sl"`  // Direction
-			Origin        string `json:"lt0"` // Origin terminal
-			Destination   string `json:"lt1"` // Destination terminal
-			VehicleQty    int    `json:"qv"`  // Number of vehicles
-			Predictions   []struct {
-				VehicleID   string    `json:"p"`  // Vehicle identifier
-				ArrivalTime string    `json:"t"`  // Predicted arrival time
-				Accessible  bool      `json:"a"`  // Is accessible vehicle
-				LastUpdate  time.Time `json:"ta"` // Last position update
-				Latitude    float64   `json:"py"` // Current vehicle latitude
-				Longitude   float64   `json:"px"` // Current vehicle longitude
-			} `json:"vs"`
-		} `json:"l"`
+		Code      int                   `json:"cp"` // Stop code
+		Name      string                `json:"np"` // Stop name
+		Latitude  float64               `json:"py"` // Stop latitude
+		Longitude float64               `json:"px"` // Stop longitude
+		Lines     []LineWithPredictions `json:"l"`  // Lines with predictions
 	} `json:"ps"`
 }
 
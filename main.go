@@ -2,26 +2,52 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/thunderjr/sptrans-mcp/internal/auth"
+	"github.com/thunderjr/sptrans-mcp/internal/catalog"
 	"github.com/thunderjr/sptrans-mcp/internal/client"
+	"github.com/thunderjr/sptrans-mcp/internal/config"
+	"github.com/thunderjr/sptrans-mcp/internal/fare"
+	"github.com/thunderjr/sptrans-mcp/internal/geo"
+	"github.com/thunderjr/sptrans-mcp/internal/geofence"
 	"github.com/thunderjr/sptrans-mcp/internal/handlers"
+	"github.com/thunderjr/sptrans-mcp/internal/i18n"
+	"github.com/thunderjr/sptrans-mcp/internal/pipeline"
+	"github.com/thunderjr/sptrans-mcp/internal/registry"
+	"github.com/thunderjr/sptrans-mcp/internal/render"
+	"github.com/thunderjr/sptrans-mcp/internal/shape"
 )
 
 func main() {
 	ctx := context.Background()
 
-	// Get the SPTrans API token from environment
-	token := os.Getenv("SPTRANS_PAT")
-	if token == "" {
-		log.Fatal("SPTRANS_PAT environment variable is required")
+	// Load configuration from flags and environment
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	format, err := render.ParseFormat(cfg.Format, render.JSON)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+
+	locale, err := i18n.ParseLocale(cfg.Locale, i18n.English)
+	if err != nil {
+		log.Fatalf("Invalid locale: %v", err)
+	}
+
+	groups, err := registry.ResolveGroups(cfg.ToolGroups, cfg.DisabledToolGroups)
+	if err != nil {
+		log.Fatalf("Invalid tool groups: %v", err)
 	}
 
 	// Create authentication manager
-	authManager := auth.NewManager(token)
+	authManager := auth.NewManager(cfg.Token)
 
 	// Authenticate on startup
 	if err := authManager.Authenticate(ctx); err != nil {
@@ -31,73 +57,79 @@ func main() {
 
 	// Create SPTrans client
 	sptransClient := client.NewClient(authManager)
+	sptransClient.SetRateLimit(cfg.RateLimit)
 
 	// Set the global client for handlers to use
 	handlers.SetGlobalClient(sptransClient)
+	handlers.SetDefaultLocale(locale)
+	handlers.SetGlobalWalker(geo.Walker{DetourFactor: cfg.WalkDetour, Speed: cfg.WalkSpeed / 3.6})
+	if cfg.FaresPath != "" {
+		fares, err := fare.Load(cfg.FaresPath)
+		if err != nil {
+			log.Fatalf("Invalid fare table: %v", err)
+		}
+		handlers.SetGlobalFares(fares)
+	}
+
+	// Load the persisted network catalog and keep it fresh in the background
+	if cfg.CatalogPath != "" {
+		if err := handlers.GlobalCatalog.Load(cfg.CatalogPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Ignoring network catalog: %v", err)
+		}
+	}
+	version, _ := handlers.GlobalCatalog.Version()
+	log.Printf("Network catalog version %d: %d lines, %d stops",
+		version, handlers.GlobalCatalog.LineCount(), handlers.GlobalCatalog.StopCount())
+	if cfg.CatalogRefresh > 0 {
+		crawler := catalog.NewCrawler(handlers.GlobalCatalog, sptransClient, catalog.CrawlerOptions{
+			Path:     cfg.CatalogPath,
+			Interval: cfg.CatalogRefresh,
+			MaxAge:   cfg.CatalogMaxAge,
+			Rate:     cfg.CrawlRate,
+		})
+		handlers.SetGlobalCrawler(crawler)
+		go crawler.Run(ctx)
+	}
+
+	// Download route shapes on first use to snap vehicles to the itinerary
+	if cfg.ShapeRefresh > 0 {
+		handlers.SetGlobalShapes(shape.NewStore(sptransClient.GetRoutesKMZ, cfg.ShapeRefresh))
+	}
 
 	// Create MCP server
-	server := mcp.NewServer(&mcp.Implementation{Name: "sptrans-mcp", Version: "1.0.0"}, nil)
-
-	// Register line operation tools
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "search_lines",
-		Description: "Search for bus lines by name or number (partial or complete)",
-	}, handlers.SearchLines)
-
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "search_line_by_direction",
-		Description: "Search for a specific line in a specific direction",
-	}, handlers.SearchLineByDirection)
-
-	// Register stop operation tools
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "search_stops",
-		Description: "Search for bus stops by name or address (partial or complete)",
-	}, handlers.SearchStops)
-
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "get_stops_by_line",
-		Description: "Get all stops served by a specific line",
-	}, handlers.GetStopsByLine)
-
-	// Register vehicle position tools
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "get_vehicle_positions",
-		Description: "Get real-time positions of all vehicles",
-	}, handlers.GetVehiclePositions)
-
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "get_vehicle_positions_by_line",
-		Description: "Get real-time positions of vehicles on a specific line",
-	}, handlers.GetVehiclePositionsByLine)
-
-	// Register arrival prediction tools (core for forecasting)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "get_arrival_predictions",
-		Description: "Get arrival predictions for vehicles at a specific stop and line",
-	}, handlers.GetArrivalPredictions)
-
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "get_arrival_predictions_by_line",
-		Description: "Get all arrival predictions for a specific line",
-	}, handlers.GetArrivalPredictionsByLine)
-
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "get_arrival_predictions_by_stop",
-		Description: "Get all arrival predictions for a specific stop",
-	}, handlers.GetArrivalPredictionsByStop)
+	server := mcp.NewServer(&mcp.Implementation{Name: "sptrans-mcp", Version: "1.0.0"}, &mcp.ServerOptions{
+		InitializedHandler: handlers.ForgetOnClose,
+	})
+
+	// Evaluate geofences over periodic position snapshots, notifying every
+	// session, and measure line speeds from the same snapshots
+	if cfg.GeofenceInterval > 0 {
+		watcher := geofence.NewWatcher(sptransClient, cfg.GeofenceInterval, handlers.GeofenceNotifier(server))
+		watcher.SetObserver(handlers.GlobalSpeeds.Observe)
+		handlers.SetGlobalGeofences(watcher)
+		go watcher.Run(ctx)
+	}
+
+	// Run every tool through the middleware pipeline
+	toolPipeline := pipeline.New(
+		pipeline.Log(log.Default(), handlers.Metrics),
+		pipeline.Errors(handlers.SessionLocale),
+		pipeline.Recover(),
+		pipeline.Render(format, handlers.SessionLocale),
+		pipeline.Timeout(cfg.ToolTimeout, cfg.ToolTimeouts),
+		pipeline.Validate(),
+	)
+
+	// Register the tools of the enabled groups
+	tools := registry.New(toolPipeline)
+	handlers.RegisterTools(tools)
+	installed := tools.Install(server, groups, locale)
 
 	log.Println("SPTrans MCP Server starting...")
 	log.Println("Available tools:")
-	log.Println("  - search_lines: Search for bus lines")
-	log.Println("  - search_line_by_direction: Search line by direction")
-	log.Println("  - search_stops: Search for bus stops")
-	log.Println("  - get_stops_by_line: Get stops for a line")
-	log.Println("  - get_vehicle_positions: Get all vehicle positions")
-	log.Println("  - get_vehicle_positions_by_line: Get vehicle positions by line")
-	log.Println("  - get_arrival_predictions: Get arrival predictions")
-	log.Println("  - get_arrival_predictions_by_line: Get predictions by line")
-	log.Println("  - get_arrival_predictions_by_stop: Get predictions by stop")
+	for _, tool := range installed {
+		log.Printf("  - %s (%s): %s", tool.Name, tool.Group, registry.Description(tool.Name, locale))
+	}
 
 	// Run the server over stdin/stdout
 	if err := server.Run(ctx, mcp.NewStdioTransport()); err != nil {